@@ -0,0 +1,45 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestRun_ConfigLint(t *testing.T) {
+	t.Parallel()
+
+	tests := []cliTestCase{
+		{
+			name: "valid config",
+			args: []string{"", "config", "lint", "./fixtures/config-lint/valid"},
+			exit: 0,
+		},
+		{
+			name: "invalid config",
+			args: []string{"", "config", "lint", "./fixtures/config-lint/invalid/osv-scanner.toml"},
+			exit: 127,
+		},
+		{
+			name: "recursive",
+			args: []string{"", "config", "lint", "--recursive", "./fixtures/config-lint"},
+			exit: 127,
+		},
+		{
+			name: "json output",
+			args: []string{"", "config", "lint", "--format", "json", "-r", "./fixtures/config-lint/invalid"},
+			exit: 127,
+		},
+		{
+			name: "no configs",
+			args: []string{"", "config", "lint", "./fixtures/locks-empty"},
+			exit: 127,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			testCli(t, tt)
+		})
+	}
+}
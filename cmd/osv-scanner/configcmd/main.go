@@ -0,0 +1,222 @@
+package configcmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/osv-scanner/internal/output"
+	"github.com/google/osv-scanner/pkg/config"
+	"github.com/google/osv-scanner/pkg/osvscanner"
+	"github.com/google/osv-scanner/pkg/reporter"
+	"github.com/urfave/cli/v2"
+	"golang.org/x/term"
+)
+
+const configFileName = "osv-scanner.toml"
+
+// formats are the formats that lint issues can be rendered in
+var formats = []string{"table", "json"}
+
+func Command(stdout, stderr io.Writer, r *reporter.Reporter) *cli.Command {
+	return &cli.Command{
+		Name:  "config",
+		Usage: "works with osv-scanner.toml config files",
+		Subcommands: []*cli.Command{
+			{
+				Name:        "lint",
+				Usage:       "checks config files for problems",
+				Description: "checks config files for problems such as unknown keys, invalid ecosystems, malformed dates and ignores that have expired or expire soon, optionally scanning the directory of each config for ignores that do not match any vulnerability",
+				ArgsUsage:   "[config files or directories...]",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:    "format",
+						Aliases: []string{"f"},
+						Usage:   "sets the output format; value can be: " + strings.Join(formats, ", "),
+						Value:   "table",
+						Action: func(_ *cli.Context, s string) error {
+							if slices.Contains(formats, s) {
+								return nil
+							}
+
+							return fmt.Errorf("unsupported output format \"%s\" - must be one of: %s", s, strings.Join(formats, ", "))
+						},
+					},
+					&cli.BoolFlag{
+						Name:    "recursive",
+						Aliases: []string{"r"},
+						Usage:   "check the config files in subdirectories of the given directories too",
+					},
+					&cli.IntFlag{
+						Name:  "expiring-within",
+						Usage: "report ignores and overrides that expire within this many days",
+						Value: int(config.DefaultExpiringWithin / (24 * time.Hour)),
+					},
+					&cli.BoolFlag{
+						Name:  "unused",
+						Usage: "scan the directory of each config file (and its subdirectories) to report ignores that do not match any vulnerability",
+					},
+				},
+				Action: func(context *cli.Context) error {
+					if context.String("format") == "json" {
+						*r = reporter.NewJSONReporter(stdout, stderr, reporter.InfoLevel)
+					} else {
+						*r = reporter.NewTableReporter(stdout, stderr, reporter.InfoLevel, false, 0)
+					}
+
+					return lintAction(context, stdout, *r)
+				},
+			},
+		},
+	}
+}
+
+// findConfigs returns the config files at the given paths, which are either
+// config files or directories containing them
+func findConfigs(paths []string, recursive bool) ([]string, error) {
+	var configs []string
+
+	for _, target := range paths {
+		info, err := os.Stat(target)
+		if err != nil {
+			return nil, err
+		}
+
+		if !info.IsDir() {
+			configs = append(configs, target)
+
+			continue
+		}
+
+		if !recursive {
+			if _, err := os.Stat(filepath.Join(target, configFileName)); err == nil {
+				configs = append(configs, filepath.Join(target, configFileName))
+			}
+
+			continue
+		}
+
+		err = filepath.WalkDir(target, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if d.IsDir() && path != target && (d.Name() == ".git" || d.Name() == "node_modules") {
+				return filepath.SkipDir
+			}
+
+			if !d.IsDir() && d.Name() == configFileName {
+				configs = append(configs, path)
+			}
+
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return configs, nil
+}
+
+// unusedIgnores scans the directory of the config file and its subdirectories,
+// returning an issue for each of its ignores that did not match any
+// vulnerability; configs are looked up like they are when scanning so that
+// the files in subdirectories with their own config are not checked against it
+func unusedIgnores(context *cli.Context, r reporter.Reporter, configPath string) ([]config.LintIssue, error) {
+	configManager := &config.ConfigManager{ConfigMap: make(map[string]config.Config)}
+	dir := filepath.Dir(configPath)
+
+	r.Infof("Scanning %s for vulnerabilities that are ignored by %s\n", dir, configPath)
+
+	_, err := osvscanner.NewScanner(osvscanner.WithConfigManager(configManager)).Scan(context.Context, osvscanner.ScannerActions{
+		DirectoryPaths: []string{dir},
+		Recursive:      true,
+	})
+	if err != nil && !errors.Is(err, osvscanner.NoPackagesFoundErr) && !errors.Is(err, osvscanner.VulnerabilitiesFoundErr) {
+		return nil, err
+	}
+
+	// the ignores of the config include those of the configs it extends or inherits from
+	cfg := configManager.Get(r, configPath)
+	sources := cfg.Sources()
+
+	var unused []config.LintIssue
+	for _, issue := range configManager.UnusedIgnores() {
+		if slices.Contains(sources, issue.Path) {
+			unused = append(unused, issue)
+		}
+	}
+
+	return unused, nil
+}
+
+func lintAction(context *cli.Context, stdout io.Writer, r reporter.Reporter) error {
+	paths := context.Args().Slice()
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+
+	configs, err := findConfigs(paths, context.Bool("recursive"))
+	if err != nil {
+		return err
+	}
+
+	if len(configs) == 0 {
+		return errors.New("no config files found")
+	}
+
+	expiringWithin := time.Duration(context.Int("expiring-within")) * 24 * time.Hour
+	issues := make([]config.LintIssue, 0)
+
+	for _, configPath := range configs {
+		found, err := config.Lint(configPath, expiringWithin)
+		if err != nil {
+			return err
+		}
+
+		issues = append(issues, found...)
+
+		// configs that cannot be loaded would not be used to ignore anything
+		if !context.Bool("unused") || slices.ContainsFunc(found, func(issue config.LintIssue) bool {
+			return issue.Kind == config.LintInvalidConfig || issue.Kind == config.LintInvalidDate
+		}) {
+			continue
+		}
+
+		unused, err := unusedIgnores(context, r, configPath)
+		if err != nil {
+			return err
+		}
+
+		issues = append(issues, unused...)
+	}
+
+	if context.String("format") == "json" {
+		if err := printJSON(issues, stdout); err != nil {
+			return fmt.Errorf("failed to write output: %w", err)
+		}
+	} else {
+		termWidth := 0
+		if stdoutAsFile, ok := stdout.(*os.File); ok {
+			termWidth, _, err = term.GetSize(int(stdoutAsFile.Fd()))
+			if err != nil { // If output is not a terminal,
+				termWidth = 0
+			}
+		}
+
+		printTable(issues, len(configs), stdout, termWidth)
+	}
+
+	if len(issues) > 0 {
+		return fmt.Errorf("found %d %s with the config", len(issues), output.Form(len(issues), "issue", "issues"))
+	}
+
+	return nil
+}
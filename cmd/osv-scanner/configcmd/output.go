@@ -0,0 +1,55 @@
+package configcmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/google/osv-scanner/internal/output"
+	"github.com/google/osv-scanner/pkg/config"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+)
+
+func printJSON(issues []config.LintIssue, w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(struct {
+		Issues []config.LintIssue `json:"issues"`
+	}{issues})
+}
+
+func printTable(issues []config.LintIssue, configs int, w io.Writer, terminalWidth int) {
+	if terminalWidth <= 0 {
+		text.DisableColors()
+	}
+
+	fmt.Fprintf(
+		w,
+		"Checked %d config %s and found %d %s\n",
+		configs,
+		output.Form(configs, "file", "files"),
+		len(issues),
+		output.Form(len(issues), "issue", "issues"),
+	)
+
+	if len(issues) == 0 {
+		return
+	}
+
+	outputTable := table.NewWriter()
+	outputTable.SetOutputMirror(w)
+
+	// use fancy characters if we're outputting to a terminal
+	if terminalWidth > 0 {
+		outputTable.SetStyle(table.StyleRounded)
+		outputTable.SetAllowedRowLength(terminalWidth)
+	}
+
+	outputTable.AppendHeader(table.Row{"Config", "Key", "Issue", "Message"})
+	for _, issue := range issues {
+		outputTable.AppendRow(table.Row{issue.Path, issue.Key, issue.Kind, issue.Message})
+	}
+	outputTable.Render()
+}
@@ -0,0 +1,96 @@
+package diff
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/google/osv-scanner/internal/ci"
+	"github.com/google/osv-scanner/pkg/reporter"
+	"github.com/urfave/cli/v2"
+	"golang.org/x/term"
+)
+
+func Command(stdout, stderr io.Writer, r *reporter.Reporter) *cli.Command {
+	return &cli.Command{
+		Name:        "diff",
+		Usage:       "compares the JSON results of two scans",
+		Description: "compares the JSON results of two scans, such as those of two releases, reporting new, resolved and unchanged vulnerabilities along with package and license changes",
+		ArgsUsage:   "<old results> <new results>",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "format",
+				Aliases: []string{"f"},
+				Usage:   "sets the output format; value can be: " + strings.Join(formats, ", "),
+				Value:   "table",
+				Action: func(_ *cli.Context, s string) error {
+					if slices.Contains(formats, s) {
+						return nil
+					}
+
+					return fmt.Errorf("unsupported output format \"%s\" - must be one of: %s", s, strings.Join(formats, ", "))
+				},
+			},
+			&cli.StringFlag{
+				Name:      "output",
+				Usage:     "saves the diff to the given file path",
+				TakesFile: true,
+			},
+		},
+		Action: func(context *cli.Context) error {
+			*r = reporter.NewTableReporter(stdout, stderr, reporter.InfoLevel, false, 0)
+
+			return action(context, stdout)
+		},
+	}
+}
+
+func action(context *cli.Context, stdout io.Writer) error {
+	if context.NArg() != 2 {
+		return errors.New("diff requires the paths of the old and new results, i.e. osv-scanner diff old.json new.json")
+	}
+
+	oldRes, err := ci.LoadVulnResults(context.Args().Get(0))
+	if err != nil {
+		return err
+	}
+
+	newRes, err := ci.LoadVulnResults(context.Args().Get(1))
+	if err != nil {
+		return err
+	}
+
+	termWidth := 0
+	if outputPath := context.String("output"); outputPath != "" {
+		f, err := os.Create(outputPath)
+		if err != nil {
+			return fmt.Errorf("failed to create output file: %w", err)
+		}
+		defer f.Close()
+
+		stdout = f
+	} else if stdoutAsFile, ok := stdout.(*os.File); ok {
+		termWidth, _, err = term.GetSize(int(stdoutAsFile.Fd()))
+		if err != nil { // If output is not a terminal,
+			termWidth = 0
+		}
+	}
+
+	diff := ci.CompareVulnerabilityResults(oldRes, newRes)
+
+	switch context.String("format") {
+	case "json":
+		if err := printJSON(diff, stdout); err != nil {
+			return fmt.Errorf("failed to write output: %w", err)
+		}
+	case "markdown":
+		printMarkdown(diff, stdout)
+	default:
+		printTable(diff, stdout, termWidth)
+	}
+
+	return nil
+}
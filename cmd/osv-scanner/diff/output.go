@@ -0,0 +1,156 @@
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/google/osv-scanner/internal/ci"
+	"github.com/google/osv-scanner/internal/output"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+)
+
+// formats are the formats that a diff can be rendered in
+var formats = []string{"table", "markdown", "json"}
+
+func printJSON(diff ci.ResultsDiff, w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(diff)
+}
+
+// summary describes how many of each kind of change there are in the diff
+func summary(diff ci.ResultsDiff) string {
+	return fmt.Sprintf(
+		"%d new, %d resolved and %d unchanged %s, %d package %s and %d license %s",
+		len(diff.NewVulnerabilities),
+		len(diff.ResolvedVulnerabilities),
+		len(diff.UnchangedVulnerabilities),
+		output.Form(len(diff.UnchangedVulnerabilities), "vulnerability", "vulnerabilities"),
+		len(diff.Packages),
+		output.Form(len(diff.Packages), "change", "changes"),
+		len(diff.Licenses),
+		output.Form(len(diff.Licenses), "change", "changes"),
+	)
+}
+
+func vulnerabilityRows(vulns []ci.VulnerabilityDiff, markdown bool) []table.Row {
+	rows := make([]table.Row, 0, len(vulns))
+
+	for _, vuln := range vulns {
+		links := make([]string, 0, len(vuln.IDs))
+		for _, id := range vuln.IDs {
+			links = append(links, output.OSVBaseVulnerabilityURL+id)
+		}
+
+		separator := "\n"
+		if markdown {
+			separator = "<br/>"
+		}
+
+		rows = append(rows, table.Row{strings.Join(links, separator), vuln.MaxSeverity, vuln.Ecosystem, vuln.Package, vuln.Version, vuln.Source})
+	}
+
+	return rows
+}
+
+func packageRows(packages []ci.PackageDiff) []table.Row {
+	rows := make([]table.Row, 0, len(packages))
+
+	for _, pkg := range packages {
+		rows = append(rows, table.Row{pkg.Change, pkg.Ecosystem, pkg.Package, pkg.OldVersion, pkg.NewVersion, pkg.Source})
+	}
+
+	return rows
+}
+
+func licenseRows(licenses []ci.LicenseDiff) []table.Row {
+	rows := make([]table.Row, 0, len(licenses))
+
+	for _, license := range licenses {
+		oldLicenses := make([]string, 0, len(license.OldLicenses))
+		for _, l := range license.OldLicenses {
+			oldLicenses = append(oldLicenses, string(l))
+		}
+
+		newLicenses := make([]string, 0, len(license.NewLicenses))
+		for _, l := range license.NewLicenses {
+			newLicenses = append(newLicenses, string(l))
+		}
+
+		rows = append(rows, table.Row{license.Ecosystem, license.Package, strings.Join(oldLicenses, ", "), strings.Join(newLicenses, ", "), license.Source})
+	}
+
+	return rows
+}
+
+// section is a table of one kind of change within the diff
+type section struct {
+	title  string
+	header table.Row
+	rows   []table.Row
+}
+
+func sections(diff ci.ResultsDiff, markdown bool) []section {
+	vulnHeader := table.Row{"OSV URL", "CVSS", "Ecosystem", "Package", "Version", "Source"}
+
+	// unchanged vulnerabilities are only counted, as they are usually the
+	// majority and are available in full from the JSON output
+	return []section{
+		{"New vulnerabilities", vulnHeader, vulnerabilityRows(diff.NewVulnerabilities, markdown)},
+		{"Resolved vulnerabilities", vulnHeader, vulnerabilityRows(diff.ResolvedVulnerabilities, markdown)},
+		{"Package changes", table.Row{"Change", "Ecosystem", "Package", "Old version", "New version", "Source"}, packageRows(diff.Packages)},
+		{"License changes", table.Row{"Ecosystem", "Package", "Old licenses", "New licenses", "Source"}, licenseRows(diff.Licenses)},
+	}
+}
+
+func printTable(diff ci.ResultsDiff, w io.Writer, terminalWidth int) {
+	if terminalWidth <= 0 {
+		text.DisableColors()
+	}
+
+	fmt.Fprintf(w, "%s\n", summary(diff))
+
+	for _, s := range sections(diff, false) {
+		if len(s.rows) == 0 {
+			continue
+		}
+
+		outputTable := table.NewWriter()
+		outputTable.SetOutputMirror(w)
+
+		// use fancy characters if we're outputting to a terminal
+		if terminalWidth > 0 {
+			outputTable.SetStyle(table.StyleRounded)
+			outputTable.SetAllowedRowLength(terminalWidth)
+		}
+
+		outputTable.SetTitle(s.title)
+		outputTable.AppendHeader(s.header)
+		outputTable.AppendRows(s.rows)
+		outputTable.Render()
+	}
+}
+
+func printMarkdown(diff ci.ResultsDiff, w io.Writer) {
+	text.DisableColors()
+
+	fmt.Fprintf(w, "%s\n", summary(diff))
+
+	for _, s := range sections(diff, true) {
+		if len(s.rows) == 0 {
+			continue
+		}
+
+		fmt.Fprintf(w, "\n### %s\n\n", s.title)
+
+		outputTable := table.NewWriter()
+		outputTable.SetOutputMirror(w)
+		outputTable.AppendHeader(s.header)
+		outputTable.AppendRows(s.rows)
+		outputTable.RenderMarkdown()
+	}
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestRun_Diff(t *testing.T) {
+	t.Parallel()
+
+	tests := []cliTestCase{
+		{
+			name: "table output",
+			args: []string{"", "diff", "./fixtures/diff/old.json", "./fixtures/diff/new.json"},
+			exit: 0,
+		},
+		{
+			name: "markdown output",
+			args: []string{"", "diff", "--format", "markdown", "./fixtures/diff/old.json", "./fixtures/diff/new.json"},
+			exit: 0,
+		},
+		{
+			name: "json output",
+			args: []string{"", "diff", "--format", "json", "./fixtures/diff/old.json", "./fixtures/diff/new.json"},
+			exit: 0,
+		},
+		{
+			name: "no changes",
+			args: []string{"", "diff", "./fixtures/diff/new.json", "./fixtures/diff/new.json"},
+			exit: 0,
+		},
+		{
+			name: "missing results",
+			args: []string{"", "diff", "./fixtures/diff/old.json"},
+			exit: 127,
+		},
+		{
+			name: "results that do not exist",
+			args: []string{"", "diff", "./fixtures/diff/old.json", "./fixtures/diff/does-not-exist.json"},
+			exit: 127,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			testCli(t, tt)
+		})
+	}
+}
@@ -1,12 +1,15 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
 	"os"
 	"slices"
 
+	"github.com/google/osv-scanner/cmd/osv-scanner/configcmd"
+	"github.com/google/osv-scanner/cmd/osv-scanner/diff"
 	"github.com/google/osv-scanner/cmd/osv-scanner/fix"
 	"github.com/google/osv-scanner/cmd/osv-scanner/scan"
 	"github.com/google/osv-scanner/cmd/osv-scanner/update"
@@ -45,6 +48,8 @@ func run(args []string, stdout, stderr io.Writer) int {
 			scan.Command(stdout, stderr, &r),
 			fix.Command(stdout, stderr, &r),
 			update.Command(stdout, stderr, &r),
+			diff.Command(stdout, stderr, &r),
+			configcmd.Command(stdout, stderr, &r),
 		},
 	}
 
@@ -60,6 +65,9 @@ func run(args []string, stdout, stderr io.Writer) int {
 		case errors.Is(err, osvscanner.NoPackagesFoundErr):
 			r.Errorf("No package sources found, --help for usage information.\n")
 			return 0
+		case errors.Is(err, context.Canceled):
+			r.Errorf("Scan cancelled\n")
+			return 130
 		case errors.Is(err, osvscanner.ErrAPIFailed):
 			r.Errorf("%v\n", err)
 			return 129
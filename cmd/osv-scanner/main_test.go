@@ -638,6 +638,16 @@ func TestRun_Licenses(t *testing.T) {
 			args: []string{"", "--format=json", "--experimental-licenses-summary", "./fixtures/locks-licenses/package-lock.json"},
 			exit: 0,
 		},
+		{
+			name: "Denylist with unrecognized licenses",
+			args: []string{"", "--experimental-licenses-denylist", "GPL-3.0-only,MIT OR ISC,not-a-license", "./fixtures/locks-licenses/package-lock.json"},
+			exit: 127,
+		},
+		{
+			name: "Denylist with license summary",
+			args: []string{"", "--experimental-licenses-summary", "--experimental-licenses-denylist", "GPL-3.0-only", "./fixtures/locks-licenses/package-lock.json"},
+			exit: 127,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -747,6 +757,56 @@ func TestRun_WithExplicitParsers(t *testing.T) {
 	})
 }
 
+func TestRun_FailOn(t *testing.T) {
+	t.Parallel()
+
+	tests := []cliTestCase{
+		{
+			name: "invalid severity threshold",
+			args: []string{"", "--fail-on", "severe", "./fixtures/locks-many/composer.lock"},
+			exit: 127,
+		},
+		{
+			name: "invalid unknown severity handling",
+			args: []string{"", "--fail-on", "high", "--unknown-severity", "maybe", "./fixtures/locks-many/composer.lock"},
+			exit: 127,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			testCli(t, tt)
+		})
+	}
+}
+
+func TestRun_Watch(t *testing.T) {
+	t.Parallel()
+
+	tests := []cliTestCase{
+		{
+			name: "only the table format can be watched",
+			args: []string{"", "--watch", "--format", "json", "./fixtures/locks-many"},
+			exit: 127,
+		},
+		{
+			name: "sboms cannot be watched",
+			args: []string{"", "--watch", "--sbom", "./fixtures/sbom-insecure/alpine.cdx.xml"},
+			exit: 127,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			testCli(t, tt)
+		})
+	}
+}
+
 func TestRun_YarnPackageOnly(t *testing.T) {
 	t.Parallel()
 	testCases := []string{
@@ -5,10 +5,12 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"os/signal"
 	"slices"
 	"strings"
 
 	"github.com/google/osv-scanner/pkg/lockfile"
+	"github.com/google/osv-scanner/pkg/osv"
 
 	"github.com/google/osv-scanner/pkg/osvscanner"
 	"github.com/google/osv-scanner/pkg/reporter"
@@ -33,7 +35,46 @@ func Command(stdout, stderr io.Writer, r *reporter.Reporter) *cli.Command {
 			&cli.StringSliceFlag{
 				Name:      "lockfile",
 				Aliases:   []string{"L"},
-				Usage:     "scan package lockfile on this path",
+				Usage:     "scan package lockfile on this path, or read it from stdin with an explicit parser (i.e. requirements.txt:-)",
+				TakesFile: true,
+			},
+			&cli.StringFlag{
+				Name:  "git-rev",
+				Usage: "scan the lockfiles of the given directories as they were at this git revision, without checking it out",
+			},
+			&cli.StringFlag{
+				Name:  "git-diff",
+				Usage: "only report vulnerabilities introduced between two git revisions of the given directories, in the form <base>..<head>",
+			},
+			&cli.StringFlag{
+				Name:      "baseline",
+				Usage:     "suppress vulnerabilities that are already present in the JSON results of a previous scan",
+				TakesFile: true,
+			},
+			&cli.StringFlag{
+				Name:      "write-baseline",
+				Usage:     "write the JSON results of this scan to the given path, for use with --baseline in future scans",
+				TakesFile: true,
+			},
+			&cli.StringFlag{
+				Name:  "fail-on",
+				Usage: "only exit with a non-zero code for vulnerabilities at or above this severity, either a rating (low, medium, high or critical) or a CVSS score",
+			},
+			&cli.StringFlag{
+				Name:  "unknown-severity",
+				Usage: "whether vulnerabilities without a known severity cause a non-zero exit code when using --fail-on; value can be: " + osvscanner.UnknownSeverityFail + ", " + osvscanner.UnknownSeverityIgnore,
+			},
+			&cli.BoolFlag{
+				Name:  "report-config-issues",
+				Usage: "report problems with the config files used by the scan, such as ignores that did not match any vulnerability or that expire soon",
+			},
+			&cli.BoolFlag{
+				Name:  "watch",
+				Usage: "keep scanning the lockfiles of the given directories and lockfile paths whenever they or their source files change, until interrupted",
+			},
+			&cli.StringFlag{
+				Name:      "stdin-source-dir",
+				Usage:     "directory to match the packages of a lockfile read from stdin against, such as the one containing its package.json",
 				TakesFile: true,
 			},
 			&cli.StringSliceFlag{
@@ -116,6 +157,33 @@ func Command(stdout, stderr io.Writer, r *reporter.Reporter) *cli.Command {
 				Usage:  "sets the path that local databases should be stored",
 				Hidden: true,
 			},
+			&cli.BoolFlag{
+				Name:  "experimental-api-cache",
+				Usage: "caches responses from the OSV API on disk so they can be reused by later scans",
+			},
+			&cli.StringFlag{
+				Name:      "experimental-api-cache-dir",
+				Usage:     "sets the directory that API responses are cached in",
+				TakesFile: true,
+			},
+			&cli.DurationFlag{
+				Name:  "experimental-api-cache-ttl",
+				Usage: "sets how long the results of package queries are cached for",
+				Value: osv.DefaultCacheQueryTTL,
+			},
+			&cli.BoolFlag{
+				Name:  "experimental-extraction-cache",
+				Usage: "caches extracted lockfiles on disk so that unchanged lockfiles are not extracted again",
+			},
+			&cli.StringFlag{
+				Name:      "experimental-extraction-cache-dir",
+				Usage:     "sets the directory that extracted lockfiles are cached in",
+				TakesFile: true,
+			},
+			&cli.BoolFlag{
+				Name:  "experimental-scan-archives",
+				Usage: "scans lockfiles within .zip, .tar.gz, .tgz and .whl archives found in directories",
+			},
 			&cli.BoolFlag{
 				Name:  "experimental-all-packages",
 				Usage: "when json output is selected, prints all packages",
@@ -128,6 +196,10 @@ func Command(stdout, stderr io.Writer, r *reporter.Reporter) *cli.Command {
 				Name:  "experimental-licenses",
 				Usage: "report on licenses based on an allowlist",
 			},
+			&cli.StringSliceFlag{
+				Name:  "experimental-licenses-denylist",
+				Usage: "report on licenses based on a denylist, which takes precedence over the allowlist",
+			},
 			&cli.StringFlag{
 				Name:      "experimental-oci-image",
 				Usage:     "scan an exported *docker* container image archive (exported using `docker save` command) file",
@@ -150,10 +222,51 @@ func Command(stdout, stderr io.Writer, r *reporter.Reporter) *cli.Command {
 				Name:  "enable-parsers",
 				Usage: fmt.Sprintf("Explicitly define which lockfile to parse. If set, any non-set parsers will be ignored. (Available parsers: %v)", lockfile.ListExtractors()),
 			},
+			&cli.StringSliceFlag{
+				Name:  "include-path",
+				Usage: "only scan paths within directories that match this glob, optionally limited to a parser with <parser>:<glob>",
+			},
+			&cli.StringSliceFlag{
+				Name:  "exclude-path",
+				Usage: "skip paths within directories that match this glob, optionally limited to a parser with <parser>:<glob>",
+			},
+			&cli.IntFlag{
+				Name:  "parallelism",
+				Usage: "number of files to scan at once when scanning directories, defaults to the number of CPUs",
+			},
 			&cli.BoolFlag{
 				Name:  "no-config",
 				Usage: "Disable osv-scanner config and always use a default configuration",
 			},
+			&cli.StringFlag{
+				Name:  "api-base-url",
+				Usage: "sets the base URL of the OSV API (or a compatible service) to query",
+			},
+			&cli.StringSliceFlag{
+				Name:  "api-header",
+				Usage: "adds a header to requests made to the OSV API, in the form \"Name: value\"",
+			},
+			&cli.StringFlag{
+				Name:      "api-ca-cert",
+				Usage:     "trusts the certificates in this PEM file when connecting to the OSV API",
+				TakesFile: true,
+			},
+			&cli.StringFlag{
+				Name:  "api-proxy",
+				Usage: "sets the HTTP proxy to use when connecting to the OSV API",
+			},
+			&cli.DurationFlag{
+				Name:  "api-timeout",
+				Usage: "sets the time limit for each request to the OSV API",
+			},
+			&cli.IntFlag{
+				Name:  "api-max-retries",
+				Usage: "sets how many times a request to the OSV API is attempted before giving up",
+			},
+			&cli.IntFlag{
+				Name:  "api-max-concurrent-requests",
+				Usage: "sets how many requests are made to the OSV API at once",
+			},
 		},
 		ArgsUsage: "[directory1 directory2...]",
 		Action: func(c *cli.Context) error {
@@ -190,17 +303,26 @@ func action(context *cli.Context, stdout, stderr io.Writer) (reporter.Reporter,
 		}
 	}
 
-	if context.Bool("experimental-licenses-summary") && context.IsSet("experimental-licenses") {
-		return nil, errors.New("--experimental-licenses-summary and --experimental-licenses flags cannot be set")
+	// NOTICE files attribute every package, so need all of their licenses
+	notice := format == "notice" || format == "notice-markdown"
+
+	if context.Bool("watch") && format != "table" {
+		return nil, errors.New("--watch can only be used with the table format")
 	}
-	allowlist := context.StringSlice("experimental-licenses")
-	if context.IsSet("experimental-licenses") {
-		if len(allowlist) == 0 ||
-			(len(allowlist) == 1 && allowlist[0] == "") {
-			return nil, errors.New("--experimental-licenses requires at least one value")
+
+	for _, flag := range []string{"experimental-licenses", "experimental-licenses-denylist"} {
+		if context.Bool("experimental-licenses-summary") && context.IsSet(flag) {
+			return nil, fmt.Errorf("--experimental-licenses-summary and --%s flags cannot be set", flag)
 		}
-		if unrecognized := spdx.Unrecognized(allowlist); len(unrecognized) > 0 {
-			return nil, fmt.Errorf("--experimental-licenses requires comma-separated spdx licenses. The following license(s) are not recognized as spdx: %s", strings.Join(unrecognized, ","))
+		licenses := context.StringSlice(flag)
+		if context.IsSet(flag) {
+			if len(licenses) == 0 ||
+				(len(licenses) == 1 && licenses[0] == "") {
+				return nil, fmt.Errorf("--%s requires at least one value", flag)
+			}
+			if unrecognized := spdx.Unrecognized(licenses); len(unrecognized) > 0 {
+				return nil, fmt.Errorf("--%s requires comma-separated spdx licenses. The following license(s) are not recognized as spdx: %s", flag, strings.Join(unrecognized, ","))
+			}
 		}
 	}
 
@@ -221,7 +343,16 @@ func action(context *cli.Context, stdout, stderr io.Writer) (reporter.Reporter,
 		callAnalysisStates = createCallAnalysisStates(context.StringSlice("call-analysis"), context.StringSlice("no-call-analysis"))
 	}
 
-	vulnResult, err := osvscanner.DoScan(osvscanner.ScannerActions{
+	apiHeaders, err := parseAPIHeaders(context.StringSlice("api-header"))
+	if err != nil {
+		return r, err
+	}
+
+	// stop scanning (and any requests that are in flight) on Ctrl+C
+	ctx, stop := signal.NotifyContext(context.Context, os.Interrupt)
+	defer stop()
+
+	actions := osvscanner.ScannerActions{
 		LockfilePaths:          context.StringSlice("lockfile"),
 		SBOMPaths:              context.StringSlice("sbom"),
 		DockerContainerNames:   context.StringSlice("docker"),
@@ -234,22 +365,56 @@ func action(context *cli.Context, stdout, stderr io.Writer) (reporter.Reporter,
 		ConsiderScanPathAsRoot: context.Bool("consider-scan-path-as-root"),
 		PathRelativeToScanDir:  context.Bool("paths-relative-to-scan-dir"),
 		EnableParsers:          context.StringSlice("enable-parsers"),
+		Parallelism:            context.Int("parallelism"),
+		IncludePaths:           context.StringSlice("include-path"),
+		ExcludePaths:           context.StringSlice("exclude-path"),
+		StdinSourceDir:         context.String("stdin-source-dir"),
+		GitRev:                 context.String("git-rev"),
+		GitDiff:                context.String("git-diff"),
+		BaselinePath:           context.String("baseline"),
+		WriteBaselinePath:      context.String("write-baseline"),
+		FailOn:                 context.String("fail-on"),
+		UnknownSeverity:        context.String("unknown-severity"),
+		ReportConfigIssues:     context.Bool("report-config-issues"),
+		APIClientConfig: osv.ClientConfig{
+			BaseURL:               context.String("api-base-url"),
+			Headers:               apiHeaders,
+			CACertFile:            context.String("api-ca-cert"),
+			ProxyURL:              context.String("api-proxy"),
+			Timeout:               context.Duration("api-timeout"),
+			MaxRetryAttempts:      context.Int("api-max-retries"),
+			MaxConcurrentRequests: context.Int("api-max-concurrent-requests"),
+		},
 		ExperimentalScannerActions: osvscanner.ExperimentalScannerActions{
-			LocalDBPath:       context.String("experimental-local-db-path"),
-			DownloadDatabases: context.Bool("experimental-download-offline-databases"),
-			CompareOffline:    context.Bool("experimental-offline"),
+			LocalDBPath:        context.String("experimental-local-db-path"),
+			DownloadDatabases:  context.Bool("experimental-download-offline-databases"),
+			CompareOffline:     context.Bool("experimental-offline"),
+			UseAPICache:        context.Bool("experimental-api-cache"),
+			APICacheDir:        context.String("experimental-api-cache-dir"),
+			APICacheTTL:        context.Duration("experimental-api-cache-ttl"),
+			UseExtractionCache: context.Bool("experimental-extraction-cache"),
+			ExtractionCacheDir: context.String("experimental-extraction-cache-dir"),
+			ScanArchives:       context.Bool("experimental-scan-archives"),
 			// License summary mode causes all
 			// packages to appear in the json as
 			// every package has a license - even
 			// if it's just the UNKNOWN license.
 			ShowAllPackages: context.Bool("experimental-all-packages") ||
-				context.Bool("experimental-licenses-summary"),
+				context.Bool("experimental-licenses-summary") || notice,
 			ScanLicensesSummary:   context.Bool("experimental-licenses-summary"),
 			ScanLicensesAllowlist: context.StringSlice("experimental-licenses"),
+			ScanLicensesDenylist:  context.StringSlice("experimental-licenses-denylist"),
+			ScanLicenseNotices:    notice,
 			ScanOCIImage:          context.String("experimental-oci-image"),
 			OnlyPackages:          context.Bool("experimental-only-packages"),
 		},
-	}, r)
+	}
+
+	if context.Bool("watch") {
+		return r, watch(ctx, actions, r)
+	}
+
+	vulnResult, err := osvscanner.DoScanWithContext(ctx, actions, r)
 
 	if err != nil && !errors.Is(err, osvscanner.NoPackagesFoundErr) && !errors.Is(err, osvscanner.VulnerabilitiesFoundErr) {
 		return r, err
@@ -262,3 +427,22 @@ func action(context *cli.Context, stdout, stderr io.Writer) (reporter.Reporter,
 	// This may be nil.
 	return r, err
 }
+
+// parseAPIHeaders parses headers given in the form "Name: value"
+func parseAPIHeaders(values []string) (map[string]string, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	headers := make(map[string]string, len(values))
+	for _, value := range values {
+		name, val, found := strings.Cut(value, ":")
+		name = strings.TrimSpace(name)
+		if !found || name == "" {
+			return nil, fmt.Errorf("--api-header must be in the form \"Name: value\", got %q", value)
+		}
+		headers[name] = strings.TrimSpace(val)
+	}
+
+	return headers, nil
+}
@@ -0,0 +1,45 @@
+package scan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/osv-scanner/internal/ci"
+	"github.com/google/osv-scanner/internal/output"
+	"github.com/google/osv-scanner/pkg/models"
+	"github.com/google/osv-scanner/pkg/osvscanner"
+	"github.com/google/osv-scanner/pkg/reporter"
+)
+
+// watch scans the lockfiles and directories of the actions, rendering the
+// results again (along with what has changed) whenever they are scanned again
+func watch(ctx context.Context, actions osvscanner.ScannerActions, r reporter.Reporter) error {
+	var previous *models.VulnerabilityResults
+
+	return osvscanner.NewScanner(osvscanner.WithReporter(r)).Watch(ctx, actions, func(results models.VulnerabilityResults, err error) {
+		if err != nil && !errors.Is(err, osvscanner.NoPackagesFoundErr) && !errors.Is(err, osvscanner.VulnerabilitiesFoundErr) {
+			r.Errorf("Failed to scan: %v\n", err)
+
+			return
+		}
+
+		if previous != nil {
+			diff := ci.CompareVulnerabilityResults(*previous, results)
+
+			r.Infof(
+				"\n%d new %s and %d resolved %s since the previous scan\n",
+				len(diff.NewVulnerabilities),
+				output.Form(len(diff.NewVulnerabilities), "vulnerability", "vulnerabilities"),
+				len(diff.ResolvedVulnerabilities),
+				output.Form(len(diff.ResolvedVulnerabilities), "vulnerability", "vulnerabilities"),
+			)
+		}
+
+		previous = &results
+
+		if errPrint := r.PrintResult(&results); errPrint != nil {
+			r.Errorf("%v\n", fmt.Errorf("failed to write output: %w", errPrint))
+		}
+	})
+}
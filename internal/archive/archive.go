@@ -0,0 +1,346 @@
+// Package archive reads the files within archives (such as release bundles
+// and Python wheels) so that they can be extracted like any other DepFile.
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/osv-scanner/pkg/lockfile"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+)
+
+// Separator separates the path of an archive from the path of a file within
+// it, i.e. "release.zip!/app/package-lock.json"
+const Separator = "!/"
+
+// ErrLimitExceeded is what files within an archive are skipped with when they
+// are larger than allowed once decompressed, which can be a sign of a
+// decompression bomb
+var ErrLimitExceeded = errors.New("file exceeds size limit once decompressed")
+
+// Limits bounds how much work is done when reading an archive
+type Limits struct {
+	// MaxDepth is how many levels of archives within archives are read,
+	// with deeper archives being skipped
+	MaxDepth int
+	// MaxFileSize is the largest that a file within an archive can be
+	// once decompressed
+	MaxFileSize int64
+	// MaxTotalSize is the most that will be decompressed from an archive,
+	// including any archives that are within it
+	MaxTotalSize int64
+}
+
+// DefaultLimits are the limits used when scanning archives found in
+// directories, which bound how much is written to the temporary directory
+// for each archive as what is decompressed is not kept in memory
+var DefaultLimits = Limits{
+	MaxDepth:     3,
+	MaxFileSize:  64 << 20,
+	MaxTotalSize: 512 << 20,
+}
+
+// Options configures which of the files within an archive are read
+type Options struct {
+	Limits
+
+	// Wanted reports if the file with the given name (relative to the root
+	// of its archive) is of use, as other files are not decompressed at all;
+	// archives within the archive are always wanted, and if Wanted is nil
+	// then so is every file
+	Wanted func(name string) bool
+	// Skipped is called with the path of each wanted file that is not read
+	// because it exceeds the limits, which otherwise are silently skipped
+	Skipped func(path string, err error)
+}
+
+func (o Options) wants(name string) bool {
+	return o.Wanted == nil || IsArchive(name) || o.Wanted(name)
+}
+
+func (o Options) skip(path string, err error) {
+	if o.Skipped != nil {
+		o.Skipped(path, err)
+	}
+}
+
+// IsArchive returns true if the file at the given path is a supported archive
+func IsArchive(p string) bool {
+	return isZip(p) || isTarGz(p)
+}
+
+func isZip(p string) bool {
+	ext := strings.ToLower(filepath.Ext(p))
+
+	return ext == ".zip" || ext == ".whl"
+}
+
+func isTarGz(p string) bool {
+	lower := strings.ToLower(p)
+
+	return strings.HasSuffix(lower, ".tar.gz") || strings.HasSuffix(lower, ".tgz")
+}
+
+// archive holds the decompressed contents of an archive, which are written
+// to files in a temporary directory rather than kept in memory
+type archive struct {
+	path string
+	dir  string
+	// files maps the names of the files in the archive to where they were decompressed to
+	files map[string]string
+	// the names of the files in the order they appear in the archive
+	names []string
+}
+
+// File is a file within an archive, which can open other files relative to
+// itself within the same archive
+type File struct {
+	io.Reader
+
+	archive *archive
+	name    string
+	file    *os.File
+}
+
+var _ lockfile.NestedDepFile = File{}
+
+func (f File) Path() string {
+	if f.archive == nil {
+		return ""
+	}
+
+	return f.archive.path + Separator + f.name
+}
+
+func (f File) Close() error {
+	if f.file == nil {
+		return nil
+	}
+
+	return f.file.Close()
+}
+
+// Open opens another file in the same archive, with relative paths being
+// resolved from the directory of this file; absolute paths are only opened
+// if they are within the archive, as the archive is what is being scanned
+func (f File) Open(p string) (lockfile.NestedDepFile, error) {
+	var name string
+
+	switch {
+	case strings.HasPrefix(p, f.archive.path+Separator):
+		name = strings.TrimPrefix(p, f.archive.path+Separator)
+	case filepath.IsAbs(p) || path.IsAbs(filepath.ToSlash(p)):
+		return nil, &fs.PathError{Op: "open", Path: p, Err: fs.ErrNotExist}
+	default:
+		name = path.Join(path.Dir(f.name), filepath.ToSlash(p))
+	}
+
+	return f.archive.open(name)
+}
+
+func (a *archive) open(name string) (File, error) {
+	spooled, ok := a.files[name]
+	if !ok {
+		return File{}, &fs.PathError{Op: "open", Path: a.path + Separator + name, Err: fs.ErrNotExist}
+	}
+
+	file, err := os.Open(spooled)
+	if err != nil {
+		return File{}, err
+	}
+
+	// like local files, decode utf-16 files based on their byte order mark
+	decoded := transform.NewReader(file, unicode.BOMOverride(encoding.Nop.NewDecoder()))
+
+	return File{Reader: decoded, archive: a, name: name, file: file}, nil
+}
+
+// Walk calls fn for each wanted file within the archive at the given path,
+// in the order they appear in the archive, including the files within any
+// archives that it contains up to the depth allowed by the limits. Files that
+// exceed the limits are skipped, rather than stopping the walk.
+//
+// Every wanted file is decompressed before fn is first called, so that files
+// can open other files regardless of where they are in the archive.
+func Walk(archivePath string, opts Options, fn func(f File) error) error {
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	dir, err := os.MkdirTemp("", "osv-scanner-archive-")
+	if err != nil {
+		return fmt.Errorf("could not create directory to decompress %s to: %w", archivePath, err)
+	}
+	defer os.RemoveAll(dir)
+
+	remaining := opts.MaxTotalSize
+
+	return walk(archivePath, dir, f, info.Size(), opts, 1, &remaining, fn)
+}
+
+func walk(archivePath string, dir string, r io.ReaderAt, size int64, opts Options, depth int, remaining *int64, fn func(f File) error) error {
+	a := &archive{path: archivePath, dir: dir, files: make(map[string]string)}
+
+	var err error
+
+	switch {
+	case isZip(archivePath):
+		err = a.readZip(r, size, opts, depth, remaining)
+	case isTarGz(archivePath):
+		err = a.readTarGz(io.NewSectionReader(r, 0, size), opts, depth, remaining)
+	default:
+		err = fmt.Errorf("%s is not a supported archive", archivePath)
+	}
+
+	if err != nil {
+		return fmt.Errorf("could not read %s: %w", archivePath, err)
+	}
+
+	for _, name := range a.names {
+		file, err := a.open(name)
+		if err != nil {
+			return err
+		}
+
+		if IsArchive(name) {
+			var info fs.FileInfo
+			if info, err = file.file.Stat(); err == nil {
+				err = walk(file.Path(), dir, file.file, info.Size(), opts, depth+1, remaining, fn)
+			}
+		} else {
+			err = fn(file)
+		}
+
+		file.Close()
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// add reads the content of a file in the archive if it is wanted, enforcing
+// the size limits on what is actually decompressed rather than what the
+// archive claims, and skipping the file if it exceeds them
+func (a *archive) add(name string, r io.Reader, opts Options, depth int, remaining *int64) error {
+	name = path.Clean(strings.TrimPrefix(name, "/"))
+
+	// entries outside the root of the archive cannot be opened by other
+	// files anyway, so there is no point reading them
+	if name == "." || name == ".." || strings.HasPrefix(name, "../") {
+		return nil
+	}
+
+	// archives nested deeper than allowed are skipped
+	if !opts.wants(name) || (IsArchive(name) && depth >= opts.MaxDepth) {
+		return nil
+	}
+
+	limit := min(opts.MaxFileSize, *remaining)
+
+	spooled, err := os.CreateTemp(a.dir, "entry-")
+	if err != nil {
+		return err
+	}
+
+	n, err := io.Copy(spooled, io.LimitReader(r, limit+1))
+	if closeErr := spooled.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	if n > limit {
+		opts.skip(a.path+Separator+name, ErrLimitExceeded)
+
+		return os.Remove(spooled.Name())
+	}
+
+	*remaining -= n
+
+	if _, exists := a.files[name]; !exists {
+		a.names = append(a.names, name)
+	}
+	a.files[name] = spooled.Name()
+
+	return nil
+}
+
+func (a *archive) readZip(r io.ReaderAt, size int64, opts Options, depth int, remaining *int64) error {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return err
+	}
+
+	for _, zf := range zr.File {
+		if !zf.Mode().IsRegular() {
+			continue
+		}
+
+		rc, err := zf.Open()
+		if err != nil {
+			return err
+		}
+
+		err = a.add(zf.Name, rc, opts, depth, remaining)
+		rc.Close()
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (a *archive) readTarGz(r io.Reader, opts Options, depth int, remaining *int64) error {
+	gr, err := gzip.NewReader(r)
+	if err != nil {
+		return err
+	}
+	defer gr.Close()
+
+	tr := tar.NewReader(gr)
+
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		if err := a.add(header.Name, tr, opts, depth, remaining); err != nil {
+			return err
+		}
+	}
+}
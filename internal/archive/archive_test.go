@@ -0,0 +1,307 @@
+package archive_test
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/osv-scanner/internal/archive"
+)
+
+type entry struct {
+	name    string
+	content []byte
+}
+
+func makeZip(t *testing.T, entries ...entry) []byte {
+	t.Helper()
+
+	buf := &bytes.Buffer{}
+	w := zip.NewWriter(buf)
+
+	for _, e := range entries {
+		f, err := w.Create(e.name)
+		if err != nil {
+			t.Fatalf("could not create zip entry: %v", err)
+		}
+
+		if _, err := f.Write(e.content); err != nil {
+			t.Fatalf("could not write zip entry: %v", err)
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatalf("could not write zip: %v", err)
+	}
+
+	return buf.Bytes()
+}
+
+func makeTarGz(t *testing.T, entries ...entry) []byte {
+	t.Helper()
+
+	buf := &bytes.Buffer{}
+	gw := gzip.NewWriter(buf)
+	tw := tar.NewWriter(gw)
+
+	for _, e := range entries {
+		header := &tar.Header{Name: e.name, Mode: 0600, Size: int64(len(e.content)), Typeflag: tar.TypeReg}
+
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatalf("could not write tar header: %v", err)
+		}
+
+		if _, err := tw.Write(e.content); err != nil {
+			t.Fatalf("could not write tar entry: %v", err)
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		t.Fatalf("could not write tar: %v", err)
+	}
+
+	if err := gw.Close(); err != nil {
+		t.Fatalf("could not write gzip: %v", err)
+	}
+
+	return buf.Bytes()
+}
+
+func writeArchive(t *testing.T, name string, content []byte) string {
+	t.Helper()
+
+	p := filepath.Join(t.TempDir(), name)
+
+	if err := os.WriteFile(p, content, 0600); err != nil {
+		t.Fatalf("could not write archive: %v", err)
+	}
+
+	return p
+}
+
+// walk returns the paths of the files within the archive, relative to it
+func walk(t *testing.T, p string, opts archive.Options) ([]string, error) {
+	t.Helper()
+
+	var paths []string
+
+	err := archive.Walk(p, opts, func(f archive.File) error {
+		paths = append(paths, strings.TrimPrefix(f.Path(), p))
+
+		return nil
+	})
+
+	return paths, err
+}
+
+func TestIsArchive(t *testing.T) {
+	t.Parallel()
+
+	for p, want := range map[string]bool{
+		"release.zip":                       true,
+		"dist/requests-2.31.0-py3-none.whl": true,
+		"bundle.tar.gz":                     true,
+		"bundle.TGZ":                        true,
+		"bundle.tar":                        false,
+		"package-lock.json":                 false,
+		"release.zip/package-lock.json":     false,
+		"archive.tar.gz.sha256":             false,
+		"app-1.0.0.jar":                     false,
+		"nested/release.zip!/inner/app.tgz": true,
+	} {
+		if got := archive.IsArchive(p); got != want {
+			t.Errorf("IsArchive(%q) = %v, want %v", p, got, want)
+		}
+	}
+}
+
+func TestWalk(t *testing.T) {
+	t.Parallel()
+
+	inner := makeTarGz(t,
+		entry{"package/package-lock.json", []byte("{}")},
+		entry{"package/package.json", []byte("{}")},
+	)
+
+	p := writeArchive(t, "release.zip", makeZip(t,
+		entry{"app/package-lock.json", []byte("lockfile")},
+		entry{"app/package.json", []byte("source")},
+		entry{"./docs/../README.md", []byte("readme")},
+		entry{"vendor/lib.tgz", inner},
+	))
+
+	got, err := walk(t, p, archive.Options{Limits: archive.DefaultLimits})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []string{
+		"!/app/package-lock.json",
+		"!/app/package.json",
+		"!/README.md",
+		"!/vendor/lib.tgz!/package/package-lock.json",
+		"!/vendor/lib.tgz!/package/package.json",
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Walk() returned unexpected files (-want +got):\n%s", diff)
+	}
+
+	// only the files that are wanted are read, along with nested archives
+	got, err = walk(t, p, archive.Options{
+		Limits: archive.DefaultLimits,
+		Wanted: func(name string) bool { return strings.HasSuffix(name, "package-lock.json") },
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want = []string{
+		"!/app/package-lock.json",
+		"!/vendor/lib.tgz!/package/package-lock.json",
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Walk() returned unexpected wanted files (-want +got):\n%s", diff)
+	}
+}
+
+func TestFile_Open(t *testing.T) {
+	t.Parallel()
+
+	p := writeArchive(t, "release.tar.gz", makeTarGz(t,
+		entry{"app/package-lock.json", []byte("lockfile")},
+		entry{"app/package.json", []byte("source")},
+		entry{"build.gradle", []byte("gradle")},
+	))
+
+	err := archive.Walk(p, archive.Options{Limits: archive.DefaultLimits}, func(f archive.File) error {
+		if f.Path() != p+"!/app/package-lock.json" {
+			return nil
+		}
+
+		for name, want := range map[string]string{
+			"package.json":                p + "!/app/package.json",
+			"../build.gradle":             p + "!/build.gradle",
+			p + "!/app/package-lock.json": p + "!/app/package-lock.json",
+		} {
+			sourceFile, err := f.Open(name)
+			if err != nil {
+				t.Errorf("Open(%q) unexpected error: %v", name, err)
+
+				continue
+			}
+
+			if sourceFile.Path() != want {
+				t.Errorf("Open(%q).Path() = %q, want %q", name, sourceFile.Path(), want)
+			}
+		}
+
+		b, err := io.ReadAll(f)
+		if err != nil {
+			t.Errorf("unexpected error reading file: %v", err)
+		}
+
+		if string(b) != "lockfile" {
+			t.Errorf("expected to read %q, got %q", "lockfile", b)
+		}
+
+		if _, err := f.Open("../../package.json"); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("expected opening a file outside the archive to not exist, got %v", err)
+		}
+
+		// even though the archive itself exists on the local filesystem
+		if _, err := f.Open(p); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("expected opening an absolute path outside the archive to not exist, got %v", err)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestWalk_Limits(t *testing.T) {
+	t.Parallel()
+
+	// highly compressible content, like a decompression bomb
+	large := bytes.Repeat([]byte{0}, 1<<20)
+
+	p := writeArchive(t, "bomb.zip", makeZip(t,
+		entry{"a.txt", large},
+		entry{"b.txt", large},
+		entry{"package-lock.json", []byte("{}")},
+	))
+
+	// files that exceed the limits are skipped, without stopping the walk
+	for _, tt := range []struct {
+		name    string
+		limits  archive.Limits
+		want    []string
+		skipped []string
+	}{
+		{
+			name:    "file size",
+			limits:  archive.Limits{MaxDepth: 1, MaxFileSize: 1 << 19, MaxTotalSize: 1 << 30},
+			want:    []string{"!/package-lock.json"},
+			skipped: []string{"!/a.txt", "!/b.txt"},
+		},
+		{
+			name:    "total size",
+			limits:  archive.Limits{MaxDepth: 1, MaxFileSize: 1 << 20, MaxTotalSize: 1<<21 - 1},
+			want:    []string{"!/a.txt", "!/package-lock.json"},
+			skipped: []string{"!/b.txt"},
+		},
+	} {
+		var skipped []string
+
+		got, err := walk(t, p, archive.Options{
+			Limits: tt.limits,
+			Skipped: func(path string, err error) {
+				if !errors.Is(err, archive.ErrLimitExceeded) {
+					t.Errorf("%s: expected %s to be skipped for exceeding the limits, got %v", tt.name, path, err)
+				}
+
+				skipped = append(skipped, strings.TrimPrefix(path, p))
+			},
+		})
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.name, err)
+		}
+
+		if diff := cmp.Diff(tt.want, got); diff != "" {
+			t.Errorf("%s: Walk() returned unexpected files (-want +got):\n%s", tt.name, diff)
+		}
+
+		if diff := cmp.Diff(tt.skipped, skipped); diff != "" {
+			t.Errorf("%s: Walk() skipped unexpected files (-want +got):\n%s", tt.name, diff)
+		}
+	}
+
+	// archives nested deeper than allowed are skipped
+	nested := writeArchive(t, "nested.zip", makeZip(t,
+		entry{"one.zip", makeZip(t,
+			entry{"one.txt", []byte("one")},
+			entry{"two.zip", makeZip(t, entry{"two.txt", []byte("two")})},
+		)},
+	))
+
+	got, err := walk(t, nested, archive.Options{Limits: archive.Limits{MaxDepth: 2, MaxFileSize: 1 << 20, MaxTotalSize: 1 << 20}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if diff := cmp.Diff([]string{"!/one.zip!/one.txt"}, got); diff != "" {
+		t.Errorf("Walk() returned unexpected files (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,306 @@
+package ci
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+
+	"github.com/google/osv-scanner/internal/semantic"
+	"github.com/google/osv-scanner/pkg/models"
+)
+
+// PackageChange describes how a package changed between two sets of results
+type PackageChange string
+
+const (
+	PackageAdded      PackageChange = "added"
+	PackageRemoved    PackageChange = "removed"
+	PackageUpgraded   PackageChange = "upgraded"
+	PackageDowngraded PackageChange = "downgraded"
+	// PackageChanged is used when the versions of a package cannot be compared
+	PackageChanged PackageChange = "changed"
+)
+
+// ResultsDiff is the difference between two sets of results, such as those
+// of two releases, unlike DiffVulnerabilityResults which only returns the
+// vulnerabilities that are new.
+//
+// Packages are matched by their source, ecosystem and name, and the groups of
+// vulnerabilities affecting them by their IDs and aliases, so vulnerabilities
+// that still affect a package after it has been upgraded are unchanged.
+type ResultsDiff struct {
+	NewVulnerabilities       []VulnerabilityDiff `json:"new_vulnerabilities"`
+	ResolvedVulnerabilities  []VulnerabilityDiff `json:"resolved_vulnerabilities"`
+	UnchangedVulnerabilities []VulnerabilityDiff `json:"unchanged_vulnerabilities"`
+	Packages                 []PackageDiff       `json:"packages"`
+	Licenses                 []LicenseDiff       `json:"licenses"`
+}
+
+// VulnerabilityDiff is a group of vulnerabilities affecting a package, with
+// the version being from the new results unless the group was resolved
+type VulnerabilityDiff struct {
+	Source      string   `json:"source"`
+	Ecosystem   string   `json:"ecosystem"`
+	Package     string   `json:"package"`
+	Version     string   `json:"version"`
+	IDs         []string `json:"ids"`
+	Aliases     []string `json:"aliases"`
+	MaxSeverity string   `json:"max_severity"`
+}
+
+// PackageDiff is a package that was added, removed or changed version
+type PackageDiff struct {
+	Source     string        `json:"source"`
+	Ecosystem  string        `json:"ecosystem"`
+	Package    string        `json:"package"`
+	Change     PackageChange `json:"change"`
+	OldVersion string        `json:"old_version,omitempty"`
+	NewVersion string        `json:"new_version,omitempty"`
+}
+
+// LicenseDiff is a package with different licenses in the new results
+type LicenseDiff struct {
+	Source      string           `json:"source"`
+	Ecosystem   string           `json:"ecosystem"`
+	Package     string           `json:"package"`
+	OldLicenses []models.License `json:"old_licenses"`
+	NewLicenses []models.License `json:"new_licenses"`
+}
+
+// packageKey identifies a package regardless of its version
+type packageKey struct {
+	source    string
+	ecosystem string
+	name      string
+}
+
+// packageVersions are the versions of a package within a set of results,
+// as a source can have more than one version of the same package
+type packageVersions map[string]models.PackageVulns
+
+func packageVersion(pkg models.PackageInfo) string {
+	if pkg.Version == "" {
+		return pkg.Commit
+	}
+
+	return pkg.Version
+}
+
+func indexPackages(results models.VulnerabilityResults) map[packageKey]packageVersions {
+	index := make(map[packageKey]packageVersions)
+
+	for _, source := range results.Results {
+		for _, pkg := range source.Packages {
+			key := packageKey{source: source.Source.Path, ecosystem: pkg.Package.Ecosystem, name: pkg.Package.Name}
+
+			if index[key] == nil {
+				index[key] = make(packageVersions)
+			}
+
+			if _, exists := index[key][packageVersion(pkg.Package)]; !exists {
+				index[key][packageVersion(pkg.Package)] = pkg
+			}
+		}
+	}
+
+	return index
+}
+
+// versionedGroup is a group of vulnerabilities affecting a version of a package
+type versionedGroup struct {
+	version string
+	group   models.GroupInfo
+}
+
+func (vs packageVersions) groups() []versionedGroup {
+	var groups []versionedGroup
+
+	for _, version := range vs.sortedVersions() {
+		for _, group := range vs[version].Groups {
+			groups = append(groups, versionedGroup{version, group})
+		}
+	}
+
+	return groups
+}
+
+func (vs packageVersions) sortedVersions() []string {
+	versions := make([]string, 0, len(vs))
+	for version := range vs {
+		versions = append(versions, version)
+	}
+	slices.Sort(versions)
+
+	return versions
+}
+
+func (vs packageVersions) licenses() []models.License {
+	var licenses []models.License
+
+	for _, pkg := range vs {
+		for _, license := range pkg.Licenses {
+			if !slices.Contains(licenses, license) {
+				licenses = append(licenses, license)
+			}
+		}
+	}
+
+	slices.Sort(licenses)
+
+	return licenses
+}
+
+func groupsOverlap(a, b models.GroupInfo) bool {
+	for _, id := range append(slices.Clone(a.IDs), a.Aliases...) {
+		if slices.Contains(b.IDs, id) || slices.Contains(b.Aliases, id) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func newVulnerabilityDiff(key packageKey, vg versionedGroup) VulnerabilityDiff {
+	return VulnerabilityDiff{
+		Source:      key.source,
+		Ecosystem:   key.ecosystem,
+		Package:     key.name,
+		Version:     vg.version,
+		IDs:         vg.group.IDs,
+		Aliases:     vg.group.Aliases,
+		MaxSeverity: vg.group.MaxSeverity,
+	}
+}
+
+// compareVersions returns how a package changed from one version to another,
+// using the version ordering of its ecosystem
+func compareVersions(ecosystem, oldVersion, newVersion string) PackageChange {
+	v, err := semantic.Parse(oldVersion, models.Ecosystem(ecosystem))
+	if err != nil {
+		return PackageChanged
+	}
+
+	switch v.CompareStr(newVersion) {
+	case -1:
+		return PackageUpgraded
+	case 1:
+		return PackageDowngraded
+	default:
+		return PackageChanged
+	}
+}
+
+func diffPackageVersions(key packageKey, oldVersions, newVersions packageVersions) []PackageDiff {
+	var removed, added []string
+
+	for _, version := range oldVersions.sortedVersions() {
+		if _, ok := newVersions[version]; !ok {
+			removed = append(removed, version)
+		}
+	}
+
+	for _, version := range newVersions.sortedVersions() {
+		if _, ok := oldVersions[version]; !ok {
+			added = append(added, version)
+		}
+	}
+
+	// a single version being replaced by another is an upgrade or downgrade
+	if len(removed) == 1 && len(added) == 1 {
+		return []PackageDiff{{
+			Source:     key.source,
+			Ecosystem:  key.ecosystem,
+			Package:    key.name,
+			Change:     compareVersions(key.ecosystem, removed[0], added[0]),
+			OldVersion: removed[0],
+			NewVersion: added[0],
+		}}
+	}
+
+	diffs := make([]PackageDiff, 0, len(removed)+len(added))
+
+	for _, version := range removed {
+		diffs = append(diffs, PackageDiff{Source: key.source, Ecosystem: key.ecosystem, Package: key.name, Change: PackageRemoved, OldVersion: version})
+	}
+
+	for _, version := range added {
+		diffs = append(diffs, PackageDiff{Source: key.source, Ecosystem: key.ecosystem, Package: key.name, Change: PackageAdded, NewVersion: version})
+	}
+
+	return diffs
+}
+
+// CompareVulnerabilityResults returns the difference between the old and new
+// results, including vulnerabilities that were resolved or are unchanged.
+//
+// Packages that are not affected by any vulnerabilities are only included in
+// results when all packages are being reported, so package and license changes
+// are only complete if both sets of results were created that way.
+func CompareVulnerabilityResults(oldRes, newRes models.VulnerabilityResults) ResultsDiff {
+	oldPackages := indexPackages(oldRes)
+	newPackages := indexPackages(newRes)
+
+	keys := make([]packageKey, 0, len(oldPackages)+len(newPackages))
+	for key := range oldPackages {
+		keys = append(keys, key)
+	}
+	for key := range newPackages {
+		if _, ok := oldPackages[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+
+	slices.SortFunc(keys, func(a, b packageKey) int {
+		return cmp.Or(
+			strings.Compare(a.source, b.source),
+			strings.Compare(a.ecosystem, b.ecosystem),
+			strings.Compare(a.name, b.name),
+		)
+	})
+
+	diff := ResultsDiff{}
+
+	for _, key := range keys {
+		oldVersions, newVersions := oldPackages[key], newPackages[key]
+		oldGroups := oldVersions.groups()
+		matched := make([]bool, len(oldGroups))
+
+		for _, ng := range newVersions.groups() {
+			i := slices.IndexFunc(oldGroups, func(og versionedGroup) bool { return groupsOverlap(og.group, ng.group) })
+
+			if i == -1 {
+				diff.NewVulnerabilities = append(diff.NewVulnerabilities, newVulnerabilityDiff(key, ng))
+
+				continue
+			}
+
+			matched[i] = true
+			diff.UnchangedVulnerabilities = append(diff.UnchangedVulnerabilities, newVulnerabilityDiff(key, ng))
+		}
+
+		for i, og := range oldGroups {
+			if !matched[i] {
+				diff.ResolvedVulnerabilities = append(diff.ResolvedVulnerabilities, newVulnerabilityDiff(key, og))
+			}
+		}
+
+		diff.Packages = append(diff.Packages, diffPackageVersions(key, oldVersions, newVersions)...)
+
+		// licenses of packages that were added or removed are not changes to them
+		if len(oldVersions) > 0 && len(newVersions) > 0 {
+			oldLicenses, newLicenses := oldVersions.licenses(), newVersions.licenses()
+
+			if !slices.Equal(oldLicenses, newLicenses) {
+				diff.Licenses = append(diff.Licenses, LicenseDiff{
+					Source:      key.source,
+					Ecosystem:   key.ecosystem,
+					Package:     key.name,
+					OldLicenses: oldLicenses,
+					NewLicenses: newLicenses,
+				})
+			}
+		}
+	}
+
+	return diff
+}
@@ -0,0 +1,95 @@
+package ci_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/osv-scanner/internal/ci"
+	"github.com/google/osv-scanner/pkg/models"
+)
+
+func pkgVulns(name, version string, licenses []models.License, groups ...[]string) models.PackageVulns {
+	pkg := models.PackageVulns{
+		Package:  models.PackageInfo{Name: name, Version: version, Ecosystem: "npm"},
+		Licenses: licenses,
+	}
+
+	for _, ids := range groups {
+		pkg.Groups = append(pkg.Groups, models.GroupInfo{IDs: ids[:1], Aliases: ids, MaxSeverity: "7.5"})
+		pkg.Vulnerabilities = append(pkg.Vulnerabilities, models.Vulnerability{ID: ids[0], Aliases: ids[1:]})
+	}
+
+	return pkg
+}
+
+func TestCompareVulnerabilityResults(t *testing.T) {
+	t.Parallel()
+
+	source := models.SourceInfo{Path: "/app/package-lock.json", Type: "lockfile"}
+	mit := []models.License{"MIT"}
+
+	oldRes := models.VulnerabilityResults{
+		Results: []models.PackageSource{{
+			Source: source,
+			Packages: []models.PackageVulns{
+				pkgVulns("lodash", "4.17.20", mit, []string{"GHSA-lodash-1", "CVE-2021-23337"}, []string{"GHSA-lodash-2"}),
+				pkgVulns("minimist", "1.2.5", mit, []string{"GHSA-minimist"}),
+				pkgVulns("left-pad", "1.3.0", []models.License{"WTFPL"}),
+				pkgVulns("semver", "7.5.0", mit),
+			},
+		}},
+	}
+
+	newRes := models.VulnerabilityResults{
+		Results: []models.PackageSource{{
+			Source: source,
+			Packages: []models.PackageVulns{
+				// still affected by the first group, which now has a different ID
+				pkgVulns("lodash", "4.17.21", mit, []string{"OSV-lodash-1", "CVE-2021-23337"}),
+				pkgVulns("minimist", "1.2.5", mit, []string{"GHSA-minimist"}),
+				pkgVulns("express", "4.18.0", mit, []string{"GHSA-express"}),
+				pkgVulns("semver", "7.4.0", []models.License{"ISC"}),
+			},
+		}},
+	}
+
+	got := ci.CompareVulnerabilityResults(oldRes, newRes)
+
+	vulnDiff := func(name, version string, ids ...string) ci.VulnerabilityDiff {
+		return ci.VulnerabilityDiff{
+			Source:      source.Path,
+			Ecosystem:   "npm",
+			Package:     name,
+			Version:     version,
+			IDs:         ids[:1],
+			Aliases:     ids,
+			MaxSeverity: "7.5",
+		}
+	}
+
+	want := ci.ResultsDiff{
+		NewVulnerabilities: []ci.VulnerabilityDiff{
+			vulnDiff("express", "4.18.0", "GHSA-express"),
+		},
+		ResolvedVulnerabilities: []ci.VulnerabilityDiff{
+			vulnDiff("lodash", "4.17.20", "GHSA-lodash-2"),
+		},
+		UnchangedVulnerabilities: []ci.VulnerabilityDiff{
+			vulnDiff("lodash", "4.17.21", "OSV-lodash-1", "CVE-2021-23337"),
+			vulnDiff("minimist", "1.2.5", "GHSA-minimist"),
+		},
+		Packages: []ci.PackageDiff{
+			{Source: source.Path, Ecosystem: "npm", Package: "express", Change: ci.PackageAdded, NewVersion: "4.18.0"},
+			{Source: source.Path, Ecosystem: "npm", Package: "left-pad", Change: ci.PackageRemoved, OldVersion: "1.3.0"},
+			{Source: source.Path, Ecosystem: "npm", Package: "lodash", Change: ci.PackageUpgraded, OldVersion: "4.17.20", NewVersion: "4.17.21"},
+			{Source: source.Path, Ecosystem: "npm", Package: "semver", Change: ci.PackageDowngraded, OldVersion: "7.5.0", NewVersion: "7.4.0"},
+		},
+		Licenses: []ci.LicenseDiff{
+			{Source: source.Path, Ecosystem: "npm", Package: "semver", OldLicenses: mit, NewLicenses: []models.License{"ISC"}},
+		},
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("CompareVulnerabilityResults() mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,296 @@
+// Package gitfs provides a read-only fs.FS of the files in a git tree, so
+// that the files at a revision can be scanned without checking it out.
+package gitfs
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// FS is a filesystem of the files in a git tree, with the content of files
+// being read directly from the objects of the repository.
+//
+// Symlinks and submodules are treated as not existing, as they have no
+// content within the tree itself.
+type FS struct {
+	tree *object.Tree
+}
+
+var _ fs.ReadDirFS = FS{}
+
+// New returns a filesystem of the files in the given tree
+func New(tree *object.Tree) FS {
+	return FS{tree: tree}
+}
+
+// Repository is a git repository on the local filesystem that revisions are
+// read from
+type Repository struct {
+	repo *git.Repository
+	// Root is the absolute path of the root of the worktree of the repository
+	Root string
+}
+
+// OpenRepository opens the git repository that contains the given directory
+func OpenRepository(dir string) (*Repository, error) {
+	repo, err := git.PlainOpenWithOptions(dir, &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return nil, fmt.Errorf("could not open git repository for %s: %w", dir, err)
+	}
+
+	wt, err := repo.Worktree()
+	if err != nil {
+		return nil, fmt.Errorf("could not find worktree of git repository for %s: %w", dir, err)
+	}
+
+	root, err := filepath.Abs(wt.Filesystem.Root())
+	if err != nil {
+		return nil, err
+	}
+
+	return &Repository{repo: repo, Root: root}, nil
+}
+
+// Revision returns a filesystem of the files at the given revision, which
+// can be anything understood by git rev-parse such as "HEAD~1" or "main"
+func (r *Repository) Revision(rev string) (FS, error) {
+	hash, err := r.repo.ResolveRevision(plumbing.Revision(rev))
+	if err != nil {
+		return FS{}, fmt.Errorf("could not resolve git revision %s: %w", rev, err)
+	}
+
+	commit, err := r.repo.CommitObject(*hash)
+	if err != nil {
+		return FS{}, fmt.Errorf("could not read commit of git revision %s: %w", rev, err)
+	}
+
+	tree, err := commit.Tree()
+	if err != nil {
+		return FS{}, fmt.Errorf("could not read tree of git revision %s: %w", rev, err)
+	}
+
+	return New(tree), nil
+}
+
+// Rel returns the path of the given local path relative to the root of the
+// repository, using forward slashes like the paths within the tree
+func (r *Repository) Rel(p string) (string, error) {
+	abs, err := filepath.Abs(p)
+	if err != nil {
+		return "", err
+	}
+
+	rel, err := filepath.Rel(r.Root, abs)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%s is not within the git repository at %s", p, r.Root)
+	}
+
+	return filepath.ToSlash(rel), nil
+}
+
+func isFile(mode filemode.FileMode) bool {
+	return mode.IsFile() && mode != filemode.Symlink
+}
+
+// subtree returns the tree of the directory with the given name
+func (fsys FS) subtree(op, name string) (*object.Tree, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+
+	if name == "." {
+		return fsys.tree, nil
+	}
+
+	tree, err := fsys.tree.Tree(name)
+	if err != nil {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+	}
+
+	return tree, nil
+}
+
+func (fsys FS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+
+	if name == "." {
+		return &dir{fsys: fsys, info: dirInfo(".")}, nil
+	}
+
+	entry, err := fsys.tree.FindEntry(name)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+
+	if entry.Mode == filemode.Dir {
+		return &dir{fsys: fsys, name: name, info: dirInfo(entry.Name)}, nil
+	}
+
+	if !isFile(entry.Mode) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+
+	f, err := fsys.tree.TreeEntryFile(entry)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+
+	rc, err := f.Reader()
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+
+	return &file{ReadCloser: rc, info: fileInfo{name: entry.Name, size: f.Size, mode: entry.Mode}}, nil
+}
+
+func (fsys FS) ReadDir(name string) ([]fs.DirEntry, error) {
+	tree, err := fsys.subtree("readdir", name)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]fs.DirEntry, 0, len(tree.Entries))
+
+	for i := range tree.Entries {
+		entry := &tree.Entries[i]
+
+		switch {
+		case entry.Mode == filemode.Dir:
+			entries = append(entries, dirInfo(entry.Name))
+		case isFile(entry.Mode):
+			entries = append(entries, &fileEntry{tree: tree, entry: entry})
+		}
+	}
+
+	// git sorts directories as if they had a trailing slash, unlike fs.FS
+	slices.SortFunc(entries, func(a, b fs.DirEntry) int {
+		return strings.Compare(a.Name(), b.Name())
+	})
+
+	return entries, nil
+}
+
+// fileInfo describes a file or directory within the tree, which have no
+// modification times as git does not track them
+type fileInfo struct {
+	name string
+	size int64
+	mode filemode.FileMode
+}
+
+func dirInfo(name string) fileInfo {
+	return fileInfo{name: path.Base(name), mode: filemode.Dir}
+}
+
+func (fi fileInfo) Name() string       { return fi.name }
+func (fi fileInfo) Size() int64        { return fi.size }
+func (fi fileInfo) ModTime() time.Time { return time.Time{} }
+func (fi fileInfo) IsDir() bool        { return fi.mode == filemode.Dir }
+func (fi fileInfo) Sys() any           { return nil }
+
+func (fi fileInfo) Mode() fs.FileMode {
+	switch {
+	case fi.IsDir():
+		return fs.ModeDir | 0555
+	case fi.mode == filemode.Executable:
+		return 0555
+	default:
+		return 0444
+	}
+}
+
+func (fi fileInfo) Type() fs.FileMode          { return fi.Mode().Type() }
+func (fi fileInfo) Info() (fs.FileInfo, error) { return fi, nil }
+
+// fileEntry is the DirEntry of a file, which only reads the size of the file
+// from the repository if its info is actually needed
+type fileEntry struct {
+	tree  *object.Tree
+	entry *object.TreeEntry
+}
+
+func (e *fileEntry) Name() string      { return e.entry.Name }
+func (e *fileEntry) IsDir() bool       { return false }
+func (e *fileEntry) Type() fs.FileMode { return 0 }
+
+func (e *fileEntry) Info() (fs.FileInfo, error) {
+	f, err := e.tree.TreeEntryFile(e.entry)
+	if err != nil {
+		return nil, err
+	}
+
+	return fileInfo{name: e.entry.Name, size: f.Size, mode: e.entry.Mode}, nil
+}
+
+type file struct {
+	io.ReadCloser
+
+	info fileInfo
+}
+
+func (f *file) Stat() (fs.FileInfo, error) { return f.info, nil }
+
+type dir struct {
+	fsys FS
+	name string
+	info fileInfo
+
+	entries []fs.DirEntry
+	offset  int
+}
+
+var _ fs.ReadDirFile = &dir{}
+
+func (d *dir) Stat() (fs.FileInfo, error) { return d.info, nil }
+func (d *dir) Close() error               { return nil }
+
+func (d *dir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.name, Err: errors.New("is a directory")}
+}
+
+func (d *dir) ReadDir(n int) ([]fs.DirEntry, error) {
+	if d.entries == nil {
+		name := d.name
+		if name == "" {
+			name = "."
+		}
+
+		entries, err := d.fsys.ReadDir(name)
+		if err != nil {
+			return nil, err
+		}
+
+		d.entries = entries
+	}
+
+	remaining := d.entries[d.offset:]
+
+	if n <= 0 {
+		d.offset = len(d.entries)
+
+		return remaining, nil
+	}
+
+	if len(remaining) == 0 {
+		return nil, io.EOF
+	}
+
+	n = min(n, len(remaining))
+	d.offset += n
+
+	return remaining[:n], nil
+}
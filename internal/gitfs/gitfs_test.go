@@ -0,0 +1,126 @@
+package gitfs_test
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/google/osv-scanner/internal/gitfs"
+)
+
+// commit writes the given files to the worktree of the repository and
+// commits them, with files that have no content being removed
+func commit(t *testing.T, repo *git.Repository, dir string, files map[string]string) {
+	t.Helper()
+
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("could not get worktree: %v", err)
+	}
+
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+
+		if content == "" {
+			if _, err := wt.Remove(name); err != nil {
+				t.Fatalf("could not remove %s: %v", name, err)
+			}
+
+			continue
+		}
+
+		if err := os.MkdirAll(filepath.Dir(p), 0750); err != nil {
+			t.Fatalf("could not create directory for %s: %v", name, err)
+		}
+
+		if err := os.WriteFile(p, []byte(content), 0600); err != nil {
+			t.Fatalf("could not write %s: %v", name, err)
+		}
+
+		if _, err := wt.Add(name); err != nil {
+			t.Fatalf("could not add %s: %v", name, err)
+		}
+	}
+
+	_, err = wt.Commit("commit", &git.CommitOptions{
+		Author: &object.Signature{Name: "osv-scanner", Email: "osv-scanner@example.com", When: time.Now()},
+	})
+	if err != nil {
+		t.Fatalf("could not commit: %v", err)
+	}
+}
+
+func TestRepository_Revision(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatalf("could not create repository: %v", err)
+	}
+
+	commit(t, repo, dir, map[string]string{
+		"package-lock.json":      "v1",
+		"services/api/go.mod":    "module api",
+		"services/api/go.sum":    "sums",
+		"services/worker.lock":   "worker",
+		"services/api.v2/go.mod": "module api/v2",
+	})
+	commit(t, repo, dir, map[string]string{
+		"package-lock.json":    "v2",
+		"services/worker.lock": "",
+	})
+
+	r, err := gitfs.OpenRepository(filepath.Join(dir, "services"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if rel, err := r.Rel(filepath.Join(dir, "services", "api")); err != nil || rel != "services/api" {
+		t.Errorf("Rel() = %q, %v, want %q", rel, err, "services/api")
+	}
+
+	if _, err := r.Rel(filepath.Dir(dir)); err == nil {
+		t.Errorf("expected paths outside of the repository to be an error")
+	}
+
+	head, err := r.Revision("HEAD")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := fstest.TestFS(head, "package-lock.json", "services/api/go.mod", "services/api/go.sum", "services/api.v2/go.mod"); err != nil {
+		t.Errorf("unexpected error testing filesystem: %v", err)
+	}
+
+	if b, _ := fs.ReadFile(head, "package-lock.json"); string(b) != "v2" {
+		t.Errorf("expected package-lock.json to be %q at HEAD, got %q", "v2", b)
+	}
+
+	if _, err := fs.Stat(head, "services/worker.lock"); err == nil {
+		t.Errorf("expected services/worker.lock to not exist at HEAD")
+	}
+
+	previous, err := r.Revision("HEAD~1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if b, _ := fs.ReadFile(previous, "package-lock.json"); string(b) != "v1" {
+		t.Errorf("expected package-lock.json to be %q at HEAD~1, got %q", "v1", b)
+	}
+
+	if b, _ := fs.ReadFile(previous, "services/worker.lock"); string(b) != "worker" {
+		t.Errorf("expected services/worker.lock to be %q at HEAD~1, got %q", "worker", b)
+	}
+
+	if _, err := r.Revision("does-not-exist"); err == nil {
+		t.Errorf("expected resolving an unknown revision to be an error")
+	}
+}
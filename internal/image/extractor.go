@@ -3,8 +3,6 @@ package image
 import (
 	"errors"
 	"fmt"
-	"os"
-	"path"
 	"sort"
 
 	"github.com/google/osv-scanner/pkg/lockfile"
@@ -46,7 +44,7 @@ func extractArtifactDeps(path string, img *Image) (lockfile.Lockfile, error) {
 	var extractedAs string
 	for _, extPair := range foundExtractors {
 		// File has to be reopened per extractor as each extractor moves the read cursor
-		f, err := OpenLayerFile(path, img.LastLayer())
+		f, err := lockfile.OpenFSDepFile(img.LastLayer(), path)
 		if err != nil {
 			return lockfile.Lockfile{}, fmt.Errorf("attempted to open file but failed: %w", err)
 		}
@@ -88,43 +86,3 @@ func extractArtifactDeps(path string, img *Image) (lockfile.Lockfile, error) {
 		Packages: packages,
 	}, nil
 }
-
-// A ImageFile represents a file that exists in an image
-type ImageFile struct {
-	*os.File
-
-	layer fileMap
-	path  string
-}
-
-func (f ImageFile) Open(openPath string) (lockfile.NestedDepFile, error) {
-	// use path instead of filepath, because container is always in Unix paths (for now)
-	if path.IsAbs(openPath) {
-		return OpenLayerFile(openPath, f.layer)
-	}
-
-	absPath := path.Join(f.path, openPath)
-
-	return OpenLayerFile(absPath, f.layer)
-}
-
-func (f ImageFile) Path() string {
-	return f.path
-}
-
-func OpenLayerFile(path string, layer fileMap) (ImageFile, error) {
-	readCloser, err := layer.OpenFile(path)
-
-	if err != nil {
-		return ImageFile{}, err
-	}
-
-	return ImageFile{
-		File:  readCloser,
-		path:  path,
-		layer: layer,
-	}, nil
-}
-
-var _ lockfile.DepFile = ImageFile{}
-var _ lockfile.NestedDepFile = ImageFile{}
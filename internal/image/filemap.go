@@ -3,6 +3,8 @@ package image
 import (
 	"io/fs"
 	"os"
+	"path"
+	"sort"
 
 	"github.com/dghubble/trie"
 )
@@ -29,15 +31,69 @@ type fileMap struct {
 	// TODO: Use hashset to speed up path lookups
 }
 
-func (filemap fileMap) OpenFile(path string) (*os.File, error) {
-	node, ok := filemap.fileNodeTrie.Get(path).(fileNode)
-	if !ok {
-		return nil, fs.ErrNotExist
+var _ fs.ReadDirFS = fileMap{}
+
+// Open opens the file with the given name on the layer, which like all
+// fs.FS names is relative to the root of the image
+func (filemap fileMap) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+
+	node, ok := filemap.fileNodeTrie.Get(virtualPath(name)).(fileNode)
+	if !ok || node.isWhiteout {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
 	}
 
 	return os.Open(node.absoluteDiskPath)
 }
 
+// ReadDir lists the files within the directory with the given name on the
+// layer, which can be spread across the directories of multiple layers on disk
+func (filemap fileMap) ReadDir(name string) ([]fs.DirEntry, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrInvalid}
+	}
+
+	dir := virtualPath(name)
+
+	if node, ok := filemap.fileNodeTrie.Get(dir).(fileNode); dir != "/" && (!ok || node.fileType != Dir || node.isWhiteout) {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+
+	var entries []fs.DirEntry
+
+	// No need to check error since we are not returning any errors
+	_ = filemap.fileNodeTrie.Walk(func(key string, value interface{}) error {
+		node := value.(fileNode)
+		if node.isWhiteout || key == dir || path.Dir(key) != dir {
+			return nil
+		}
+
+		info, err := os.Stat(node.absoluteDiskPath)
+		if err == nil {
+			entries = append(entries, fs.FileInfoToDirEntry(info))
+		}
+
+		return nil
+	})
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name() < entries[j].Name()
+	})
+
+	return entries, nil
+}
+
+// virtualPath converts a fs.FS name into the path used for the layer
+func virtualPath(name string) string {
+	if name == "." {
+		return "/"
+	}
+
+	return "/" + name
+}
+
 // AllFiles return all files that exist on the layer the FileMap is representing
 func (filemap fileMap) AllFiles() []fileNode {
 	allFiles := []fileNode{}
@@ -1,6 +1,7 @@
 package image
 
 import (
+	"context"
 	"errors"
 	"fmt"
 
@@ -9,7 +10,7 @@ import (
 )
 
 // ScanImage scans an exported docker image .tar file
-func ScanImage(r reporter.Reporter, imagePath string) (ScanResults, error) {
+func ScanImage(ctx context.Context, r reporter.Reporter, imagePath string) (ScanResults, error) {
 	img, err := loadImage(imagePath)
 	if err != nil {
 		// Ignore errors on cleanup since the folder might not have been created anyway.
@@ -23,6 +24,12 @@ func ScanImage(r reporter.Reporter, imagePath string) (ScanResults, error) {
 		ImagePath: imagePath,
 	}
 	for _, file := range allFiles {
+		if ctx.Err() != nil {
+			// Ignore errors on cleanup since we're already returning an error
+			_ = img.Cleanup()
+			return ScanResults{}, ctx.Err()
+		}
+
 		if file.fileType != RegularFile {
 			continue
 		}
@@ -0,0 +1,120 @@
+package image_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"io"
+	"path"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"github.com/google/osv-scanner/internal/image"
+	"github.com/google/osv-scanner/pkg/reporter"
+)
+
+const nodeModulesLockfile = `{
+  "name": "app",
+  "lockfileVersion": 3,
+  "requires": true,
+  "packages": {
+    "node_modules/wrappy": {
+      "version": "1.0.2"
+    }
+  }
+}
+`
+
+func makeLayer(t *testing.T, files map[string]string) v1.Layer {
+	t.Helper()
+
+	buf := &bytes.Buffer{}
+	tw := tar.NewWriter(buf)
+
+	written := make(map[string]bool)
+
+	for name, content := range files {
+		// like real images, directories have their own entries before their files
+		var dirs []string
+		for dir := path.Dir(name); dir != "." && !written[dir]; dir = path.Dir(dir) {
+			written[dir] = true
+			dirs = append([]string{dir}, dirs...)
+		}
+
+		for _, dir := range dirs {
+			if err := tw.WriteHeader(&tar.Header{Name: dir + "/", Mode: 0700, Typeflag: tar.TypeDir}); err != nil {
+				t.Fatalf("could not write tar header: %v", err)
+			}
+		}
+
+		header := &tar.Header{Name: name, Mode: 0600, Size: int64(len(content)), Typeflag: tar.TypeReg}
+
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatalf("could not write tar header: %v", err)
+		}
+
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatalf("could not write tar entry: %v", err)
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		t.Fatalf("could not write tar: %v", err)
+	}
+
+	layer, err := tarball.LayerFromOpener(func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(buf.Bytes())), nil
+	})
+	if err != nil {
+		t.Fatalf("could not create layer: %v", err)
+	}
+
+	return layer
+}
+
+func TestScanImage(t *testing.T) {
+	t.Parallel()
+
+	img, err := mutate.AppendLayers(
+		empty.Image,
+		makeLayer(t, map[string]string{
+			"app/node_modules/.package-lock.json":     nodeModulesLockfile,
+			"removed/node_modules/.package-lock.json": nodeModulesLockfile,
+		}),
+		makeLayer(t, map[string]string{
+			"removed/node_modules/.wh..package-lock.json": "",
+		}),
+	)
+	if err != nil {
+		t.Fatalf("could not create image: %v", err)
+	}
+
+	imagePath := filepath.Join(t.TempDir(), "image.tar")
+	if err := tarball.WriteToFile(imagePath, name.MustParseReference("osv-scanner/test:latest"), img); err != nil {
+		t.Fatalf("could not write image: %v", err)
+	}
+
+	results, err := image.ScanImage(context.Background(), &reporter.VoidReporter{}, imagePath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(results.Lockfiles) != 1 {
+		t.Fatalf("expected 1 lockfile, got %d", len(results.Lockfiles))
+	}
+
+	lockfile := results.Lockfiles[0]
+
+	if lockfile.FilePath != "/app/node_modules/.package-lock.json" {
+		t.Errorf("expected lockfile to be from /app/node_modules/.package-lock.json, got %s", lockfile.FilePath)
+	}
+
+	if len(lockfile.Packages) != 1 || lockfile.Packages[0].Name != "wrappy" {
+		t.Errorf("expected lockfile to have the wrappy package, got %v", lockfile.Packages)
+	}
+}
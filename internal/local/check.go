@@ -1,11 +1,15 @@
 package local
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"net/http"
 	"os"
 	"path"
+	"path/filepath"
 
+	"github.com/google/osv-scanner/internal/utility/vulns"
 	"github.com/google/osv-scanner/pkg/lockfile"
 	"github.com/google/osv-scanner/pkg/models"
 	"github.com/google/osv-scanner/pkg/osv"
@@ -15,8 +19,21 @@ import (
 const zippedDBRemoteHost = "https://osv-vulnerabilities.storage.googleapis.com"
 const envKeyLocalDBCacheDirectory = "OSV_SCANNER_LOCAL_DB_CACHE_DIRECTORY"
 
-func loadDB(dbBasePath string, ecosystem lockfile.Ecosystem, offline bool) (*ZipDB, error) {
-	return NewZippedDB(dbBasePath, string(ecosystem), fmt.Sprintf("%s/%s/all.zip", zippedDBRemoteHost, ecosystem), offline)
+// Options configures how the local databases are loaded
+type Options struct {
+	// Offline prevents the databases from being downloaded,
+	// meaning only those that are already on disk can be used
+	Offline bool
+	// LocalDBPath is where the databases are stored, see setupLocalDBDirectory
+	LocalDBPath string
+	// HTTPClient is used to download the databases, defaulting to http.DefaultClient
+	HTTPClient *http.Client
+	// UserAgent is sent when downloading the databases, defaulting to osv.RequestUserAgent
+	UserAgent string
+}
+
+func loadDB(ctx context.Context, dbBasePath string, ecosystem lockfile.Ecosystem, opts Options) (*ZipDB, error) {
+	return newZippedDB(ctx, dbBasePath, string(ecosystem), fmt.Sprintf("%s/%s/all.zip", zippedDBRemoteHost, ecosystem), opts)
 }
 
 func toPackageDetails(query *osv.Query) (lockfile.PackageDetails, error) {
@@ -89,11 +106,11 @@ func setupLocalDBDirectory(localDBPath string) (string, error) {
 	return "", err
 }
 
-func MakeRequest(r reporter.Reporter, query osv.BatchedQuery, offline bool, localDBPath string) (*osv.HydratedBatchedResponse, error) {
+func MakeRequest(ctx context.Context, r reporter.Reporter, query osv.BatchedQuery, opts Options) (*osv.HydratedBatchedResponse, error) {
 	results := make([]osv.Response, 0, len(query.Queries))
 	dbs := make(map[lockfile.Ecosystem]*ZipDB)
 
-	dbBasePath, err := setupLocalDBDirectory(localDBPath)
+	dbBasePath, err := setupLocalDBDirectory(opts.LocalDBPath)
 
 	if err != nil {
 		return &osv.HydratedBatchedResponse{}, fmt.Errorf("could not create %s: %w", dbBasePath, err)
@@ -104,7 +121,7 @@ func MakeRequest(r reporter.Reporter, query osv.BatchedQuery, offline bool, loca
 			return db, nil
 		}
 
-		db, err := loadDB(dbBasePath, ecosystem, offline)
+		db, err := loadDB(ctx, dbBasePath, ecosystem, opts)
 
 		if err != nil {
 			return nil, err
@@ -117,7 +134,79 @@ func MakeRequest(r reporter.Reporter, query osv.BatchedQuery, offline bool, loca
 		return db, nil
 	}
 
+	var commitDBs []*ZipDB
+	commitDBsLoaded := false
+
+	loadCommitDBs := func() []*ZipDB {
+		if commitDBsLoaded {
+			return commitDBs
+		}
+		commitDBsLoaded = true
+
+		for _, ecosystem := range commitEcosystems {
+			db, err := loadDBFromCache(lockfile.Ecosystem(ecosystem))
+
+			if err != nil {
+				r.Warnf("could not load db for %s ecosystem, commits will not be checked against it: %v\n", ecosystem, err)
+
+				continue
+			}
+
+			commitDBs = append(commitDBs, db)
+		}
+
+		return commitDBs
+	}
+
+	repos := make(map[string]*gitRepository)
+
+	openRepoFromCache := func(source models.SourceInfo) *gitRepository {
+		repoPath := source.Path
+		if source.ScanPath != "" && !filepath.IsAbs(repoPath) {
+			repoPath = filepath.Join(source.ScanPath, repoPath)
+		}
+
+		if repo, ok := repos[repoPath]; ok {
+			return repo
+		}
+
+		repo, err := openGitRepository(repoPath)
+
+		if err != nil {
+			r.Verbosef("Could not open git repository at %s, only exact commit matches will be found: %v\n", repoPath, err)
+			repo = nil
+		}
+
+		repos[repoPath] = repo
+
+		return repo
+	}
+
+	matchCommit := func(commit string, source models.SourceInfo) models.Vulnerabilities {
+		var repo *gitRepository
+
+		if source.Type == "git" {
+			repo = openRepoFromCache(source)
+		}
+
+		vulnerabilities := models.Vulnerabilities{}
+
+		for _, db := range loadCommitDBs() {
+			for _, vulnerability := range db.vulnerabilitiesAffectingCommit(repo, commit) {
+				if !vulns.Include(vulnerabilities, vulnerability) {
+					vulnerabilities = append(vulnerabilities, vulnerability)
+				}
+			}
+		}
+
+		return vulnerabilities
+	}
+
 	for _, query := range query.Queries {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
 		pkg, err := toPackageDetails(query)
 
 		if err != nil {
@@ -134,9 +223,7 @@ func MakeRequest(r reporter.Reporter, query osv.BatchedQuery, offline bool, loca
 				return nil, errors.New("ecosystem is empty and there is no commit hash")
 			}
 
-			// Is a commit based query, skip local scanning
-			results = append(results, osv.Response{})
-			r.Infof("Skipping commit scanning for: %s\n", pkg.Commit)
+			results = append(results, osv.Response{Vulns: matchCommit(pkg.Commit, query.Source)})
 
 			continue
 		}
@@ -0,0 +1,297 @@
+package local
+
+import (
+	"errors"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/google/osv-scanner/internal/utility/vulns"
+	"github.com/google/osv-scanner/pkg/models"
+)
+
+// commitEcosystems are the databases that are checked for GIT ranges
+// when matching commit queries, as commits do not have an ecosystem of their own
+var commitEcosystems = []string{"GIT", "OSS-Fuzz"}
+
+// gitRepository represents a local git repository that commit queries
+// can be matched against without making any network requests
+type gitRepository struct {
+	repo *git.Repository
+	// the normalized urls of the remotes of the repository
+	remotes []string
+	// the commits that are reachable from a given commit (including itself)
+	ancestors map[string]map[string]struct{}
+}
+
+func openGitRepository(repoPath string) (*gitRepository, error) {
+	repo, err := git.PlainOpenWithOptions(repoPath, &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return nil, err
+	}
+
+	remotes, err := repo.Remotes()
+	if err != nil {
+		return nil, err
+	}
+
+	var urls []string
+	for _, remote := range remotes {
+		for _, u := range remote.Config().URLs {
+			urls = append(urls, normalizeRepoURL(u))
+		}
+	}
+
+	return &gitRepository{
+		repo:      repo,
+		remotes:   urls,
+		ancestors: make(map[string]map[string]struct{}),
+	}, nil
+}
+
+// normalizeRepoURL strips the parts of a repository url that do not identify
+// the repository, so that "git@github.com:org/repo.git" and
+// "https://github.com/org/repo" are considered the same
+func normalizeRepoURL(repoURL string) string {
+	repoURL = strings.TrimSpace(repoURL)
+
+	// scp-like syntax, i.e. "git@github.com:org/repo.git"
+	if !strings.Contains(repoURL, "://") {
+		if user, rest, found := strings.Cut(repoURL, "@"); found && !strings.Contains(user, "/") {
+			repoURL = rest
+		}
+		repoURL = strings.Replace(repoURL, ":", "/", 1)
+	} else if u, err := url.Parse(repoURL); err == nil {
+		repoURL = u.Host + u.Path
+	}
+
+	repoURL = strings.TrimSuffix(repoURL, "/")
+	repoURL = strings.TrimSuffix(repoURL, ".git")
+
+	return strings.ToLower(repoURL)
+}
+
+func (r *gitRepository) hasRemote(repoURL string) bool {
+	normalized := normalizeRepoURL(repoURL)
+
+	for _, remote := range r.remotes {
+		if remote == normalized {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r *gitRepository) hasCommit(hash string) bool {
+	_, err := r.repo.CommitObject(plumbing.NewHash(hash))
+
+	return err == nil
+}
+
+// ancestorsOf returns the set of commits reachable from the given commit,
+// including the commit itself
+func (r *gitRepository) ancestorsOf(hash string) (map[string]struct{}, error) {
+	if ancestors, ok := r.ancestors[hash]; ok {
+		return ancestors, nil
+	}
+
+	commit, err := r.repo.CommitObject(plumbing.NewHash(hash))
+	if err != nil {
+		return nil, err
+	}
+
+	ancestors := make(map[string]struct{})
+	err = object.NewCommitPreorderIter(commit, nil, nil).ForEach(func(c *object.Commit) error {
+		ancestors[c.Hash.String()] = struct{}{}
+
+		return nil
+	})
+
+	// shallow clones will be missing the parents of their oldest commits,
+	// in which case we go with what history we do have
+	if err != nil && !errors.Is(err, plumbing.ErrObjectNotFound) {
+		return nil, err
+	}
+
+	r.ancestors[hash] = ancestors
+
+	return ancestors, nil
+}
+
+// isRelevantRange checks if the given range describes commits in this repository,
+// either because it is for one of the remotes or because its events reference
+// commits that exist locally
+func (r *gitRepository) isRelevantRange(ar models.Range) bool {
+	if ar.Repo != "" && r.hasRemote(ar.Repo) {
+		return true
+	}
+
+	for _, e := range ar.Events {
+		for _, hash := range []string{e.Introduced, e.Fixed, e.LastAffected, e.Limit} {
+			if hash != "" && hash != "0" && r.hasCommit(hash) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// rangeContainsCommit checks if the given commit is affected by the range,
+// which it is if it descends from an introduced commit without also descending
+// from a fixed or last affected commit that itself descends from that
+// introduced commit. This does not depend on the order of the events, and
+// allows vulnerabilities to be reintroduced after being fixed.
+//
+// ancestorsOf returns the commits reachable from the given commit (including
+// itself), or nil if they are not known, in which case events are assumed to
+// descend from every introduced commit
+func rangeContainsCommit(ar models.Range, commit string, ancestorsOf func(hash string) map[string]struct{}) bool {
+	descendsFrom := func(hash string, ancestor string) bool {
+		if ancestor == "0" || strings.EqualFold(hash, ancestor) {
+			return true
+		}
+
+		ancestors := ancestorsOf(hash)
+		if ancestors == nil {
+			return true
+		}
+
+		_, ok := ancestors[strings.ToLower(ancestor)]
+
+		return ok
+	}
+
+	for _, introduced := range ar.Events {
+		if introduced.Introduced == "" || !descendsFrom(commit, introduced.Introduced) {
+			continue
+		}
+
+		closed := slices.ContainsFunc(ar.Events, func(e models.Event) bool {
+			switch {
+			case e.Fixed != "":
+				return descendsFrom(commit, e.Fixed) && descendsFrom(e.Fixed, introduced.Introduced)
+			case e.LastAffected != "":
+				// the last affected commit is itself affected, but anything after it is not
+				return !strings.EqualFold(e.LastAffected, commit) &&
+					descendsFrom(commit, e.LastAffected) &&
+					descendsFrom(e.LastAffected, introduced.Introduced)
+			default:
+				return false
+			}
+		})
+
+		if !closed {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isCommitAffected checks if the given commit of the repository, whose
+// ancestors are given, is affected by any of the GIT ranges of the vulnerability
+func (r *gitRepository) isCommitAffected(v models.Vulnerability, commit string, ancestors map[string]struct{}) bool {
+	for _, affected := range v.Affected {
+		for _, ar := range affected.Ranges {
+			if ar.Type != models.RangeGit || !r.isRelevantRange(ar) {
+				continue
+			}
+
+			if rangeContainsCommit(ar, commit, func(hash string) map[string]struct{} {
+				if strings.EqualFold(hash, commit) {
+					return ancestors
+				}
+
+				// the ancestors of events that are not in the repository are unknown
+				hashAncestors, err := r.ancestorsOf(strings.ToLower(hash))
+				if err != nil {
+					return nil
+				}
+
+				return hashAncestors
+			}) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// isCommitAffectedWithoutHistory is used when the repository the commit is from
+// is not available locally, and so only commits that are explicitly named in
+// a GIT range can be matched
+func isCommitAffectedWithoutHistory(v models.Vulnerability, commit string) bool {
+	for _, affected := range v.Affected {
+		for _, ar := range affected.Ranges {
+			if ar.Type != models.RangeGit {
+				continue
+			}
+
+			withoutHistory := func(hash string) map[string]struct{} {
+				if strings.EqualFold(hash, commit) {
+					return map[string]struct{}{}
+				}
+
+				return nil
+			}
+
+			if rangeContainsCommit(ar, commit, withoutHistory) {
+				// an introduced value of "0" would match any commit, which is only
+				// meaningful if we know the commit is from the same repository
+				for _, e := range ar.Events {
+					if e.Introduced != "" && strings.EqualFold(e.Introduced, commit) {
+						return true
+					}
+				}
+			}
+
+			for _, e := range ar.Events {
+				if e.LastAffected != "" && strings.EqualFold(e.LastAffected, commit) {
+					return true
+				}
+			}
+		}
+	}
+
+	return false
+}
+
+// vulnerabilitiesAffectingCommit returns the vulnerabilities in the database
+// with GIT ranges that affect the given commit, using the history of repo
+// if it is available
+func (db *ZipDB) vulnerabilitiesAffectingCommit(repo *gitRepository, commit string) models.Vulnerabilities {
+	var vulnerabilities models.Vulnerabilities
+	var ancestors map[string]struct{}
+
+	if repo != nil {
+		var err error
+
+		// the commit might not be in the repository, such as if it is from a
+		// submodule or is older than the history of a shallow clone, in which
+		// case it can only be matched like it would be without the repository
+		if ancestors, err = repo.ancestorsOf(commit); err != nil {
+			repo = nil
+		}
+	}
+
+	for _, vulnerability := range db.Vulnerabilities(false) {
+		var affected bool
+		if repo == nil {
+			affected = isCommitAffectedWithoutHistory(vulnerability, commit)
+		} else {
+			affected = repo.isCommitAffected(vulnerability, commit, ancestors)
+		}
+
+		if affected && !vulns.Include(vulnerabilities, vulnerability) {
+			vulnerabilities = append(vulnerabilities, vulnerability)
+		}
+	}
+
+	return vulnerabilities
+}
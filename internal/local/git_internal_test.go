@@ -0,0 +1,281 @@
+package local
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/google/osv-scanner/pkg/models"
+)
+
+func TestNormalizeRepoURL(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{url: "https://github.com/google/osv-scanner", want: "github.com/google/osv-scanner"},
+		{url: "https://github.com/google/osv-scanner.git", want: "github.com/google/osv-scanner"},
+		{url: "https://github.com/Google/OSV-Scanner/", want: "github.com/google/osv-scanner"},
+		{url: "git@github.com:google/osv-scanner.git", want: "github.com/google/osv-scanner"},
+		{url: "ssh://git@github.com/google/osv-scanner", want: "github.com/google/osv-scanner"},
+	}
+
+	for _, tt := range tests {
+		if got := normalizeRepoURL(tt.url); got != tt.want {
+			t.Errorf("normalizeRepoURL(%q) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
+
+// commitHistory creates a repository with a linear history of n commits,
+// returning the hashes of the commits from oldest to newest
+func commitHistory(t *testing.T, n int) (string, []string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatalf("could not init repository: %v", err)
+	}
+
+	_, err = repo.CreateRemote(&config.RemoteConfig{
+		Name: "origin",
+		URLs: []string{"git@github.com:example/project.git"},
+	})
+	if err != nil {
+		t.Fatalf("could not create remote: %v", err)
+	}
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("could not get worktree: %v", err)
+	}
+
+	hashes := make([]string, 0, n)
+	for i := range n {
+		hash, err := worktree.Commit("commit", &git.CommitOptions{
+			AllowEmptyCommits: true,
+			Author: &object.Signature{
+				Name:  "osv-scanner",
+				Email: "osv-scanner@example.com",
+				When:  time.Date(2024, 1, i+1, 0, 0, 0, 0, time.UTC),
+			},
+		})
+		if err != nil {
+			t.Fatalf("could not commit: %v", err)
+		}
+		hashes = append(hashes, hash.String())
+	}
+
+	return dir, hashes
+}
+
+func gitVuln(id string, repo string, events ...models.Event) models.Vulnerability {
+	return models.Vulnerability{
+		ID: id,
+		Affected: []models.Affected{
+			{Ranges: []models.Range{{Type: models.RangeGit, Repo: repo, Events: events}}},
+		},
+	}
+}
+
+func TestGitRepository_IsCommitAffected(t *testing.T) {
+	t.Parallel()
+
+	dir, hashes := commitHistory(t, 5)
+
+	repo, err := openGitRepository(dir)
+	if err != nil {
+		t.Fatalf("could not open repository: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		vuln   models.Vulnerability
+		commit string
+		want   bool
+	}{
+		{
+			name:   "introduced in history, not fixed",
+			vuln:   gitVuln("OSV-1", "", models.Event{Introduced: hashes[1]}),
+			commit: hashes[3],
+			want:   true,
+		},
+		{
+			name:   "introduced after the commit",
+			vuln:   gitVuln("OSV-2", "", models.Event{Introduced: hashes[3]}),
+			commit: hashes[1],
+			want:   false,
+		},
+		{
+			name:   "fixed in history",
+			vuln:   gitVuln("OSV-3", "", models.Event{Introduced: hashes[0]}, models.Event{Fixed: hashes[2]}),
+			commit: hashes[3],
+			want:   false,
+		},
+		{
+			name:   "fixed after the commit",
+			vuln:   gitVuln("OSV-4", "", models.Event{Introduced: hashes[0]}, models.Event{Fixed: hashes[4]}),
+			commit: hashes[3],
+			want:   true,
+		},
+		{
+			name:   "commit is the last affected",
+			vuln:   gitVuln("OSV-5", "", models.Event{Introduced: hashes[0]}, models.Event{LastAffected: hashes[3]}),
+			commit: hashes[3],
+			want:   true,
+		},
+		{
+			name:   "commit is after the last affected",
+			vuln:   gitVuln("OSV-6", "", models.Event{Introduced: hashes[0]}, models.Event{LastAffected: hashes[2]}),
+			commit: hashes[3],
+			want:   false,
+		},
+		{
+			name:   "reintroduced after being fixed",
+			vuln:   gitVuln("OSV-9", "", models.Event{Introduced: hashes[0]}, models.Event{Fixed: hashes[1]}, models.Event{Introduced: hashes[3]}),
+			commit: hashes[4],
+			want:   true,
+		},
+		{
+			name:   "fixed before being reintroduced",
+			vuln:   gitVuln("OSV-10", "", models.Event{Introduced: hashes[0]}, models.Event{Fixed: hashes[1]}, models.Event{Introduced: hashes[3]}),
+			commit: hashes[2],
+			want:   false,
+		},
+		{
+			name:   "fixed listed before it was introduced",
+			vuln:   gitVuln("OSV-11", "", models.Event{Fixed: hashes[2]}, models.Event{Introduced: hashes[0]}),
+			commit: hashes[3],
+			want:   false,
+		},
+		{
+			name:   "introduced zero in the same repository",
+			vuln:   gitVuln("OSV-7", "https://github.com/example/project", models.Event{Introduced: "0"}),
+			commit: hashes[3],
+			want:   true,
+		},
+		{
+			name:   "introduced zero in a different repository",
+			vuln:   gitVuln("OSV-8", "https://github.com/example/other", models.Event{Introduced: "0"}),
+			commit: hashes[3],
+			want:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		ancestors, err := repo.ancestorsOf(tt.commit)
+		if err != nil {
+			t.Fatalf("%s: could not get ancestors: %v", tt.name, err)
+		}
+
+		if got := repo.isCommitAffected(tt.vuln, tt.commit, ancestors); got != tt.want {
+			t.Errorf("%s: isCommitAffected() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestGitRepository_IsCommitAffected_ShuffledEvents(t *testing.T) {
+	t.Parallel()
+
+	dir, hashes := commitHistory(t, 5)
+
+	repo, err := openGitRepository(dir)
+	if err != nil {
+		t.Fatalf("could not open repository: %v", err)
+	}
+
+	// introduced in the first commit, fixed in the second, and then reintroduced in the fourth
+	events := []models.Event{{Introduced: hashes[0]}, {Fixed: hashes[1]}, {Introduced: hashes[3]}}
+	want := []bool{true, false, false, true, true}
+
+	orders := [][]int{{0, 1, 2}, {0, 2, 1}, {1, 0, 2}, {1, 2, 0}, {2, 0, 1}, {2, 1, 0}}
+
+	for _, order := range orders {
+		shuffled := make([]models.Event, 0, len(events))
+		for _, i := range order {
+			shuffled = append(shuffled, events[i])
+		}
+
+		vuln := gitVuln("OSV-1", "", shuffled...)
+
+		for i, commit := range hashes {
+			ancestors, err := repo.ancestorsOf(commit)
+			if err != nil {
+				t.Fatalf("could not get ancestors: %v", err)
+			}
+
+			if got := repo.isCommitAffected(vuln, commit, ancestors); got != want[i] {
+				t.Errorf("isCommitAffected(commit %d) with events in order %v = %v, want %v", i, order, got, want[i])
+			}
+		}
+	}
+}
+
+func TestZipDB_VulnerabilitiesAffectingCommit_NotInRepository(t *testing.T) {
+	t.Parallel()
+
+	dir, hashes := commitHistory(t, 2)
+
+	repo, err := openGitRepository(dir)
+	if err != nil {
+		t.Fatalf("could not open repository: %v", err)
+	}
+
+	// i.e. a commit from a submodule, or older than the history of a shallow clone
+	commit := "2b5d5e5b6d5e4f4e3f0b3a2b1c0d9e8f7a6b5c4d"
+
+	db := &ZipDB{vulnerabilities: []models.Vulnerability{
+		gitVuln("OSV-1", "", models.Event{Introduced: hashes[0]}, models.Event{LastAffected: commit}),
+		gitVuln("OSV-2", "", models.Event{Introduced: hashes[0]}),
+	}}
+
+	got := db.vulnerabilitiesAffectingCommit(repo, commit)
+
+	if len(got) != 1 || got[0].ID != "OSV-1" {
+		t.Errorf("vulnerabilitiesAffectingCommit() = %v, want only OSV-1 to match the commit exactly", got)
+	}
+}
+
+func TestIsCommitAffectedWithoutHistory(t *testing.T) {
+	t.Parallel()
+
+	commit := "2b5d5e5b6d5e4f4e3f0b3a2b1c0d9e8f7a6b5c4d"
+
+	tests := []struct {
+		name string
+		vuln models.Vulnerability
+		want bool
+	}{
+		{
+			name: "commit introduced the vulnerability",
+			vuln: gitVuln("OSV-1", "", models.Event{Introduced: commit}),
+			want: true,
+		},
+		{
+			name: "commit is the last affected",
+			vuln: gitVuln("OSV-2", "", models.Event{Introduced: "0"}, models.Event{LastAffected: commit}),
+			want: true,
+		},
+		{
+			name: "commit fixed the vulnerability",
+			vuln: gitVuln("OSV-3", "", models.Event{Introduced: "0"}, models.Event{Fixed: commit}),
+			want: false,
+		},
+		{
+			name: "introduced zero does not match unknown commits",
+			vuln: gitVuln("OSV-4", "", models.Event{Introduced: "0"}),
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		if got := isCommitAffectedWithoutHistory(tt.vuln, commit); got != tt.want {
+			t.Errorf("%s: isCommitAffectedWithoutHistory() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
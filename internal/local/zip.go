@@ -31,20 +31,41 @@ type ZipDB struct {
 	Offline bool
 	// the path to the zip archive on disk
 	StoredAt string
+	// the client used to download the zip archive, defaulting to http.DefaultClient
+	HTTPClient *http.Client
+	// the user agent sent when downloading, defaulting to osv.RequestUserAgent
+	UserAgent string
 	// the vulnerabilities that are loaded into this database
 	vulnerabilities []models.Vulnerability
 }
 
 var ErrOfflineDatabaseNotFound = errors.New("no offline version of the OSV database is available")
 
-func fetchRemoteArchiveCRC32CHash(url string) (uint32, error) {
-	req, err := http.NewRequestWithContext(context.Background(), http.MethodHead, url, nil)
+func (db *ZipDB) do(req *http.Request) (*http.Response, error) {
+	userAgent := db.UserAgent
+	if userAgent == "" {
+		userAgent = osv.RequestUserAgent
+	}
+
+	if userAgent != "" {
+		req.Header.Set("User-Agent", userAgent)
+	}
+
+	if db.HTTPClient == nil {
+		return http.DefaultClient.Do(req)
+	}
+
+	return db.HTTPClient.Do(req)
+}
+
+func (db *ZipDB) fetchRemoteArchiveCRC32CHash(ctx context.Context) (uint32, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, db.ArchiveURL, nil)
 
 	if err != nil {
 		return 0, err
 	}
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := db.do(req)
 	if err != nil {
 		return 0, err
 	}
@@ -75,7 +96,7 @@ func fetchLocalArchiveCRC32CHash(data []byte) uint32 {
 	return crc32.Checksum(data, crc32.MakeTable(crc32.Castagnoli))
 }
 
-func (db *ZipDB) fetchZip() ([]byte, error) {
+func (db *ZipDB) fetchZip(ctx context.Context) ([]byte, error) {
 	cache, err := os.ReadFile(db.StoredAt)
 
 	if db.Offline {
@@ -87,7 +108,7 @@ func (db *ZipDB) fetchZip() ([]byte, error) {
 	}
 
 	if err == nil {
-		remoteHash, err := fetchRemoteArchiveCRC32CHash(db.ArchiveURL)
+		remoteHash, err := db.fetchRemoteArchiveCRC32CHash(ctx)
 
 		if err != nil {
 			return nil, err
@@ -98,17 +119,13 @@ func (db *ZipDB) fetchZip() ([]byte, error) {
 		}
 	}
 
-	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, db.ArchiveURL, nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, db.ArchiveURL, nil)
 
 	if err != nil {
 		return nil, fmt.Errorf("could not retrieve OSV database archive: %w", err)
 	}
 
-	if osv.RequestUserAgent != "" {
-		req.Header.Set("User-Agent", osv.RequestUserAgent)
-	}
-
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := db.do(req)
 	if err != nil {
 		return nil, fmt.Errorf("could not retrieve OSV database archive: %w", err)
 	}
@@ -176,10 +193,10 @@ func (db *ZipDB) loadZipFile(zipFile *zip.File) {
 // Internally, the archive is cached along with the date that it was fetched
 // so that a new version of the archive is only downloaded if it has been
 // modified, per HTTP caching standards.
-func (db *ZipDB) load() error {
+func (db *ZipDB) load(ctx context.Context) error {
 	db.vulnerabilities = []models.Vulnerability{}
 
-	body, err := db.fetchZip()
+	body, err := db.fetchZip(ctx)
 
 	if err != nil {
 		return err
@@ -192,6 +209,10 @@ func (db *ZipDB) load() error {
 
 	// Read all the files from the zip archive
 	for _, zipFile := range zipReader.File {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
 		if !strings.HasSuffix(zipFile.Name, ".json") {
 			continue
 		}
@@ -202,14 +223,20 @@ func (db *ZipDB) load() error {
 	return nil
 }
 
-func NewZippedDB(dbBasePath, name, url string, offline bool) (*ZipDB, error) {
+func NewZippedDB(ctx context.Context, dbBasePath, name, url string, offline bool) (*ZipDB, error) {
+	return newZippedDB(ctx, dbBasePath, name, url, Options{Offline: offline})
+}
+
+func newZippedDB(ctx context.Context, dbBasePath, name, url string, opts Options) (*ZipDB, error) {
 	db := &ZipDB{
 		Name:       name,
 		ArchiveURL: url,
-		Offline:    offline,
+		Offline:    opts.Offline,
 		StoredAt:   path.Join(dbBasePath, name, "all.zip"),
+		HTTPClient: opts.HTTPClient,
+		UserAgent:  opts.UserAgent,
 	}
-	if err := db.load(); err != nil {
+	if err := db.load(ctx); err != nil {
 		return nil, fmt.Errorf("unable to fetch OSV database: %w", err)
 	}
 
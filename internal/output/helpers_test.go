@@ -178,6 +178,42 @@ func testOutputWithVulnerabilities(t *testing.T, run outputTestRunner) {
 				},
 			},
 		},
+		{
+			name: "one source with one package and one vulnerability scored with environmental metrics",
+			args: outputTestCaseArgs{
+				vulnResult: &models.VulnerabilityResults{
+					Results: []models.PackageSource{
+						{
+							Source: models.SourceInfo{Path: "path/to/my/first/lockfile"},
+							Packages: []models.PackageVulns{
+								{
+									Package: models.PackageInfo{
+										Name:      "mine1",
+										Version:   "1.2.3",
+										Ecosystem: "npm",
+									},
+									Groups: []models.GroupInfo{{
+										IDs:                   []string{"OSV-1"},
+										Aliases:               []string{"OSV-1"},
+										MaxSeverity:           "9.8",
+										EnvironmentalSeverity: "7.8",
+									}},
+									Vulnerabilities: models.Vulnerabilities{
+										{
+											ID:      "OSV-1",
+											Summary: "Something scary!",
+											Severity: []models.Severity{
+												{Type: models.SeverityCVSSV3, Score: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
 		{
 			name: "one source with one package and one called vulnerability",
 			args: outputTestCaseArgs{
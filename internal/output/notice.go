@@ -0,0 +1,213 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/google/osv-scanner/pkg/models"
+	"github.com/google/osv-scanner/pkg/spdx"
+)
+
+const noticeUnknownLicense = "UNKNOWN"
+
+// noticePackage is a package that is attributed in a NOTICE file
+type noticePackage struct {
+	models.PackageInfo
+	Notices []models.LicenseNotice
+}
+
+// String describes the package like "lodash 4.17.21 (npm)"
+func (pkg noticePackage) String() string {
+	version := pkg.Version
+	if version == "" {
+		version = pkg.Commit
+	}
+
+	if pkg.Ecosystem == "" {
+		return strings.TrimSpace(pkg.Name + " " + version)
+	}
+
+	return fmt.Sprintf("%s %s (%s)", pkg.Name, version, pkg.Ecosystem)
+}
+
+// noticeGroup is the packages of a NOTICE file that have the same license
+type noticeGroup struct {
+	License  string
+	Packages []noticePackage
+}
+
+// noticeLicense returns the license expression that all the licenses of a
+// package have to be complied with under
+func noticeLicense(licenses []models.License) string {
+	var known []string
+	for _, license := range licenses {
+		if license == "" || strings.EqualFold(string(license), noticeUnknownLicense) {
+			continue
+		}
+
+		known = append(known, string(license))
+	}
+
+	if len(known) == 0 {
+		return noticeUnknownLicense
+	}
+
+	// expressions are written in their canonical form so that packages are
+	// grouped together regardless of how they write the same license
+	for i, license := range known {
+		expr, err := spdx.Parse(license)
+		if err != nil {
+			continue
+		}
+
+		known[i] = expr.String()
+		if len(known) > 1 && !expr.IsLicense() {
+			known[i] = "(" + known[i] + ")"
+		}
+	}
+
+	return strings.Join(known, " AND ")
+}
+
+// groupNoticePackages groups the unique packages of the results by their
+// license, sorted by license with packages of unknown licenses last
+func groupNoticePackages(vulnResult *models.VulnerabilityResults) []noticeGroup {
+	type uniquePackage struct {
+		license string
+		pkg     noticePackage
+	}
+
+	var keys []string
+	unique := make(map[string]*uniquePackage)
+
+	for _, source := range vulnResult.Results {
+		for _, pkg := range source.Packages {
+			key := strings.Join([]string{pkg.Package.Ecosystem, pkg.Package.Name, pkg.Package.Version, pkg.Package.Commit}, "\x00")
+
+			if existing, ok := unique[key]; ok {
+				// the same package can be installed in several places
+				if len(existing.pkg.Notices) == 0 {
+					existing.pkg.Notices = pkg.LicenseNotices
+				}
+
+				continue
+			}
+
+			keys = append(keys, key)
+			unique[key] = &uniquePackage{
+				license: noticeLicense(pkg.Licenses),
+				pkg:     noticePackage{PackageInfo: pkg.Package, Notices: pkg.LicenseNotices},
+			}
+		}
+	}
+
+	var groups []noticeGroup
+	indexes := make(map[string]int)
+
+	for _, key := range keys {
+		u := unique[key]
+
+		i, ok := indexes[u.license]
+		if !ok {
+			i = len(groups)
+			indexes[u.license] = i
+			groups = append(groups, noticeGroup{License: u.license})
+		}
+
+		groups[i].Packages = append(groups[i].Packages, u.pkg)
+	}
+
+	for _, group := range groups {
+		sort.SliceStable(group.Packages, func(i, j int) bool {
+			return group.Packages[i].String() < group.Packages[j].String()
+		})
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		if (groups[i].License == noticeUnknownLicense) != (groups[j].License == noticeUnknownLicense) {
+			return groups[j].License == noticeUnknownLicense
+		}
+
+		// licenses are sorted by their first identifier, ignoring any parentheses
+		return strings.ToLower(strings.TrimLeft(groups[i].License, "(")) < strings.ToLower(strings.TrimLeft(groups[j].License, "("))
+	})
+
+	return groups
+}
+
+const noticeIntroduction = "The following third-party packages are used, grouped by their license, " +
+	"along with the license and copyright notices that they include."
+
+// PrintNoticeResults prints the packages of the results as a plain text
+// NOTICE file that attributes them, grouped by their license
+func PrintNoticeResults(vulnResult *models.VulnerabilityResults, outputWriter io.Writer) {
+	heavy := strings.Repeat("=", 80)
+	light := strings.Repeat("-", 80)
+
+	fmt.Fprintf(outputWriter, "THIRD-PARTY SOFTWARE NOTICES\n\n%s\n", noticeIntroduction)
+
+	groups := groupNoticePackages(vulnResult)
+	if len(groups) == 0 {
+		fmt.Fprintf(outputWriter, "\nNo third-party packages were found.\n")
+
+		return
+	}
+
+	for _, group := range groups {
+		fmt.Fprintf(
+			outputWriter,
+			"\n%s\n%s (%d %s)\n%s\n\n",
+			heavy,
+			group.License,
+			len(group.Packages),
+			Form(len(group.Packages), "package", "packages"),
+			heavy,
+		)
+
+		for _, pkg := range group.Packages {
+			fmt.Fprintf(outputWriter, "  * %s\n", pkg)
+		}
+
+		for _, pkg := range group.Packages {
+			for _, notice := range pkg.Notices {
+				fmt.Fprintf(outputWriter, "\n%s\n%s: %s\n%s\n\n", light, pkg, notice.Path, light)
+				fmt.Fprint(outputWriter, strings.TrimRight(notice.Text, "\n")+"\n")
+			}
+		}
+	}
+}
+
+// PrintMarkdownNoticeResults prints the packages of the results as a
+// markdown NOTICE file that attributes them, grouped by their license
+func PrintMarkdownNoticeResults(vulnResult *models.VulnerabilityResults, outputWriter io.Writer) {
+	fmt.Fprintf(outputWriter, "# Third-party software notices\n\n%s\n", noticeIntroduction)
+
+	groups := groupNoticePackages(vulnResult)
+	if len(groups) == 0 {
+		fmt.Fprintf(outputWriter, "\nNo third-party packages were found.\n")
+
+		return
+	}
+
+	for _, group := range groups {
+		fmt.Fprintf(outputWriter, "\n## %s\n\n", group.License)
+
+		for _, pkg := range group.Packages {
+			fmt.Fprintf(outputWriter, "- `%s`\n", pkg)
+		}
+
+		for _, pkg := range group.Packages {
+			for _, notice := range pkg.Notices {
+				fence := "```"
+				for strings.Contains(notice.Text, fence) {
+					fence += "`"
+				}
+
+				fmt.Fprintf(outputWriter, "\n### %s: %s\n\n", pkg, notice.Path)
+				fmt.Fprintf(outputWriter, "%stext\n%s\n%s\n", fence, strings.TrimRight(notice.Text, "\n"), fence)
+			}
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package output_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/osv-scanner/internal/output"
+	"github.com/google/osv-scanner/internal/testutility"
+	"github.com/google/osv-scanner/pkg/models"
+)
+
+// noticeResults has packages with a variety of licenses and notices,
+// including the same package being found in several sources
+func noticeResults() *models.VulnerabilityResults {
+	return &models.VulnerabilityResults{
+		Results: []models.PackageSource{
+			{
+				Source: models.SourceInfo{Path: "path/to/package-lock.json", Type: "lockfile"},
+				Packages: []models.PackageVulns{
+					{
+						Package:  models.PackageInfo{Name: "mine-1", Version: "1.0.0", Ecosystem: "npm"},
+						Licenses: []models.License{"MIT"},
+						LicenseNotices: []models.LicenseNotice{
+							{Path: "LICENSE", Text: "MIT License\n\nCopyright (c) 2024 Mine\n"},
+						},
+					},
+					{
+						Package:  models.PackageInfo{Name: "dual", Version: "2.0.0", Ecosystem: "npm"},
+						Licenses: []models.License{"MIT OR Apache-2.0"},
+					},
+					{
+						Package:  models.PackageInfo{Name: "both", Version: "1.2.3", Ecosystem: "npm"},
+						Licenses: []models.License{"MIT OR Apache-2.0", "ISC"},
+					},
+					{
+						Package:  models.PackageInfo{Name: "mystery", Version: "0.0.1", Ecosystem: "npm"},
+						Licenses: []models.License{"UNKNOWN"},
+					},
+					{
+						Package:  models.PackageInfo{Name: "fenced", Version: "3.0.0", Ecosystem: "npm"},
+						Licenses: []models.License{"BSD-3-Clause"},
+						LicenseNotices: []models.LicenseNotice{
+							{Path: "NOTICE.md", Text: "Use it like:\n\n```js\nrequire('fenced')\n```"},
+						},
+					},
+				},
+			},
+			{
+				Source: models.SourceInfo{Path: "path/to/other/package-lock.json", Type: "lockfile"},
+				Packages: []models.PackageVulns{
+					{
+						Package:  models.PackageInfo{Name: "mine-1", Version: "1.0.0", Ecosystem: "npm"},
+						Licenses: []models.License{"MIT"},
+					},
+					{
+						Package:  models.PackageInfo{Name: "dual-parenthesized", Version: "1.0.0", Ecosystem: "PyPI"},
+						Licenses: []models.License{"(MIT or Apache-2.0)"},
+					},
+					{
+						Package:  models.PackageInfo{Name: "Django", Version: "4.2.0", Ecosystem: "PyPI"},
+						Licenses: []models.License{"BSD-3-Clause"},
+					},
+					{
+						Package: models.PackageInfo{Name: "unlicensed", Version: "1.0.0", Ecosystem: "PyPI"},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestPrintNoticeResults(t *testing.T) {
+	t.Parallel()
+
+	outputWriter := &bytes.Buffer{}
+	output.PrintNoticeResults(noticeResults(), outputWriter)
+
+	testutility.NewSnapshot().MatchText(t, outputWriter.String())
+}
+
+func TestPrintNoticeResults_WithLicenseViolations(t *testing.T) {
+	t.Parallel()
+
+	testOutputWithLicenseViolations(t, func(t *testing.T, args outputTestCaseArgs) {
+		t.Helper()
+
+		outputWriter := &bytes.Buffer{}
+		output.PrintNoticeResults(args.vulnResult, outputWriter)
+
+		testutility.NewSnapshot().MatchText(t, outputWriter.String())
+	})
+}
+
+func TestPrintMarkdownNoticeResults(t *testing.T) {
+	t.Parallel()
+
+	outputWriter := &bytes.Buffer{}
+	output.PrintMarkdownNoticeResults(noticeResults(), outputWriter)
+
+	testutility.NewSnapshot().MatchText(t, outputWriter.String())
+}
+
+func TestPrintMarkdownNoticeResults_WithLicenseViolations(t *testing.T) {
+	t.Parallel()
+
+	testOutputWithLicenseViolations(t, func(t *testing.T, args outputTestCaseArgs) {
+		t.Helper()
+
+		outputWriter := &bytes.Buffer{}
+		output.PrintMarkdownNoticeResults(args.vulnResult, outputWriter)
+
+		testutility.NewSnapshot().MatchText(t, outputWriter.String())
+	})
+}
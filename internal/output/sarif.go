@@ -221,6 +221,35 @@ func createSARIFHelpText(gv *groupedSARIFFinding) string {
 	return helpText.String()
 }
 
+// environmentalGroupKey identifies a group of vulnerabilities by one of its
+// aliases, along with the package and source that it affects
+type environmentalGroupKey struct {
+	pkgWithSource
+	id string
+}
+
+// mapEnvironmentalGroups maps the groups of vulnerabilities that have been
+// scored with environmental metrics by each of their aliases
+func mapEnvironmentalGroups(vulnResult *models.VulnerabilityResults) map[environmentalGroupKey]models.GroupInfo {
+	groups := make(map[environmentalGroupKey]models.GroupInfo)
+
+	for _, res := range vulnResult.Results {
+		for _, pkg := range res.Packages {
+			for _, group := range pkg.Groups {
+				if group.EnvironmentalSeverity == "" {
+					continue
+				}
+
+				for _, id := range slices.Concat(group.IDs, group.Aliases) {
+					groups[environmentalGroupKey{pkgWithSource{Package: pkg.Package, Source: res.Source}, id}] = group
+				}
+			}
+		}
+	}
+
+	return groups
+}
+
 // PrintSARIFReport prints SARIF output to outputWriter
 func PrintSARIFReport(vulnResult *models.VulnerabilityResults, outputWriter io.Writer) error {
 	report, err := sarif.New(sarif.Version210)
@@ -232,6 +261,7 @@ func PrintSARIFReport(vulnResult *models.VulnerabilityResults, outputWriter io.W
 	run.Tool.Driver.WithVersion(version.OSVVersion)
 
 	vulnIDMap := mapIDsToGroupedSARIFFinding(vulnResult)
+	environmentalGroups := mapEnvironmentalGroups(vulnResult)
 	// Sort the IDs to have deterministic loop of vulnIDMap
 	vulnIDs := []string{}
 	for vulnID := range vulnIDMap {
@@ -293,7 +323,18 @@ func PrintSARIFReport(vulnResult *models.VulnerabilityResults, outputWriter io.W
 				alsoKnownAsStr = fmt.Sprintf(" (also known as '%s')", strings.Join(gv.AliasedIDList[1:], "', '"))
 			}
 
-			run.CreateResultForRule(gv.DisplayID).
+			result := run.CreateResultForRule(gv.DisplayID)
+
+			// the scores of vulnerabilities are only included when they have been
+			// adjusted with environmental metrics, as they differ between results
+			if group, ok := environmentalGroups[environmentalGroupKey{pws, gv.DisplayID}]; ok {
+				properties := sarif.NewPropertyBag()
+				properties.AddString("max_severity", group.MaxSeverity)
+				properties.AddString("environmental_severity", group.EnvironmentalSeverity)
+				result.AttachPropertyBag(properties)
+			}
+
+			result.
 				WithLevel("warning").
 				WithMessage(
 					sarif.NewTextMessage(
@@ -117,7 +117,7 @@ func tableBuilderInner(vulnResult *models.VulnerabilityResults, calledVulns bool
 				}
 
 				outputRow = append(outputRow, strings.Join(links, "\n"))
-				outputRow = append(outputRow, group.MaxSeverity)
+				outputRow = append(outputRow, groupSeverity(group))
 
 				if pkg.Package.Ecosystem == "" && pkg.Package.Commit != "" {
 					pkgCommitStr := results.PkgToString(pkg.Package)
@@ -143,7 +143,23 @@ func tableBuilderInner(vulnResult *models.VulnerabilityResults, calledVulns bool
 	return allOutputRows
 }
 
+// groupSeverity describes the highest CVSS score of the group, along with
+// its base score if it was scored with environmental metrics
+func groupSeverity(group models.GroupInfo) string {
+	if group.EnvironmentalSeverity == "" {
+		return group.MaxSeverity
+	}
+
+	return fmt.Sprintf("%s (base %s)", group.EnvironmentalSeverity, group.MaxSeverity)
+}
+
 func MaxSeverity(group models.GroupInfo, pkg models.PackageVulns) string {
+	return MaxEnvironmentalSeverity(group, pkg, severity.Environment{})
+}
+
+// MaxEnvironmentalSeverity returns the highest CVSS score of the group when
+// its vectors are scored with the metrics of the environment
+func MaxEnvironmentalSeverity(group models.GroupInfo, pkg models.PackageVulns, env severity.Environment) string {
 	var maxSeverity float64 = -1
 	for _, vulnID := range group.IDs {
 		var severities []models.Severity
@@ -152,7 +168,7 @@ func MaxSeverity(group models.GroupInfo, pkg models.PackageVulns) string {
 				severities = vuln.Severity
 			}
 		}
-		score, _, _ := severity.CalculateOverallScore(severities)
+		score, _, _ := severity.CalculateOverallEnvironmentalScore(severities, env)
 		maxSeverity = max(maxSeverity, score)
 	}
 
@@ -167,7 +183,7 @@ func licenseTableBuilder(outputTable table.Writer, vulnResult *models.Vulnerabil
 	licenseConfig := vulnResult.ExperimentalAnalysisConfig.Licenses
 	if licenseConfig.Summary {
 		return licenseSummaryTableBuilder(outputTable, vulnResult)
-	} else if len(licenseConfig.Allowlist) > 0 {
+	} else if licenseConfig.HasPolicy() {
 		return licenseViolationsTableBuilder(outputTable, vulnResult)
 	}
 
@@ -15,7 +15,7 @@ func PrintVerticalResults(vulnResult *models.VulnerabilityResults, outputWriter
 		printVerticalHeader(result, outputWriter)
 		printVerticalVulnerabilities(result, outputWriter)
 
-		if len(vulnResult.ExperimentalAnalysisConfig.Licenses.Allowlist) > 0 {
+		if vulnResult.ExperimentalAnalysisConfig.Licenses.HasPolicy() {
 			printVerticalLicenseViolations(result, outputWriter)
 		}
 
@@ -0,0 +1,194 @@
+// Package pathfilter decides which paths should be scanned based on
+// include and exclude glob patterns.
+package pathfilter
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+)
+
+// Pattern is a glob that is matched against slash separated paths.
+//
+// Each segment of the pattern is matched using the syntax of path.Match, with
+// "**" matching any number of segments (including none). Like .gitignore,
+// a pattern without a slash (other than a trailing one) matches at any depth,
+// whereas a pattern with one is matched relative to the root of the scan.
+type Pattern struct {
+	raw      string
+	segments []string
+}
+
+// Compile parses the given glob into a Pattern
+func Compile(glob string) (Pattern, error) {
+	trimmed := strings.TrimSuffix(strings.TrimPrefix(glob, "./"), "/")
+
+	if trimmed == "" {
+		return Pattern{}, fmt.Errorf("invalid path pattern %q: pattern is empty", glob)
+	}
+
+	if !strings.Contains(trimmed, "/") {
+		trimmed = "**/" + trimmed
+	}
+
+	segments := strings.Split(strings.TrimPrefix(trimmed, "/"), "/")
+
+	for _, segment := range segments {
+		if _, err := path.Match(segment, ""); err != nil {
+			return Pattern{}, fmt.Errorf("invalid path pattern %q: %w", glob, err)
+		}
+	}
+
+	return Pattern{raw: glob, segments: segments}, nil
+}
+
+func (p Pattern) String() string {
+	return p.raw
+}
+
+// Match returns true if the pattern matches the given path,
+// or any of the directories that it is within
+func (p Pattern) Match(relPath string) bool {
+	segments := strings.Split(relPath, "/")
+
+	for i := range segments {
+		if matchSegments(p.segments, segments[:i+1]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+
+			return false
+		}
+
+		if len(name) == 0 {
+			return false
+		}
+
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+
+		pattern, name = pattern[1:], name[1:]
+	}
+
+	return len(name) == 0
+}
+
+// Rule is a set of patterns that paths are included or excluded by,
+// optionally only applying to files that are parsed with certain parsers
+type Rule struct {
+	Include []Pattern
+	Exclude []Pattern
+	// Parsers limits the rule to files parsed with one of these parsers,
+	// with the rule applying to all paths if empty
+	Parsers []string
+}
+
+// NewRule compiles the given globs into a Rule
+func NewRule(include, exclude, parsers []string) (Rule, error) {
+	rule := Rule{Parsers: parsers}
+
+	for _, glob := range include {
+		pattern, err := Compile(glob)
+		if err != nil {
+			return Rule{}, err
+		}
+		rule.Include = append(rule.Include, pattern)
+	}
+
+	for _, glob := range exclude {
+		pattern, err := Compile(glob)
+		if err != nil {
+			return Rule{}, err
+		}
+		rule.Exclude = append(rule.Exclude, pattern)
+	}
+
+	return rule, nil
+}
+
+func (r Rule) appliesTo(parser string) bool {
+	return len(r.Parsers) == 0 || slices.Contains(r.Parsers, parser)
+}
+
+// Filter decides which paths are scanned using a set of rules.
+//
+// A path is skipped if it matches any exclude pattern that applies to it, or
+// if there are include patterns that apply to it and it does not match any.
+type Filter struct {
+	Rules []Rule
+}
+
+// SkipDir returns whether the directory at the given path (relative to the
+// root of the scan) should be skipped, along with the reason why.
+//
+// Only rules that apply to all parsers are considered, as directories can
+// contain files for other parsers, and include patterns are not considered
+// as directories need to be walked to find the files within that match them.
+func (f *Filter) SkipDir(relPath string) (bool, string) {
+	if f == nil {
+		return false, ""
+	}
+
+	for _, rule := range f.Rules {
+		if len(rule.Parsers) > 0 {
+			continue
+		}
+
+		for _, pattern := range rule.Exclude {
+			if pattern.Match(relPath) {
+				return true, fmt.Sprintf("it matches the exclude pattern %q", pattern)
+			}
+		}
+	}
+
+	return false, ""
+}
+
+// SkipFile returns whether the file at the given path (relative to the root
+// of the scan) should be skipped when it is parsed with the given parser
+// (which is empty for files that are not lockfiles), along with the reason why
+func (f *Filter) SkipFile(relPath string, parser string) (bool, string) {
+	if f == nil {
+		return false, ""
+	}
+
+	hasIncludes := false
+	included := false
+
+	for _, rule := range f.Rules {
+		if !rule.appliesTo(parser) {
+			continue
+		}
+
+		for _, pattern := range rule.Exclude {
+			if pattern.Match(relPath) {
+				return true, fmt.Sprintf("it matches the exclude pattern %q", pattern)
+			}
+		}
+
+		for _, pattern := range rule.Include {
+			hasIncludes = true
+			included = included || pattern.Match(relPath)
+		}
+	}
+
+	if hasIncludes && !included {
+		return true, "it does not match any include patterns"
+	}
+
+	return false, ""
+}
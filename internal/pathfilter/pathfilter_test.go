@@ -0,0 +1,113 @@
+package pathfilter_test
+
+import (
+	"testing"
+
+	"github.com/google/osv-scanner/internal/pathfilter"
+)
+
+func TestPattern_Match(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{pattern: "third_party", path: "third_party", want: true},
+		{pattern: "third_party", path: "third_party/lib/go.mod", want: true},
+		{pattern: "third_party", path: "src/third_party/go.mod", want: true},
+		{pattern: "third_party/", path: "src/third_party/go.mod", want: true},
+		{pattern: "/third_party", path: "src/third_party/go.mod", want: false},
+		{pattern: "/third_party", path: "third_party/go.mod", want: true},
+		{pattern: "docs/*.txt", path: "docs/requirements.txt", want: true},
+		{pattern: "docs/*.txt", path: "src/docs/requirements.txt", want: false},
+		{pattern: "docs/*.txt", path: "docs/examples/requirements.txt", want: false},
+		{pattern: "docs/**/*.txt", path: "docs/requirements.txt", want: true},
+		{pattern: "docs/**/*.txt", path: "docs/examples/a/b/requirements.txt", want: true},
+		{pattern: "**/testdata/**", path: "pkg/testdata/package-lock.json", want: true},
+		{pattern: "**/testdata/**", path: "testdata/package-lock.json", want: true},
+		{pattern: "**/testdata/**", path: "pkg/testdata2/package-lock.json", want: false},
+		{pattern: "*.lock", path: "a/b/yarn.lock", want: true},
+		{pattern: "*.lock", path: "a/b/package-lock.json", want: false},
+		{pattern: "./examples", path: "examples/go.mod", want: true},
+	}
+
+	for _, tt := range tests {
+		pattern, err := pathfilter.Compile(tt.pattern)
+		if err != nil {
+			t.Fatalf("Compile(%q) unexpected error: %v", tt.pattern, err)
+		}
+
+		if got := pattern.Match(tt.path); got != tt.want {
+			t.Errorf("Compile(%q).Match(%q) = %v, want %v", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestCompile_Invalid(t *testing.T) {
+	t.Parallel()
+
+	for _, glob := range []string{"", "/", "docs/[a-"} {
+		if _, err := pathfilter.Compile(glob); err == nil {
+			t.Errorf("Compile(%q) expected an error", glob)
+		}
+	}
+}
+
+func TestFilter(t *testing.T) {
+	t.Parallel()
+
+	everywhere, err := pathfilter.NewRule([]string{"services/**"}, []string{"**/testdata/**"}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	requirements, err := pathfilter.NewRule(nil, []string{"docs"}, []string{"requirements.txt"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	filter := &pathfilter.Filter{Rules: []pathfilter.Rule{everywhere, requirements}}
+
+	dirs := []struct {
+		path string
+		want bool
+	}{
+		{path: "services/api/testdata", want: true},
+		// directories are never skipped by rules that are scoped to parsers
+		{path: "services/docs", want: false},
+		// or by include patterns, as they might have files that are included
+		{path: "tools", want: false},
+	}
+
+	for _, tt := range dirs {
+		if got, _ := filter.SkipDir(tt.path); got != tt.want {
+			t.Errorf("SkipDir(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+
+	files := []struct {
+		path   string
+		parser string
+		want   bool
+	}{
+		{path: "services/api/package-lock.json", parser: "package-lock.json", want: false},
+		{path: "services/api/testdata/package-lock.json", parser: "package-lock.json", want: true},
+		{path: "tools/package-lock.json", parser: "package-lock.json", want: true},
+		{path: "services/docs/requirements.txt", parser: "requirements.txt", want: true},
+		{path: "services/docs/Pipfile.lock", parser: "Pipfile.lock", want: false},
+		{path: "services/api/bom.json", parser: "", want: false},
+	}
+
+	for _, tt := range files {
+		if got, _ := filter.SkipFile(tt.path, tt.parser); got != tt.want {
+			t.Errorf("SkipFile(%q, %q) = %v, want %v", tt.path, tt.parser, got, tt.want)
+		}
+	}
+
+	var nilFilter *pathfilter.Filter
+	if skip, _ := nilFilter.SkipFile("a/b", ""); skip {
+		t.Errorf("expected a nil filter to not skip anything")
+	}
+}
@@ -17,8 +17,8 @@ import (
 	"golang.org/x/vuln/scan"
 )
 
-func goAnalysis(r reporter.Reporter, pkgs []models.PackageVulns, source models.SourceInfo) {
-	cmd := exec.Command("go", "version")
+func goAnalysis(ctx context.Context, r reporter.Reporter, pkgs []models.PackageVulns, source models.SourceInfo) {
+	cmd := exec.CommandContext(ctx, "go", "version")
 	_, err := cmd.Output()
 	if err != nil {
 		r.Infof("Skipping call analysis on Go code since Go is not installed.\n")
@@ -44,7 +44,7 @@ func goAnalysis(r reporter.Reporter, pkgs []models.PackageVulns, source models.S
 		}
 	}
 
-	res, err := runGovulncheck(filepath.Dir(source.Path), filteredVulns, goVersion)
+	res, err := runGovulncheck(ctx, filepath.Dir(source.Path), filteredVulns, goVersion)
 	if err != nil {
 		// TODO: Better method to identify the type of error and give advice specific to the error
 		r.Errorf(
@@ -126,7 +126,7 @@ func fillNotImportedAnalysisInfo(vulnsByID map[string]models.Vulnerability, vuln
 	}
 }
 
-func runGovulncheck(moddir string, vulns []models.Vulnerability, goVersion string) (map[string][]*govulncheck.Finding, error) {
+func runGovulncheck(ctx context.Context, moddir string, vulns []models.Vulnerability, goVersion string) (map[string][]*govulncheck.Finding, error) {
 	// Create a temporary directory containing all of the vulnerabilities that
 	// are passed in to check against govulncheck.
 	//
@@ -159,7 +159,7 @@ func runGovulncheck(moddir string, vulns []models.Vulnerability, goVersion strin
 
 	// Run govulncheck on the module at moddir and vulnerability database that
 	// was just created.
-	cmd := scan.Command(context.Background(), "-db", dbdirURL.String(), "-C", moddir, "-json", "./...")
+	cmd := scan.Command(ctx, "-db", dbdirURL.String(), "-C", moddir, "-json", "./...")
 	var b bytes.Buffer
 	cmd.Stdout = &b
 	cmd.Env = append(os.Environ(), "GOVERSION=go"+goVersion)
@@ -1,6 +1,7 @@
 package sourceanalysis
 
 import (
+	"context"
 	"encoding/json"
 	"os"
 	"path/filepath"
@@ -44,7 +45,7 @@ func Test_runGovulncheck(t *testing.T) {
 		vulns = append(vulns, newVuln)
 	}
 
-	res, err := runGovulncheck(filepath.Join(fixturesDir, "test-project"), vulns, "1.19")
+	res, err := runGovulncheck(context.Background(), filepath.Join(fixturesDir, "test-project"), vulns, "1.19")
 	if err != nil {
 		t.Errorf("failed to run RunGoVulnCheck: %v", err)
 	}
@@ -2,6 +2,7 @@ package sourceanalysis
 
 import (
 	"bytes"
+	"context"
 	"debug/dwarf"
 	"debug/elf"
 	"errors"
@@ -31,8 +32,8 @@ const (
 	RustLibExtension = ".rcgu.o/"
 )
 
-func rustAnalysis(r reporter.Reporter, pkgs []models.PackageVulns, source models.SourceInfo) {
-	binaryPaths, err := rustBuildSource(r, source)
+func rustAnalysis(ctx context.Context, r reporter.Reporter, pkgs []models.PackageVulns, source models.SourceInfo) {
+	binaryPaths, err := rustBuildSource(ctx, r, source)
 	if err != nil {
 		r.Errorf("failed to build cargo/rust project from source: %s\n", err)
 		return
@@ -218,10 +219,10 @@ func extractRlibArchive(rlibPath string) (bytes.Buffer, error) {
 	return buf, nil
 }
 
-func rustBuildSource(r reporter.Reporter, source models.SourceInfo) ([]string, error) {
+func rustBuildSource(ctx context.Context, r reporter.Reporter, source models.SourceInfo) ([]string, error) {
 	projectBaseDir := filepath.Dir(source.Path)
 
-	cmd := exec.Command("cargo", "build", "--workspace", "--all-targets", "--release")
+	cmd := exec.CommandContext(ctx, "cargo", "build", "--workspace", "--all-targets", "--release")
 	cmd.Env = append(cmd.Environ(), RustFlagsEnv)
 	cmd.Dir = projectBaseDir
 	if errors.Is(cmd.Err, exec.ErrDot) {
@@ -2,6 +2,7 @@ package sourceanalysis
 
 import (
 	"bytes"
+	"context"
 	"os"
 	"path/filepath"
 	"reflect"
@@ -97,7 +98,7 @@ func Test_rustBuildSource(t *testing.T) {
 		},
 	}
 	for _, tt := range tests {
-		got, err := rustBuildSource(tt.args.r, tt.args.source)
+		got, err := rustBuildSource(context.Background(), tt.args.r, tt.args.source)
 		if (err != nil) != tt.wantErr {
 			t.Errorf("rustBuildSource() error = %v, wantErr %v", err, tt.wantErr)
 			return
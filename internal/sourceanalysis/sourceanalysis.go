@@ -1,6 +1,7 @@
 package sourceanalysis
 
 import (
+	"context"
 	"path/filepath"
 
 	"github.com/google/osv-scanner/pkg/models"
@@ -24,14 +25,15 @@ func vulnsFromAllPkgs(pkgs []models.PackageVulns) ([]models.Vulnerability, map[s
 	return vulns, flatVulns
 }
 
-// Run runs the language specific analyzers on the code given packages and source info
-func Run(r reporter.Reporter, source models.SourceInfo, pkgs []models.PackageVulns, callAnalysis map[string]bool) {
+// Run runs the language specific analyzers on the code given packages and source info,
+// stopping any external tools that are still running if the context is cancelled
+func Run(ctx context.Context, r reporter.Reporter, source models.SourceInfo, pkgs []models.PackageVulns, callAnalysis map[string]bool) {
 	// GoVulnCheck
 	if source.Type == "lockfile" && filepath.Base(source.Path) == "go.mod" && callAnalysis["go"] {
-		goAnalysis(r, pkgs, source)
+		goAnalysis(ctx, r, pkgs, source)
 	}
 
 	if source.Type == "lockfile" && filepath.Base(source.Path) == "Cargo.lock" && callAnalysis["rust"] {
-		rustAnalysis(r, pkgs, source)
+		rustAnalysis(ctx, r, pkgs, source)
 	}
 }
@@ -9,10 +9,10 @@ import (
 	"github.com/google/osv-scanner/pkg/models"
 )
 
-var shouldDebugInJSON bool
+// shouldDebugInJSON is only read from the environment once so that it is safe to use concurrently
+var shouldDebugInJSON = os.Getenv("debug") == "true"
 
 func InJSON[P models.IFilePosition](groupKey string, dependencies map[string]P, lines []string, offset int) {
-	shouldDebugInJSON = os.Getenv("debug") == "true"
 	var group, dependency string
 	var groupLevel, stack int
 
@@ -8,10 +8,10 @@ import (
 	"github.com/google/osv-scanner/pkg/models"
 )
 
-var shouldDebugInTOML bool
+// shouldDebugInTOML is only read from the environment once so that it is safe to use concurrently
+var shouldDebugInTOML = os.Getenv("debug") == "true"
 
 func InTOML[P models.IFilePosition](groupKey string, otherKey string, dependencies []P, lines []string) {
-	shouldDebugInTOML = os.Getenv("debug") == "true"
 	dependency := 0
 	open := false
 	for lineNumber, line := range lines {
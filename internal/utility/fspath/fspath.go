@@ -0,0 +1,20 @@
+package fspath
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Name converts the given path into a name that is valid for an fs.FS,
+// treating it as relative to the root of the filesystem regardless of if it
+// has a leading slash, and never going above the root
+func Name(p string) string {
+	name := strings.TrimPrefix(path.Clean("/"+filepath.ToSlash(p)), "/")
+
+	if name == "" {
+		return "."
+	}
+
+	return name
+}
@@ -0,0 +1,30 @@
+package fspath_test
+
+import (
+	"testing"
+
+	"github.com/google/osv-scanner/internal/utility/fspath"
+)
+
+func TestName(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: "", want: "."},
+		{path: ".", want: "."},
+		{path: "/", want: "."},
+		{path: "dir/file.txt", want: "dir/file.txt"},
+		{path: "/dir/file.txt", want: "dir/file.txt"},
+		{path: "./dir/../other/file.txt", want: "other/file.txt"},
+		{path: "../../file.txt", want: "file.txt"},
+	}
+
+	for _, tt := range tests {
+		if got := fspath.Name(tt.path); got != tt.want {
+			t.Errorf("Name(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
@@ -33,6 +33,10 @@ func Group(packageSources []models.PackageSource) (map[string]models.PackageVuln
 					packageVulns.Metadata = packageVulns.Metadata.Merge(pkg.Metadata)
 				}
 
+				if len(packageVulns.LicenseNotices) == 0 {
+					packageVulns.LicenseNotices = slices.Clone(pkg.LicenseNotices)
+				}
+
 				uniquePackages[packageURL.ToString()] = packageVulns
 			} else {
 				// Entry does not exists yet, lets create it
@@ -43,7 +47,9 @@ func Group(packageSources []models.PackageSource) (map[string]models.PackageVuln
 					Vulnerabilities:   slices.Clone(pkg.Vulnerabilities),
 					Groups:            slices.Clone(pkg.Groups),
 					Licenses:          slices.Clone(pkg.Licenses),
+					LicenseSource:     pkg.LicenseSource,
 					LicenseViolations: slices.Clone(pkg.LicenseViolations),
+					LicenseNotices:    slices.Clone(pkg.LicenseNotices),
 					Metadata:          pkg.Metadata,
 				}
 				uniquePackages[packageURL.ToString()] = newPackageVuln
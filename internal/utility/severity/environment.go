@@ -0,0 +1,157 @@
+package severity
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/osv-scanner/pkg/models"
+	gocvss30 "github.com/pandatix/go-cvss/30"
+	gocvss31 "github.com/pandatix/go-cvss/31"
+	gocvss40 "github.com/pandatix/go-cvss/40"
+)
+
+// cvssV3EnvironmentalMetrics are the metrics of the environmental group of
+// CVSS v3.0 and v3.1, which are the only ones that environments can set
+var cvssV3EnvironmentalMetrics = []string{"CR", "IR", "AR", "MAV", "MAC", "MPR", "MUI", "MS", "MC", "MI", "MA"}
+
+// cvssV4EnvironmentalMetrics are the metrics of the environmental group of CVSS v4.0
+var cvssV4EnvironmentalMetrics = []string{"CR", "IR", "AR", "MAV", "MAC", "MAT", "MPR", "MUI", "MVC", "MVI", "MVA", "MSC", "MSI", "MSA"}
+
+// Environment is the environmental metrics that CVSS vectors are scored with,
+// given like "MAV:A/CR:L", which are separate for CVSS v3 (both v3.0 and
+// v3.1) and v4 vectors as their metrics differ
+type Environment struct {
+	CVSSV3 string
+	CVSSV4 string
+}
+
+// IsZero reports if the environment does not set any metrics
+func (env Environment) IsZero() bool {
+	return env.CVSSV3 == "" && env.CVSSV4 == ""
+}
+
+// Rescores reports if the environment sets metrics for the CVSS version of
+// the severity, meaning its environmental score can differ from its base score
+func (env Environment) Rescores(severity models.Severity) bool {
+	switch severity.Type {
+	case models.SeverityCVSSV3:
+		return env.CVSSV3 != "" && (strings.HasPrefix(severity.Score, "CVSS:3.0") || strings.HasPrefix(severity.Score, "CVSS:3.1"))
+	case models.SeverityCVSSV4:
+		return env.CVSSV4 != ""
+	default:
+		return false
+	}
+}
+
+// Validate returns an error if any of the metrics of the environment are not
+// environmental metrics of their CVSS version, or have invalid values
+func (env Environment) Validate() error {
+	if env.CVSSV3 != "" {
+		vec, _ := gocvss31.ParseVector("CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H")
+		if err := setEnvironmentalMetrics(env.CVSSV3, cvssV3EnvironmentalMetrics, vec.Set); err != nil {
+			return fmt.Errorf("invalid CVSS v3 metrics %q: %w", env.CVSSV3, err)
+		}
+	}
+
+	if env.CVSSV4 != "" {
+		vec, _ := gocvss40.ParseVector("CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N")
+		if err := setEnvironmentalMetrics(env.CVSSV4, cvssV4EnvironmentalMetrics, vec.Set); err != nil {
+			return fmt.Errorf("invalid CVSS v4 metrics %q: %w", env.CVSSV4, err)
+		}
+	}
+
+	return nil
+}
+
+// setEnvironmentalMetrics sets each of the metrics (i.e. "MAV:A/CR:L") with
+// the setter of a vector, provided they are one of the allowed metrics
+func setEnvironmentalMetrics(metrics string, allowed []string, set func(abv string, value string) error) error {
+	for _, metric := range strings.Split(metrics, "/") {
+		abv, value, ok := strings.Cut(strings.TrimSpace(metric), ":")
+		if !ok {
+			return fmt.Errorf("%q is not a metric, i.e. MAV:A", metric)
+		}
+
+		if !slices.Contains(allowed, abv) {
+			return fmt.Errorf("%q is not an environmental metric, which are %s", abv, strings.Join(allowed, ", "))
+		}
+
+		if err := set(abv, value); err != nil {
+			return fmt.Errorf("%q has an invalid value: %w", metric, err)
+		}
+	}
+
+	return nil
+}
+
+// CalculateEnvironmentalScore returns the score of the severity with the
+// metrics of the environment for its CVSS version, which is its base score
+// if the environment does not set any for it or it is a CVSS v2 severity
+func CalculateEnvironmentalScore(severity models.Severity, env Environment) (float64, string, error) {
+	score := -1.0
+	rating := unknownRating
+	var err error
+
+	if !env.Rescores(severity) {
+		return CalculateScore(severity)
+	}
+
+	switch {
+	case severity.Type == models.SeverityCVSSV3 && strings.HasPrefix(severity.Score, "CVSS:3.0"):
+		var vec *gocvss30.CVSS30
+		vec, err = gocvss30.ParseVector(severity.Score)
+		if err == nil {
+			err = setEnvironmentalMetrics(env.CVSSV3, cvssV3EnvironmentalMetrics, vec.Set)
+		}
+		if err == nil {
+			score = vec.EnvironmentalScore()
+			rating, err = gocvss30.Rating(score)
+		}
+	case severity.Type == models.SeverityCVSSV3 && strings.HasPrefix(severity.Score, "CVSS:3.1"):
+		var vec *gocvss31.CVSS31
+		vec, err = gocvss31.ParseVector(severity.Score)
+		if err == nil {
+			err = setEnvironmentalMetrics(env.CVSSV3, cvssV3EnvironmentalMetrics, vec.Set)
+		}
+		if err == nil {
+			score = vec.EnvironmentalScore()
+			rating, err = gocvss31.Rating(score)
+		}
+	case severity.Type == models.SeverityCVSSV4:
+		var vec *gocvss40.CVSS40
+		vec, err = gocvss40.ParseVector(severity.Score)
+		if err == nil {
+			err = setEnvironmentalMetrics(env.CVSSV4, cvssV4EnvironmentalMetrics, vec.Set)
+		}
+		if err == nil {
+			// the score of CVSS v4 vectors includes their environmental metrics
+			score = vec.Score()
+			rating, err = gocvss40.Rating(score)
+		}
+	default:
+		return CalculateScore(severity)
+	}
+
+	return score, rating, err
+}
+
+// CalculateOverallEnvironmentalScore returns the highest score of the
+// severities with the metrics of the environment
+func CalculateOverallEnvironmentalScore(severities []models.Severity, env Environment) (float64, string, error) {
+	maxScore := -1.0
+	maxRating := unknownRating
+
+	for _, severity := range severities {
+		score, rating, err := CalculateEnvironmentalScore(severity, env)
+		if err != nil {
+			return -1, unknownRating, err
+		}
+		if score > maxScore {
+			maxScore = score
+			maxRating = rating
+		}
+	}
+
+	return maxScore, maxRating, nil
+}
@@ -1,6 +1,8 @@
 package severity
 
 import (
+	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/google/osv-scanner/pkg/models"
@@ -12,6 +14,44 @@ import (
 
 const unknownRating = "UNKNOWN"
 
+// ratingThresholds are the lowest CVSS scores that have each rating
+var ratingThresholds = map[string]float64{
+	"LOW":      0.1,
+	"MEDIUM":   4.0,
+	"HIGH":     7.0,
+	"CRITICAL": 9.0,
+}
+
+// Rating returns the CVSS v3 rating of the score, which is UNKNOWN for
+// negative scores as they are used when there is no known severity
+func Rating(score float64) string {
+	if score < 0 {
+		return unknownRating
+	}
+
+	rating, err := gocvss31.Rating(score)
+	if err != nil {
+		return unknownRating
+	}
+
+	return rating
+}
+
+// ParseThreshold parses a severity threshold, which is either a rating
+// (i.e. "high") for the lowest score with that rating, or a CVSS score
+func ParseThreshold(threshold string) (float64, error) {
+	if score, ok := ratingThresholds[strings.ToUpper(threshold)]; ok {
+		return score, nil
+	}
+
+	score, err := strconv.ParseFloat(threshold, 64)
+	if err != nil || score < 0 || score > 10 {
+		return -1, fmt.Errorf("invalid severity threshold %q - must be one of low, medium, high, critical or a CVSS score between 0 and 10", threshold)
+	}
+
+	return score, nil
+}
+
 func CalculateScore(severity models.Severity) (float64, string, error) {
 	score := -1.0
 	rating := unknownRating
@@ -90,3 +90,174 @@ func TestSeverity_CalculateScore(t *testing.T) {
 		})
 	}
 }
+
+func TestSeverity_ParseThreshold(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		threshold string
+		want      float64
+		wantErr   bool
+	}{
+		{threshold: "low", want: 0.1},
+		{threshold: "Medium", want: 4.0},
+		{threshold: "HIGH", want: 7.0},
+		{threshold: "critical", want: 9.0},
+		{threshold: "7.5", want: 7.5},
+		{threshold: "0", want: 0},
+		{threshold: "10", want: 10},
+		{threshold: "10.1", wantErr: true},
+		{threshold: "-1", wantErr: true},
+		{threshold: "severe", wantErr: true},
+		{threshold: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.threshold, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := severity.ParseThreshold(tt.threshold)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseThreshold() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("ParseThreshold() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSeverity_Rating(t *testing.T) {
+	t.Parallel()
+
+	for score, want := range map[float64]string{
+		-1:  "UNKNOWN",
+		0:   "NONE",
+		3.9: "LOW",
+		4:   "MEDIUM",
+		7.5: "HIGH",
+		9.8: "CRITICAL",
+		11:  "UNKNOWN",
+	} {
+		if got := severity.Rating(score); got != want {
+			t.Errorf("Rating(%v) = %q, want %q", score, got, want)
+		}
+	}
+}
+
+func TestSeverity_CalculateEnvironmentalScore(t *testing.T) {
+	t.Parallel()
+
+	type result struct {
+		score  float64
+		rating string
+	}
+	env := severity.Environment{CVSSV3: "MAV:A/CR:L/IR:L", CVSSV4: "MAV:A/CR:L/IR:L"}
+	tests := []struct {
+		name string
+		sev  models.Severity
+		env  severity.Environment
+		want result
+	}{
+		{
+			name: "CVSS v2.0",
+			sev: models.Severity{
+				Type:  models.SeverityCVSSV2,
+				Score: "AV:N/AC:L/Au:N/C:C/I:C/A:C",
+			},
+			env: env,
+			want: result{
+				score:  10.0,
+				rating: "CRITICAL",
+			},
+		},
+		{
+			name: "CVSS v3.0",
+			sev: models.Severity{
+				Type:  models.SeverityCVSSV3,
+				Score: "CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H",
+			},
+			env: env,
+			want: result{
+				score:  7.8,
+				rating: "HIGH",
+			},
+		},
+		{
+			name: "CVSS v3.1",
+			sev: models.Severity{
+				Type:  models.SeverityCVSSV3,
+				Score: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H",
+			},
+			env: env,
+			want: result{
+				score:  7.8,
+				rating: "HIGH",
+			},
+		},
+		{
+			name: "CVSS v3.1 without metrics",
+			sev: models.Severity{
+				Type:  models.SeverityCVSSV3,
+				Score: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H",
+			},
+			env: severity.Environment{CVSSV4: env.CVSSV4},
+			want: result{
+				score:  9.8,
+				rating: "CRITICAL",
+			},
+		},
+		{
+			name: "CVSS v4.0",
+			sev: models.Severity{
+				Type:  models.SeverityCVSSV4,
+				Score: "CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N",
+			},
+			env: env,
+			want: result{
+				score:  8.5,
+				rating: "HIGH",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			gotScore, gotRating, err := severity.CalculateEnvironmentalScore(tt.sev, tt.env)
+			if err != nil {
+				t.Errorf("CalculateEnvironmentalScore() error: %v", err)
+			}
+			if math.Round(10*gotScore) != math.Round(10*tt.want.score) || gotRating != tt.want.rating {
+				t.Errorf("CalculateEnvironmentalScore() = (%.1f, %s), want (%.1f, %s)", gotScore, gotRating, tt.want.score, tt.want.rating)
+			}
+		})
+	}
+}
+
+func TestEnvironment_Validate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		env     severity.Environment
+		wantErr bool
+	}{
+		{name: "empty", env: severity.Environment{}},
+		{name: "valid", env: severity.Environment{CVSSV3: "MAV:L/MS:C/CR:H", CVSSV4: "MAT:P/MSC:H/AR:L"}},
+		{name: "base metric", env: severity.Environment{CVSSV3: "AV:L"}, wantErr: true},
+		{name: "metric of another version", env: severity.Environment{CVSSV4: "MS:C"}, wantErr: true},
+		{name: "invalid value", env: severity.Environment{CVSSV3: "MAV:Z"}, wantErr: true},
+		{name: "not a metric", env: severity.Environment{CVSSV4: "MAV"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if err := tt.env.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
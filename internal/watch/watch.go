@@ -0,0 +1,309 @@
+// Package watch reports changes to a set of files, such as the lockfiles
+// that have been scanned, so that they can be scanned again.
+//
+// The directories containing the files are watched rather than the files
+// themselves, as editors commonly save files by replacing them.
+package watch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// DefaultDebounce is how long a Watcher waits for files to stop changing
+// by default, so that a save which writes a file several times (or several
+// files at once) is reported as a single change
+const DefaultDebounce = 200 * time.Millisecond
+
+// ErrClosed is returned when waiting for changes on a closed Watcher
+var ErrClosed = errors.New("watcher is closed")
+
+// Watcher reports changes to a set of files
+type Watcher struct {
+	debounce time.Duration
+	fsnotify *fsnotify.Watcher
+	ready    chan struct{}
+	done     chan struct{}
+
+	mu      sync.Mutex
+	dirs    []string
+	files   map[string]bool
+	pending map[string]bool
+	changed map[string]bool
+	timer   *time.Timer
+	err     error
+	closed  bool
+
+	// created are the directories that new files are looked for in, which
+	// are reported if createdMatch returns true for them
+	created      []string
+	createdMatch func(path string) bool
+}
+
+// New creates a Watcher that reports files as having changed once they have
+// not changed again for the debounce duration
+func New(debounce time.Duration) (*Watcher, error) {
+	fw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("could not create watcher: %w", err)
+	}
+
+	w := &Watcher{
+		debounce: debounce,
+		fsnotify: fw,
+		ready:    make(chan struct{}, 1),
+		done:     make(chan struct{}),
+		files:    make(map[string]bool),
+		pending:  make(map[string]bool),
+		changed:  make(map[string]bool),
+	}
+
+	go w.read()
+
+	return w, nil
+}
+
+// WatchCreated sets the directories that new files are reported for when
+// they are created, if match returns true for them, along with the files
+// being watched; this takes effect on the next call to Watch
+func (w *Watcher) WatchCreated(dirs []string, match func(path string) bool) error {
+	created := make([]string, 0, len(dirs))
+
+	for _, dir := range dirs {
+		dir, err := filepath.Abs(dir)
+		if err != nil {
+			return err
+		}
+
+		created = append(created, dir)
+	}
+
+	w.mu.Lock()
+	w.created = created
+	w.createdMatch = match
+	w.mu.Unlock()
+
+	return nil
+}
+
+// Watch replaces the files being watched, which do not need to exist
+func (w *Watcher) Watch(files []string) error {
+	watched := make(map[string]bool, len(files))
+
+	w.mu.Lock()
+	dirs := slices.Clone(w.created)
+	w.mu.Unlock()
+
+	for _, file := range files {
+		file, err := filepath.Abs(file)
+		if err != nil {
+			return err
+		}
+
+		watched[file] = true
+
+		if dir := filepath.Dir(file); !slices.Contains(dirs, dir) {
+			dirs = append(dirs, dir)
+		}
+	}
+
+	w.mu.Lock()
+	w.files = watched
+	previous := w.dirs
+	w.mu.Unlock()
+
+	for _, dir := range previous {
+		if !slices.Contains(dirs, dir) {
+			// the watch might have already been removed along with the directory
+			_ = w.fsnotify.Remove(dir)
+		}
+	}
+
+	added := make([]string, 0, len(dirs))
+
+	for _, dir := range dirs {
+		if err := w.fsnotify.Add(dir); err != nil {
+			// files within directories that do not exist cannot change
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+
+			return fmt.Errorf("could not watch %s: %w", dir, err)
+		}
+
+		added = append(added, dir)
+	}
+
+	w.mu.Lock()
+	w.dirs = added
+	w.mu.Unlock()
+
+	return nil
+}
+
+// Next waits for at least one of the watched files to change, returning the
+// paths of every file that has changed since the previous call
+func (w *Watcher) Next(ctx context.Context) ([]string, error) {
+	for {
+		w.mu.Lock()
+		if w.err != nil {
+			err := w.err
+			w.mu.Unlock()
+
+			return nil, err
+		}
+
+		if len(w.changed) > 0 {
+			changed := make([]string, 0, len(w.changed))
+			for file := range w.changed {
+				changed = append(changed, file)
+			}
+			clear(w.changed)
+			w.mu.Unlock()
+
+			slices.Sort(changed)
+
+			return changed, nil
+		}
+		w.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-w.done:
+			return nil, ErrClosed
+		case <-w.ready:
+		}
+	}
+}
+
+// Close stops watching for changes
+func (w *Watcher) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return nil
+	}
+
+	w.closed = true
+	close(w.done)
+
+	if w.timer != nil {
+		w.timer.Stop()
+	}
+
+	return w.fsnotify.Close()
+}
+
+// read handles the events of the watched directories until the watcher is closed
+func (w *Watcher) read() {
+	for {
+		select {
+		case event, ok := <-w.fsnotify.Events:
+			if !ok {
+				return
+			}
+
+			// the contents of files are not changed by their permissions changing
+			if event.Has(fsnotify.Chmod) && !event.Has(fsnotify.Write) {
+				continue
+			}
+
+			w.notify(event.Name, event.Has(fsnotify.Create))
+		case err, ok := <-w.fsnotify.Errors:
+			if !ok {
+				return
+			}
+
+			if errors.Is(err, fsnotify.ErrEventOverflow) {
+				w.notifyAll()
+
+				continue
+			}
+
+			w.fail(fmt.Errorf("could not read file events: %w", err))
+		}
+	}
+}
+
+// notify records that the file at the given path might have changed, which
+// is ignored if the file is not being watched, unless it was just created
+// in one of the directories that new files are looked for in
+func (w *Watcher) notify(path string, created bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed || (!w.files[path] && !(created && w.isWatchedCreation(path))) {
+		return
+	}
+
+	w.pending[path] = true
+
+	if w.timer == nil {
+		w.timer = time.AfterFunc(w.debounce, w.flush)
+	} else {
+		w.timer.Reset(w.debounce)
+	}
+}
+
+// notifyAll records that every watched file might have changed, for when
+// too many events happened at once to know which ones did
+func (w *Watcher) notifyAll() {
+	w.mu.Lock()
+	files := make([]string, 0, len(w.files))
+	for file := range w.files {
+		files = append(files, file)
+	}
+	w.mu.Unlock()
+
+	for _, file := range files {
+		w.notify(file, false)
+	}
+}
+
+// isWatchedCreation reports if a file that has been created at the given path
+// should be reported, which must be called while holding the lock
+func (w *Watcher) isWatchedCreation(path string) bool {
+	return w.createdMatch != nil && slices.Contains(w.created, filepath.Dir(path)) && w.createdMatch(path)
+}
+
+// fail stops the watcher from reporting any further changes
+func (w *Watcher) fail(err error) {
+	w.mu.Lock()
+	if w.err == nil {
+		w.err = err
+	}
+	w.mu.Unlock()
+
+	w.signal()
+}
+
+// flush moves the pending changes to those ready to be returned by Next,
+// as the files have stopped changing
+func (w *Watcher) flush() {
+	w.mu.Lock()
+	for file := range w.pending {
+		w.changed[file] = true
+	}
+	clear(w.pending)
+	w.timer = nil
+	w.mu.Unlock()
+
+	w.signal()
+}
+
+func (w *Watcher) signal() {
+	select {
+	case w.ready <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,163 @@
+package watch_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/osv-scanner/internal/watch"
+)
+
+func newWatcher(t *testing.T, files ...string) *watch.Watcher {
+	t.Helper()
+
+	w, err := watch.New(50 * time.Millisecond)
+	if err != nil {
+		t.Fatalf("could not create watcher: %v", err)
+	}
+	t.Cleanup(func() { _ = w.Close() })
+
+	if err := w.Watch(files); err != nil {
+		t.Fatalf("could not watch files: %v", err)
+	}
+
+	return w
+}
+
+func next(t *testing.T, w *watch.Watcher) []string {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	changed, err := w.Next(ctx)
+	if err != nil {
+		t.Fatalf("Next() error = %v", err)
+	}
+
+	return changed
+}
+
+func writeFile(t *testing.T, path string, content string) {
+	t.Helper()
+
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("could not write %s: %v", path, err)
+	}
+}
+
+func TestWatcher(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	lockfile := filepath.Join(dir, "package-lock.json")
+	manifest := filepath.Join(dir, "package.json")
+	unwatched := filepath.Join(dir, "README.md")
+
+	writeFile(t, lockfile, "{}")
+
+	w := newWatcher(t, lockfile, manifest)
+
+	// changes to files that are not being watched are ignored
+	writeFile(t, unwatched, "# hello world")
+	// several changes in quick succession are reported together
+	writeFile(t, lockfile, `{"lockfileVersion": 3}`)
+	writeFile(t, manifest, "{}")
+
+	if diff := cmp.Diff([]string{lockfile, manifest}, next(t, w)); diff != "" {
+		t.Errorf("Next() mismatch (-want +got):\n%s", diff)
+	}
+
+	// files that are replaced, like when saved by many editors, are still watched
+	replacement := filepath.Join(dir, "package-lock.json.tmp")
+	writeFile(t, replacement, `{"lockfileVersion": 2}`)
+	if err := os.Rename(replacement, lockfile); err != nil {
+		t.Fatalf("could not replace lockfile: %v", err)
+	}
+
+	if diff := cmp.Diff([]string{lockfile}, next(t, w)); diff != "" {
+		t.Errorf("Next() mismatch (-want +got):\n%s", diff)
+	}
+
+	if err := os.Remove(manifest); err != nil {
+		t.Fatalf("could not remove manifest: %v", err)
+	}
+
+	if diff := cmp.Diff([]string{manifest}, next(t, w)); diff != "" {
+		t.Errorf("Next() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestWatcher_Watch(t *testing.T) {
+	t.Parallel()
+
+	dirA, dirB := t.TempDir(), t.TempDir()
+	fileA := filepath.Join(dirA, "go.mod")
+	fileB := filepath.Join(dirB, "go.mod")
+
+	w := newWatcher(t, fileA)
+
+	// files that are no longer being watched are ignored
+	if err := w.Watch([]string{fileB}); err != nil {
+		t.Fatalf("could not watch files: %v", err)
+	}
+
+	writeFile(t, fileA, "module a")
+	writeFile(t, fileB, "module b")
+
+	if diff := cmp.Diff([]string{fileB}, next(t, w)); diff != "" {
+		t.Errorf("Next() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestWatcher_WatchCreated(t *testing.T) {
+	t.Parallel()
+
+	dir, other := t.TempDir(), t.TempDir()
+
+	w := newWatcher(t)
+
+	err := w.WatchCreated([]string{dir}, func(path string) bool {
+		return filepath.Ext(path) == ".lock"
+	})
+	if err != nil {
+		t.Fatalf("could not watch for created files: %v", err)
+	}
+
+	if err := w.Watch(nil); err != nil {
+		t.Fatalf("could not watch files: %v", err)
+	}
+
+	// only new files that match in the given directories are reported
+	writeFile(t, filepath.Join(dir, "README.md"), "# hello world")
+	writeFile(t, filepath.Join(other, "poetry.lock"), "")
+	writeFile(t, filepath.Join(dir, "poetry.lock"), "")
+
+	if diff := cmp.Diff([]string{filepath.Join(dir, "poetry.lock")}, next(t, w)); diff != "" {
+		t.Errorf("Next() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestWatcher_Next_Cancelled(t *testing.T) {
+	t.Parallel()
+
+	w := newWatcher(t, filepath.Join(t.TempDir(), "go.mod"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := w.Next(ctx); err != context.Canceled {
+		t.Errorf("Next() error = %v, want %v", err, context.Canceled)
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	if _, err := w.Next(context.Background()); err != watch.ErrClosed {
+		t.Errorf("Next() error = %v, want %v", err, watch.ErrClosed)
+	}
+}
@@ -2,12 +2,19 @@ package config
 
 import (
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"slices"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
-	"github.com/BurntSushi/toml"
+	"github.com/google/osv-scanner/internal/pathfilter"
+	"github.com/google/osv-scanner/internal/semantic"
+	"github.com/google/osv-scanner/internal/utility/fspath"
+	"github.com/google/osv-scanner/pkg/models"
 	"github.com/google/osv-scanner/pkg/reporter"
 )
 
@@ -20,44 +27,424 @@ type ConfigManager struct {
 	DefaultConfig Config
 	// Cache to store loaded configs
 	ConfigMap map[string]Config
+	// FS is the filesystem that config files are loaded from when set,
+	// instead of the local filesystem
+	FS fs.FS
+
+	// guards ConfigMap, so that configs can be loaded by concurrent scans
+	mu sync.Mutex
+	// warned are the problems with configs that have already been reported
+	warned map[LintIssue]bool
+
+	usage     *ignoreUsage
+	usageOnce sync.Once
 }
 
 type Config struct {
-	IgnoredVulns      []IgnoreEntry          `toml:"IgnoredVulns"`
-	PackageOverrides  []PackageOverrideEntry `toml:"PackageOverrides"`
-	LoadPath          string                 `toml:"LoadPath"`
-	GoVersionOverride string                 `toml:"GoVersionOverride"`
+	IgnoredVulns      []IgnoreEntry          `toml:"IgnoredVulns,omitempty"`
+	PackageOverrides  []PackageOverrideEntry `toml:"PackageOverrides,omitempty"`
+	LoadPath          string                 `toml:"LoadPath,omitempty"`
+	GoVersionOverride string                 `toml:"GoVersionOverride,omitempty"`
+	API               APIConfig              `toml:"API,omitempty"`
+	PathFilters       []PathFilterEntry      `toml:"PathFilters,omitempty"`
+	FailOn            FailOnConfig           `toml:"FailOn,omitempty"`
+	Policies          []PolicyEntry          `toml:"Policies,omitempty"`
+	Licenses          LicenseConfig          `toml:"Licenses,omitempty"`
+	CVSSEnvironments  []CVSSEnvironmentEntry `toml:"CVSSEnvironments,omitempty"`
+	// Extends are the paths of other config files (relative to this one)
+	// whose entries are added after those of this config
+	Extends []string `toml:"Extends,omitempty"`
+	// Inherit adds the entries of the nearest config file in a parent
+	// directory after those of this config (and any that it extends)
+	Inherit bool `toml:"Inherit,omitempty"`
+
+	// sources are the config files that the config was loaded from
+	sources       []string
+	ignoreSources []entrySource
+	// ignorePaths are the compiled path globs of each of the ignore entries
+	ignorePaths   [][]pathfilter.Pattern
+	policySources []entrySource
+	// environmentSources are where the CVSS environments were loaded from
+	environmentSources []entrySource
+	// usage records which ignore entries have matched a vulnerability,
+	// if the config was loaded from a file
+	usage *ignoreUsage
+}
+
+// LicenseConfig is the policy that the licenses of the packages from the
+// sources that the config applies to are checked against, unless licenses
+// are allowed or denied with flags
+type LicenseConfig struct {
+	// Allow is the licenses that can be used, which allows every license that
+	// is not denied if it is empty
+	Allow []string `toml:"allow,omitempty"`
+	// Deny is the licenses that cannot be used, even if they are allowed
+	Deny []string `toml:"deny,omitempty"`
+	// Exceptions are packages that can use licenses that the policy does not allow
+	Exceptions []LicenseException `toml:"exceptions,omitempty"`
+}
+
+// HasPolicy reports if any licenses are allowed or denied
+func (l LicenseConfig) HasPolicy() bool {
+	return len(l.Allow) > 0 || len(l.Deny) > 0
+}
+
+// LicenseException lets a package use licenses that the license policy does
+// not allow, such as a tool that is only used internally
+type LicenseException struct {
+	Package string `toml:"package,omitempty"`
+	// Ecosystem is the ecosystem of the package, which is any if empty
+	Ecosystem string `toml:"ecosystem,omitempty"`
+	// Versions is a range of versions of the package, as comma separated
+	// constraints (i.e. ">= 1.0.0, < 2.0.0"), which is every version if empty
+	Versions string `toml:"versions,omitempty"`
+	// Licenses are the licenses that the package can use regardless of the
+	// policy, which is any license if empty
+	Licenses       []string  `toml:"licenses,omitempty"`
+	EffectiveUntil time.Time `toml:"effectiveUntil,omitempty"`
+	Reason         string    `toml:"reason,omitempty"`
+}
+
+// CVSSEnvironmentEntry gives the environmental metrics that the CVSS
+// vectors of vulnerabilities are scored with when they affect packages that
+// match all of its conditions, with conditions that are not set matching
+// everything. Only the first entry that matches a package applies to it.
+type CVSSEnvironmentEntry struct {
+	Reason string `toml:"reason,omitempty"`
+
+	Ecosystems []string `toml:"ecosystems,omitempty"`
+	// Paths are globs of the source files, relative to the directory of the config file
+	Paths []string `toml:"paths,omitempty"`
+
+	// CVSSV3 are the environmental metrics of CVSS v3.0 and v3.1 vectors (i.e. "MAV:A/CR:L")
+	CVSSV3 string `toml:"cvssV3,omitempty"`
+	// CVSSV4 are the environmental metrics of CVSS v4.0 vectors
+	CVSSV4 string `toml:"cvssV4,omitempty"`
+}
+
+// PolicyEntry decides what happens to the vulnerabilities that match all of
+// its conditions, with conditions that are not set matching everything.
+// Policies are evaluated in order, with only the first one that matches applying.
+type PolicyEntry struct {
+	// Action is one of "fail", "warn" or "ignore"
+	Action string `toml:"action,omitempty"`
+	Reason string `toml:"reason,omitempty"`
+
+	Ecosystems []string `toml:"ecosystems,omitempty"`
+	// Packages are the names of packages, which can include path.Match wildcards
+	Packages []string `toml:"packages,omitempty"`
+	// Severities are the ratings of the highest severity of the vulnerabilities,
+	// one of "none", "low", "medium", "high", "critical" or "unknown"
+	Severities []string `toml:"severities,omitempty"`
+	// Dev matches packages that are only development dependencies (or not)
+	Dev *bool `toml:"dev,omitempty"`
+	// Direct matches packages that are direct dependencies (or not)
+	Direct *bool `toml:"direct,omitempty"`
+	// Fixable matches vulnerabilities that have been fixed in a version of the package (or not)
+	Fixable *bool `toml:"fixable,omitempty"`
+	// OlderThan matches vulnerabilities that were published at least this long ago
+	OlderThan Age `toml:"olderThan,omitempty,omitzero"`
+	// Paths are globs of the source files, relative to the directory of the config file
+	Paths []string `toml:"paths,omitempty"`
+}
+
+// Age is a length of time that can also be given in days, weeks or years
+// (i.e. "90d", "2w" or "2y"), with a year being 365 days
+type Age time.Duration
+
+func (a Age) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(a).String()), nil
+}
+
+func (a *Age) UnmarshalText(text []byte) error {
+	s := string(text)
+
+	units := map[string]time.Duration{
+		"d": 24 * time.Hour,
+		"w": 7 * 24 * time.Hour,
+		"y": 365 * 24 * time.Hour,
+	}
+
+	for suffix, unit := range units {
+		if n, ok := strings.CutSuffix(s, suffix); ok {
+			count, err := strconv.Atoi(n)
+			if err != nil || count < 0 {
+				return fmt.Errorf("invalid age %q", s)
+			}
+
+			*a = Age(time.Duration(count) * unit)
+
+			return nil
+		}
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return fmt.Errorf("invalid age %q: %w", s, err)
+	}
+
+	*a = Age(d)
+
+	return nil
+}
+
+// FailOnConfig describes which called vulnerabilities cause a scan to fail,
+// with vulnerabilities that do not still being reported.
+type FailOnConfig struct {
+	// Severity is the lowest severity that fails the scan, either a rating
+	// (i.e. "high") or a CVSS score (i.e. "7.0")
+	Severity string `toml:"severity,omitempty"`
+	// UnknownSeverity is whether vulnerabilities without a known severity
+	// fail the scan ("fail") or not ("ignore") when Severity is set
+	UnknownSeverity string `toml:"unknownSeverity,omitempty"`
+}
+
+// PathFilterEntry describes paths to include in or exclude from directory
+// scans, as globs relative to the directory being scanned.
+type PathFilterEntry struct {
+	Include []string `toml:"include,omitempty"`
+	Exclude []string `toml:"exclude,omitempty"`
+	// If set, the entry only applies to files parsed with one of these parsers
+	Parsers []string `toml:"parsers,omitempty"`
+	Reason  string   `toml:"reason,omitempty"`
+}
+
+// APIConfig describes how to connect to the OSV API, for when requests
+// need to go through a proxy or an OSV-compatible service.
+// It is only read from the config file that is passed explicitly, as the
+// configs that are found alongside scanned files belong to what is scanned.
+type APIConfig struct {
+	BaseURL string `toml:"baseURL,omitempty"`
+	// Header values are sent as they are, without expanding environment variables
+	Headers               map[string]string `toml:"headers,omitempty"`
+	CACertFile            string            `toml:"caCertFile,omitempty"`
+	Proxy                 string            `toml:"proxy,omitempty"`
+	Timeout               time.Duration     `toml:"timeout,omitempty,omitzero"`
+	MaxRetryAttempts      int               `toml:"maxRetryAttempts,omitempty,omitzero"`
+	MaxConcurrentRequests int               `toml:"maxConcurrentRequests,omitempty,omitzero"`
 }
 
 type IgnoreEntry struct {
-	ID          string    `toml:"id"`
-	IgnoreUntil time.Time `toml:"ignoreUntil"`
-	Reason      string    `toml:"reason"`
+	ID          string    `toml:"id,omitempty"`
+	IgnoreUntil time.Time `toml:"ignoreUntil,omitempty"`
+	Reason      string    `toml:"reason,omitempty"`
+
+	// If any of these are set, the entry only applies to the vulnerability
+	// where it is found in a package and source file that match all of them
+	Package   string `toml:"package,omitempty"`
+	Ecosystem string `toml:"ecosystem,omitempty"`
+	// Versions is a range of versions of the package, as comma separated
+	// constraints (i.e. ">= 1.0.0, < 2.0.0")
+	Versions string `toml:"versions,omitempty"`
+	// Paths are globs of the source files, relative to the directory of the config file
+	Paths []string `toml:"paths,omitempty"`
+}
+
+// IgnoreTarget is where a vulnerability was found, which scoped ignore
+// entries are matched against
+type IgnoreTarget struct {
+	Name      string
+	Version   string
+	Ecosystem string
+	// SourcePath is the path of the source file that the package is from
+	SourcePath string
+}
+
+// IsScoped reports if the entry only applies to some of the places that the
+// vulnerability is found
+func (e IgnoreEntry) IsScoped() bool {
+	return e.Package != "" || e.Ecosystem != "" || e.Versions != "" || len(e.Paths) > 0
+}
+
+// compileIgnorePaths compiles the path globs of each of the ignore entries,
+// skipping those that are invalid as they cannot match anything
+func compileIgnorePaths(entries []IgnoreEntry) [][]pathfilter.Pattern {
+	compiled := make([][]pathfilter.Pattern, len(entries))
+
+	for i, entry := range entries {
+		for _, glob := range entry.Paths {
+			if pattern, err := pathfilter.Compile(glob); err == nil {
+				compiled[i] = append(compiled[i], pattern)
+			}
+		}
+	}
+
+	return compiled
+}
+
+// appliesTo reports if the entry applies to vulnerabilities found in the
+// target, with paths being relative to the config file that it is from and
+// matched against the compiled globs of the entry
+func (e IgnoreEntry) appliesTo(source entrySource, paths []pathfilter.Pattern, target IgnoreTarget) bool {
+	if e.Package != "" && e.Package != target.Name {
+		return false
+	}
+
+	if e.Ecosystem != "" {
+		ecosystem, _, _ := strings.Cut(target.Ecosystem, ":")
+
+		if !strings.EqualFold(e.Ecosystem, target.Ecosystem) && !strings.EqualFold(e.Ecosystem, ecosystem) {
+			return false
+		}
+	}
+
+	if e.Versions != "" && (target.Version == "" || !versionInRange(target.Version, target.Ecosystem, e.Versions)) {
+		return false
+	}
+
+	if len(e.Paths) > 0 {
+		rel, ok := relativePath(source.path, target.SourcePath)
+		if !ok {
+			return false
+		}
+
+		return slices.ContainsFunc(paths, func(pattern pathfilter.Pattern) bool { return pattern.Match(rel) })
+	}
+
+	return true
+}
+
+// versionInRange reports if the version meets all of the comma separated
+// constraints, which are compared using the ordering of the ecosystem
+func versionInRange(version, ecosystem, constraints string) bool {
+	v, err := semantic.Parse(version, models.Ecosystem(ecosystem))
+	if err != nil {
+		return false
+	}
+
+	for _, constraint := range strings.Split(constraints, ",") {
+		op, bound := splitConstraint(constraint)
+		if bound == "" {
+			return false
+		}
+
+		cmp := v.CompareStr(bound)
+
+		var ok bool
+		switch op {
+		case "", "=", "==":
+			ok = cmp == 0
+		case "!=":
+			ok = cmp != 0
+		case "<":
+			ok = cmp < 0
+		case "<=":
+			ok = cmp <= 0
+		case ">":
+			ok = cmp > 0
+		case ">=":
+			ok = cmp >= 0
+		}
+
+		if !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ValidVersionRange reports if the range of versions is made up of
+// constraints that can be evaluated
+func ValidVersionRange(constraints string) bool {
+	for _, constraint := range strings.Split(constraints, ",") {
+		op, bound := splitConstraint(constraint)
+
+		if !slices.Contains(versionOperators, op) || bound == "" {
+			return false
+		}
+	}
+
+	return true
+}
+
+var versionOperators = []string{"", "=", "==", "!=", "<", "<=", ">", ">="}
+
+// splitConstraint splits a version constraint (i.e. ">= 1.0.0") into its
+// operator and the version that it is bounded by
+func splitConstraint(constraint string) (string, string) {
+	constraint = strings.TrimSpace(constraint)
+	bound := strings.TrimLeft(constraint, "<>=!")
+
+	return constraint[:len(constraint)-len(bound)], strings.TrimSpace(bound)
+}
+
+// RelativePath returns the target path relative to the directory of the
+// config file (or the working directory if it was not loaded from one),
+// if it is within it
+func (c *Config) RelativePath(target string) (string, bool) {
+	return relativePath(c.LoadPath, target)
+}
+
+func relativePath(loadPath string, target string) (string, bool) {
+	if target == "" {
+		return "", false
+	}
+
+	dir := "."
+	if loadPath != "" {
+		dir = filepath.Dir(loadPath)
+	}
+
+	absTarget, err := filepath.Abs(target)
+	if err != nil {
+		return "", false
+	}
+
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", false
+	}
+
+	rel, err := filepath.Rel(absDir, absTarget)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+
+	return filepath.ToSlash(rel), true
 }
 
 type PackageOverrideEntry struct {
-	Name string `toml:"name"`
+	Name string `toml:"name,omitempty"`
 	// If the version is empty, the entry applies to all versions.
-	Version        string    `toml:"version"`
-	Ecosystem      string    `toml:"ecosystem"`
-	Ignore         bool      `toml:"ignore"`
-	License        License   `toml:"license"`
-	EffectiveUntil time.Time `toml:"effectiveUntil"`
-	Reason         string    `toml:"reason"`
+	Version        string    `toml:"version,omitempty"`
+	Ecosystem      string    `toml:"ecosystem,omitempty"`
+	Ignore         bool      `toml:"ignore,omitempty"`
+	License        License   `toml:"license,omitempty"`
+	EffectiveUntil time.Time `toml:"effectiveUntil,omitempty"`
+	Reason         string    `toml:"reason,omitempty"`
 }
 
 type License struct {
-	Override []string `toml:"override"`
+	Override []string `toml:"override,omitempty"`
 }
 
+// ShouldIgnore checks if the vulnerability is ignored wherever it is found,
+// which does not include ignore entries that are scoped
 func (c *Config) ShouldIgnore(vulnID string) (bool, IgnoreEntry) {
-	index := slices.IndexFunc(c.IgnoredVulns, func(e IgnoreEntry) bool { return e.ID == vulnID })
-	if index == -1 {
-		return false, IgnoreEntry{}
+	return c.ShouldIgnoreIn(vulnID, IgnoreTarget{})
+}
+
+// ShouldIgnoreIn checks if the vulnerability is ignored when it is found in
+// the target, including by ignore entries that are scoped to it
+func (c *Config) ShouldIgnoreIn(vulnID string, target IgnoreTarget) (bool, IgnoreEntry) {
+	paths := c.ignorePaths
+	if len(paths) != len(c.IgnoredVulns) {
+		// the config was not loaded from a file, so its globs are not compiled yet
+		paths = compileIgnorePaths(c.IgnoredVulns)
 	}
-	ignoredLine := c.IgnoredVulns[index]
 
-	return shouldIgnoreTimestamp(ignoredLine.IgnoreUntil), ignoredLine
+	for i, entry := range c.IgnoredVulns {
+		source := c.ignoreSource(i)
+
+		if entry.ID == vulnID && entry.appliesTo(source, paths[i], target) {
+			c.usage.mark(source)
+
+			return shouldIgnoreTimestamp(entry.IgnoreUntil), entry
+		}
+	}
+
+	return false, IgnoreEntry{}
 }
 
 func (c *Config) filterPackageVersionEntries(name string, version string, ecosystem string, condition func(PackageOverrideEntry) bool) (bool, PackageOverrideEntry) {
@@ -88,6 +475,29 @@ func (c *Config) ShouldOverridePackageVersionLicense(name, version, ecosystem st
 	})
 }
 
+// ShouldExemptPackageLicense checks if the package can use licenses that the
+// license policy does not allow, returning the first exception that applies;
+// ecosystems are compared without their release (i.e. the "10" of "Debian:10")
+func (c *Config) ShouldExemptPackageLicense(name, version, ecosystem string) (bool, LicenseException) {
+	base, _, _ := strings.Cut(ecosystem, ":")
+
+	for _, exception := range c.Licenses.Exceptions {
+		exceptionBase, _, _ := strings.Cut(exception.Ecosystem, ":")
+
+		if exception.Package != name || (exception.Ecosystem != "" && !strings.EqualFold(exceptionBase, base)) {
+			continue
+		}
+
+		if exception.Versions != "" && (version == "" || !versionInRange(version, ecosystem, exception.Versions)) {
+			continue
+		}
+
+		return shouldIgnoreTimestamp(exception.EffectiveUntil), exception
+	}
+
+	return false, LicenseException{}
+}
+
 func shouldIgnoreTimestamp(ignoreUntil time.Time) bool {
 	if ignoreUntil.IsZero() {
 		// If IgnoreUntil is not set, should ignore.
@@ -101,12 +511,10 @@ func shouldIgnoreTimestamp(ignoreUntil time.Time) bool {
 // Sets the override config by reading the config file at configPath.
 // Will return an error if loading the config file fails
 func (c *ConfigManager) UseOverride(configPath string) error {
-	config := Config{}
-	_, err := toml.DecodeFile(configPath, &config)
+	config, err := c.loadConfig(configPath, true)
 	if err != nil {
 		return err
 	}
-	config.LoadPath = configPath
 	c.OverrideConfig = &config
 
 	return nil
@@ -115,10 +523,15 @@ func (c *ConfigManager) UseOverride(configPath string) error {
 // Attempts to get the config
 func (c *ConfigManager) Get(r reporter.Reporter, targetPath string) Config {
 	if c.OverrideConfig != nil {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		c.warnIgnoreScopes(r, *c.OverrideConfig)
+
 		return *c.OverrideConfig
 	}
 
-	configPath, err := normalizeConfigLoadPath(targetPath)
+	configPath, err := c.normalizeConfigLoadPath(targetPath)
 	if err != nil {
 		// TODO: This can happen when target is not a file (e.g. Docker container, git hash...etc.)
 		// Figure out a more robust way to load config from non files
@@ -126,26 +539,106 @@ func (c *ConfigManager) Get(r reporter.Reporter, targetPath string) Config {
 		return Config{}
 	}
 
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	config, alreadyExists := c.ConfigMap[configPath]
 	if alreadyExists {
 		return config
 	}
 
-	config, configErr := tryLoadConfig(configPath)
+	config, configErr := c.tryLoadConfig(configPath)
 	if configErr == nil {
 		r.Infof("Loaded filter from: %s\n", config.LoadPath)
+		if sources := config.Sources(); len(sources) > 1 {
+			r.Verbosef("Effective config for %s, merged from %s:\n%s\n", filepath.Dir(configPath), strings.Join(sources, ", "), config)
+		}
+		c.warnIgnoreScopes(r, config)
 	} else {
 		// If config doesn't exist, use the default config
 		config = c.DefaultConfig
 	}
+	if c.ConfigMap == nil {
+		c.ConfigMap = make(map[string]Config)
+	}
 	c.ConfigMap[configPath] = config
 
 	return config
 }
 
+// GetAPIConfig returns the API settings for the scan, which are global
+// rather than per source: they are only taken from the override config, and
+// never from configs that are found alongside the scanned files, as those
+// could otherwise send requests (and their headers) to any server.
+func (c *ConfigManager) GetAPIConfig() (APIConfig, string) {
+	if c.OverrideConfig == nil {
+		return APIConfig{}, ""
+	}
+
+	return c.OverrideConfig.API, c.OverrideConfig.LoadPath
+}
+
+// GetFailOnConfig returns the failure thresholds for the scan, which are
+// global and taken from the override config if there is one, otherwise from
+// the config in the given directory (if any).
+func (c *ConfigManager) GetFailOnConfig(dir string) (FailOnConfig, string) {
+	if c.OverrideConfig != nil {
+		return c.OverrideConfig.FailOn, c.OverrideConfig.LoadPath
+	}
+
+	config, err := c.tryLoadConfig(filepath.Join(dir, osvScannerConfigName))
+	if err != nil {
+		return FailOnConfig{}, ""
+	}
+
+	return config.FailOn, config.LoadPath
+}
+
+// GetPathFilters returns the path filters for scanning the given directory,
+// from the override config if there is one or otherwise the config in the
+// directory (if any), without reporting that the config has been loaded as
+// that happens when the config is used for the results.
+func (c *ConfigManager) GetPathFilters(dir string) ([]PathFilterEntry, string) {
+	if c.OverrideConfig != nil {
+		return c.OverrideConfig.PathFilters, c.OverrideConfig.LoadPath
+	}
+
+	configPath, err := c.normalizeConfigLoadPath(dir)
+	if err != nil {
+		return nil, ""
+	}
+
+	config, err := c.tryLoadConfig(configPath)
+	if err != nil {
+		return c.DefaultConfig.PathFilters, ""
+	}
+
+	return config.PathFilters, config.LoadPath
+}
+
+// stat returns the FileInfo of the file at the given path, from the
+// configured filesystem if there is one
+func (c *ConfigManager) stat(target string) (fs.FileInfo, error) {
+	if c.FS == nil {
+		return os.Stat(target)
+	}
+
+	return fs.Stat(c.FS, fspath.Name(target))
+}
+
+// open opens the file at the given path, from the configured
+// filesystem if there is one
+func (c *ConfigManager) open(target string) (fs.File, error) {
+	if c.FS == nil {
+		return os.Open(target)
+	}
+
+	return c.FS.Open(fspath.Name(target))
+}
+
 // Finds the containing folder of `target`, then appends osvScannerConfigName
-func normalizeConfigLoadPath(target string) (string, error) {
-	stat, err := os.Stat(target)
+func (c *ConfigManager) normalizeConfigLoadPath(target string) (string, error) {
+	stat, err := c.stat(target)
 	if err != nil {
 		return "", fmt.Errorf("failed to stat target: %w", err)
 	}
@@ -163,20 +656,6 @@ func normalizeConfigLoadPath(target string) (string, error) {
 
 // tryLoadConfig tries to load config in `target` (or it's containing directory)
 // `target` will be the key for the entry in configMap
-func tryLoadConfig(configPath string) (Config, error) {
-	file, err := os.Open(configPath)
-	var config Config
-	if err == nil { // File exists, and we have permission to read
-		defer file.Close()
-
-		_, err := toml.NewDecoder(file).Decode(&config)
-		if err != nil {
-			return Config{}, fmt.Errorf("failed to parse config file: %w", err)
-		}
-		config.LoadPath = configPath
-
-		return config, nil
-	}
-
-	return Config{}, fmt.Errorf("no config file found on this path: %s", configPath)
+func (c *ConfigManager) tryLoadConfig(configPath string) (Config, error) {
+	return c.loadConfig(configPath, false)
 }
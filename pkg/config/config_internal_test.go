@@ -1,12 +1,17 @@
 package config
 
 import (
+	"io"
+	"os"
 	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
+	"testing/fstest"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/osv-scanner/pkg/reporter"
 )
 
 type testStruct struct {
@@ -75,16 +80,18 @@ func TestTryLoadConfig(t *testing.T) {
 		},
 	}
 
+	c := &ConfigManager{}
+
 	for _, testData := range testPaths {
 		absPath, err := filepath.Abs(testData.targetPath)
 		if err != nil {
 			t.Errorf("%s", err)
 		}
-		configPath, err := normalizeConfigLoadPath(absPath)
+		configPath, err := c.normalizeConfigLoadPath(absPath)
 		if err != nil {
 			t.Errorf("%s", err)
 		}
-		config, configErr := tryLoadConfig(configPath)
+		config, configErr := c.tryLoadConfig(configPath)
 		if !cmp.Equal(config.IgnoredVulns, testData.config.IgnoredVulns) {
 			t.Errorf("Configs not equal: %+v != %+v", config, testData.config)
 		}
@@ -99,6 +106,73 @@ func TestTryLoadConfig(t *testing.T) {
 	}
 }
 
+func TestConfigManager_FS(t *testing.T) {
+	t.Parallel()
+
+	c := &ConfigManager{
+		FS: fstest.MapFS{
+			"app/osv-scanner.toml":      {Data: []byte("[[IgnoredVulns]]\nid = \"GHSA-1\"\n")},
+			"app/package-lock.json":     {Data: []byte("{}")},
+			"app/web/package-lock.json": {Data: []byte("{}")},
+			"other/package-lock.json":   {Data: []byte("{}")},
+		},
+	}
+
+	config := c.Get(&reporter.VoidReporter{}, "/app/package-lock.json")
+	if len(config.IgnoredVulns) != 1 || config.IgnoredVulns[0].ID != "GHSA-1" {
+		t.Errorf("expected config to be loaded from the filesystem, got %+v", config)
+	}
+
+	config = c.Get(&reporter.VoidReporter{}, "/other/package-lock.json")
+	if len(config.IgnoredVulns) != 0 {
+		t.Errorf("expected the default config, got %+v", config)
+	}
+
+	// configs in parent directories are only used when inherited
+	config = c.Get(&reporter.VoidReporter{}, "/app/web/package-lock.json")
+	if len(config.IgnoredVulns) != 0 {
+		t.Errorf("expected the default config, got %+v", config)
+	}
+
+	// and the path filters are looked up in the same way
+	if _, loadPath := c.GetPathFilters("/app"); loadPath != "/app/osv-scanner.toml" {
+		t.Errorf("expected the path filters of /app/osv-scanner.toml, got %q", loadPath)
+	}
+
+	if _, loadPath := c.GetPathFilters("/app/web"); loadPath != "" {
+		t.Errorf("expected the default path filters, got %q", loadPath)
+	}
+}
+
+func TestConfigManager_GetFailOnConfig(t *testing.T) {
+	t.Parallel()
+
+	c := &ConfigManager{
+		FS: fstest.MapFS{
+			"osv-scanner.toml": {Data: []byte("[FailOn]\nseverity = \"high\"\nunknownSeverity = \"ignore\"\n")},
+		},
+	}
+
+	got, loadPath := c.GetFailOnConfig(".")
+	want := FailOnConfig{Severity: "high", UnknownSeverity: "ignore"}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("GetFailOnConfig() mismatch (-want +got):\n%s", diff)
+	}
+
+	if loadPath != "osv-scanner.toml" {
+		t.Errorf("GetFailOnConfig() loadPath = %q, want %q", loadPath, "osv-scanner.toml")
+	}
+
+	// the override config is used instead, even if it has no thresholds
+	c.OverrideConfig = &Config{LoadPath: "override.toml"}
+
+	got, loadPath = c.GetFailOnConfig(".")
+	if diff := cmp.Diff(FailOnConfig{}, got); diff != "" || loadPath != "override.toml" {
+		t.Errorf("GetFailOnConfig() = %+v from %q, want the override config", got, loadPath)
+	}
+}
+
 func TestConfig_ShouldIgnore(t *testing.T) {
 	t.Parallel()
 
@@ -213,6 +287,124 @@ func TestConfig_ShouldIgnore(t *testing.T) {
 	}
 }
 
+func TestConfig_ShouldIgnoreIn(t *testing.T) {
+	t.Parallel()
+
+	config := Config{
+		LoadPath: "/app/osv-scanner.toml",
+		IgnoredVulns: []IgnoreEntry{
+			{ID: "GHSA-1", Package: "lodash", Ecosystem: "npm"},
+			{ID: "GHSA-2", Versions: ">= 1.0.0, < 2.0.0"},
+			{ID: "GHSA-3", Paths: []string{"services/api/**"}},
+			{ID: "GHSA-4"},
+		},
+	}
+
+	target := IgnoreTarget{
+		Name:       "lodash",
+		Version:    "1.5.0",
+		Ecosystem:  "npm",
+		SourcePath: "/app/services/api/package-lock.json",
+	}
+
+	tests := []struct {
+		name   string
+		vulnID string
+		target IgnoreTarget
+		want   bool
+	}{
+		{name: "package", vulnID: "GHSA-1", target: target, want: true},
+		{name: "other package", vulnID: "GHSA-1", target: IgnoreTarget{Name: "react", Ecosystem: "npm"}, want: false},
+		{name: "other ecosystem", vulnID: "GHSA-1", target: IgnoreTarget{Name: "lodash", Ecosystem: "PyPI"}, want: false},
+		{name: "within versions", vulnID: "GHSA-2", target: target, want: true},
+		{name: "outside versions", vulnID: "GHSA-2", target: IgnoreTarget{Name: "lodash", Version: "2.0.0", Ecosystem: "npm"}, want: false},
+		{name: "path", vulnID: "GHSA-3", target: target, want: true},
+		{name: "other path", vulnID: "GHSA-3", target: IgnoreTarget{SourcePath: "/app/services/web/package-lock.json"}, want: false},
+		{name: "outside of the config directory", vulnID: "GHSA-3", target: IgnoreTarget{SourcePath: "/other/services/api/package-lock.json"}, want: false},
+		{name: "unscoped", vulnID: "GHSA-4", target: target, want: true},
+		{name: "scoped entries need a target", vulnID: "GHSA-1", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got, _ := config.ShouldIgnoreIn(tt.vulnID, tt.target); got != tt.want {
+				t.Errorf("ShouldIgnoreIn() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfigManager_Get_InvalidIgnoreScopes(t *testing.T) {
+	t.Parallel()
+
+	c := &ConfigManager{
+		FS: fstest.MapFS{
+			"app/osv-scanner.toml": {Data: []byte(`
+[[IgnoredVulns]]
+id = "GHSA-1"
+ecosystem = "nmp"
+
+[[IgnoredVulns]]
+id = "GHSA-2"
+versions = ">> 1.0"
+
+[[IgnoredVulns]]
+id = "GHSA-3"
+paths = ["services/[", "services/api/**"]
+`)},
+			"app/package-lock.json":              {Data: []byte("{}")},
+			"app/services/api/package-lock.json": {Data: []byte("{}")},
+		},
+	}
+
+	var stderr strings.Builder
+	r := reporter.NewJSONReporter(io.Discard, &stderr, reporter.WarnLevel)
+
+	config := c.Get(r, "/app/package-lock.json")
+	c.Get(r, "/app/services/api/package-lock.json")
+
+	// each problem is only reported once, however many times the config is used
+	want := []string{
+		`Warning: /app/osv-scanner.toml IgnoredVulns[0].ecosystem will never apply, as "nmp" is not a known ecosystem (invalid-ecosystem)`,
+		`Warning: /app/osv-scanner.toml IgnoredVulns[1].versions will never apply, as ">> 1.0" is not a valid range of versions, i.e. ">= 1.0.0, < 2.0.0" (invalid-value)`,
+	}
+	got := strings.Split(strings.TrimSpace(stderr.String()), "\n")
+
+	if len(got) != 3 {
+		t.Fatalf("expected three warnings, got %q", got)
+	}
+	if diff := cmp.Diff(want, got[:2]); diff != "" {
+		t.Errorf("Get() warnings mismatch (-want +got):\n%s", diff)
+	}
+	if !strings.HasPrefix(got[2], "Warning: /app/osv-scanner.toml IgnoredVulns[2].paths will never apply") {
+		t.Errorf("expected a warning about the invalid glob, got %q", got[2])
+	}
+
+	// the valid globs of an entry still apply
+	target := IgnoreTarget{SourcePath: "/app/services/api/package-lock.json"}
+	if got, _ := config.ShouldIgnoreIn("GHSA-3", target); !got {
+		t.Errorf("ShouldIgnoreIn() = %v, want %v", got, true)
+	}
+}
+
+func TestValidVersionRange(t *testing.T) {
+	t.Parallel()
+
+	for constraints, want := range map[string]bool{
+		"1.0.0":             true,
+		">= 1.0.0, < 2.0.0": true,
+		"!=1.2.3":           true,
+		"=> 1.0.0":          false,
+		">= 1.0.0, ":        false,
+		"":                  false,
+	} {
+		if got := ValidVersionRange(constraints); got != want {
+			t.Errorf("ValidVersionRange(%q) = %v, want %v", constraints, got, want)
+		}
+	}
+}
+
 func TestConfig_ShouldIgnorePackageVersion(t *testing.T) {
 	t.Parallel()
 
@@ -445,3 +637,208 @@ func TestConfig_ShouldOverridePackageVersionLicense(t *testing.T) {
 		})
 	}
 }
+
+func TestConfigManager_Policies(t *testing.T) {
+	t.Parallel()
+
+	c := &ConfigManager{
+		FS: fstest.MapFS{
+			"osv-scanner.toml": {Data: []byte(`
+[[Policies]]
+action = "fail"
+severities = ["high", "critical"]
+dev = false
+fixable = true
+
+[[Policies]]
+action = "ignore"
+reason = "unfixable and old"
+severities = ["low"]
+fixable = false
+olderThan = "2y"
+`)},
+			"package-lock.json": {Data: []byte("{}")},
+		},
+	}
+
+	yes, no := true, false
+	want := []PolicyEntry{
+		{Action: "fail", Severities: []string{"high", "critical"}, Dev: &no, Fixable: &yes},
+		{Action: "ignore", Reason: "unfixable and old", Severities: []string{"low"}, Fixable: &no, OlderThan: Age(2 * 365 * 24 * time.Hour)},
+	}
+
+	got := c.Get(&reporter.VoidReporter{}, "/package-lock.json").Policies
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Get() policies mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestConfigManager_Licenses(t *testing.T) {
+	t.Parallel()
+
+	c := &ConfigManager{
+		FS: fstest.MapFS{
+			"osv-scanner.toml": {Data: []byte(`
+[Licenses]
+allow = ["MIT", "Apache-2.0"]
+deny = ["AGPL-3.0-only"]
+
+[[Licenses.exceptions]]
+package = "shared-tool"
+ecosystem = "npm"
+licenses = ["GPL-3.0-only"]
+`)},
+			"package-lock.json": {Data: []byte("{}")},
+			"backend/osv-scanner.toml": {Data: []byte(`
+Inherit = true
+
+[Licenses]
+deny = ["SSPL-1.0"]
+
+[[Licenses.exceptions]]
+package = "internal-tool"
+reason = "only used internally"
+`)},
+			"backend/package-lock.json": {Data: []byte("{}")},
+		},
+	}
+
+	r := &reporter.VoidReporter{}
+
+	want := LicenseConfig{
+		Allow: []string{"MIT", "Apache-2.0"},
+		Deny:  []string{"AGPL-3.0-only"},
+		Exceptions: []LicenseException{
+			{Package: "shared-tool", Ecosystem: "npm", Licenses: []string{"GPL-3.0-only"}},
+		},
+	}
+	if diff := cmp.Diff(want, c.Get(r, "/package-lock.json").Licenses); diff != "" {
+		t.Errorf("Get() licenses mismatch (-want +got):\n%s", diff)
+	}
+
+	// the policy of the subtree replaces the lists it sets, and adds its exceptions
+	want = LicenseConfig{
+		Allow: []string{"MIT", "Apache-2.0"},
+		Deny:  []string{"SSPL-1.0"},
+		Exceptions: []LicenseException{
+			{Package: "internal-tool", Reason: "only used internally"},
+			{Package: "shared-tool", Ecosystem: "npm", Licenses: []string{"GPL-3.0-only"}},
+		},
+	}
+	if diff := cmp.Diff(want, c.Get(r, "/backend/package-lock.json").Licenses); diff != "" {
+		t.Errorf("Get() licenses mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestConfig_ShouldExemptPackageLicense(t *testing.T) {
+	t.Parallel()
+
+	config := Config{
+		Licenses: LicenseConfig{
+			Exceptions: []LicenseException{
+				{Package: "expired", EffectiveUntil: time.Now().Add(-time.Hour)},
+				{Package: "ranged", Ecosystem: "npm", Versions: ">= 1.0.0, < 2.0.0", Licenses: []string{"AGPL-3.0-only"}},
+				{Package: "released", Ecosystem: "Debian:12"},
+				{Package: "anywhere"},
+			},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		version   string
+		ecosystem string
+		want      bool
+	}{
+		{name: "expired", version: "1.0.0", ecosystem: "npm", want: false},
+		{name: "ranged", version: "1.5.0", ecosystem: "npm", want: true},
+		{name: "ranged", version: "2.0.0", ecosystem: "npm", want: false},
+		{name: "ranged", version: "1.5.0", ecosystem: "PyPI", want: false},
+		{name: "released", version: "1.0.0", ecosystem: "Debian:10", want: true},
+		{name: "released", version: "1.0.0", ecosystem: "debian:11", want: true},
+		{name: "released", version: "1.0.0", ecosystem: "Alpine:v3.18", want: false},
+		{name: "anywhere", version: "0.1.0", ecosystem: "Go", want: true},
+		{name: "other", version: "1.0.0", ecosystem: "npm", want: false},
+	}
+
+	for _, tt := range tests {
+		got, _ := config.ShouldExemptPackageLicense(tt.name, tt.version, tt.ecosystem)
+		if got != tt.want {
+			t.Errorf("ShouldExemptPackageLicense(%q, %q, %q) = %v, want %v", tt.name, tt.version, tt.ecosystem, got, tt.want)
+		}
+	}
+}
+
+func TestConfigManager_GetAPIConfig(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	write := func(name string, content string) {
+		t.Helper()
+
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write("osv-scanner.toml", "[API]\nbaseURL = \"https://discovered.example.com\"\n")
+	write("shared.toml", "[API]\nbaseURL = \"https://extended.example.com\"\n")
+	write("override.toml", "Extends = [\"shared.toml\"]\n")
+
+	// configs that are found alongside scanned files are never used
+	c := &ConfigManager{}
+	if got, _ := c.GetAPIConfig(); !reflect.DeepEqual(got, APIConfig{}) {
+		t.Errorf("GetAPIConfig() = %+v, want no settings without an override config", got)
+	}
+
+	// nor are the settings of configs that the override config extends
+	if err := c.UseOverride(filepath.Join(dir, "override.toml")); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := c.GetAPIConfig(); !reflect.DeepEqual(got, APIConfig{}) {
+		t.Errorf("GetAPIConfig() = %+v, want the settings of the extended config to not be used", got)
+	}
+
+	write("override.toml", "[API]\nbaseURL = \"https://explicit.example.com\"\n\n[API.headers]\nAuthorization = \"$TOKEN\"\n")
+	if err := c.UseOverride(filepath.Join(dir, "override.toml")); err != nil {
+		t.Fatal(err)
+	}
+
+	want := APIConfig{BaseURL: "https://explicit.example.com", Headers: map[string]string{"Authorization": "$TOKEN"}}
+	if got, _ := c.GetAPIConfig(); !reflect.DeepEqual(got, want) {
+		t.Errorf("GetAPIConfig() = %+v, want %+v", got, want)
+	}
+}
+
+func TestAge_UnmarshalText(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		text    string
+		want    time.Duration
+		wantErr bool
+	}{
+		{text: "90d", want: 90 * 24 * time.Hour},
+		{text: "2w", want: 14 * 24 * time.Hour},
+		{text: "1y", want: 365 * 24 * time.Hour},
+		{text: "36h", want: 36 * time.Hour},
+		{text: "d", wantErr: true},
+		{text: "-1d", wantErr: true},
+		{text: "soon", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			t.Parallel()
+
+			var got Age
+			err := got.UnmarshalText([]byte(tt.text))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("UnmarshalText() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !tt.wantErr && time.Duration(got) != tt.want {
+				t.Errorf("UnmarshalText() = %v, want %v", time.Duration(got), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,255 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// entrySource is where an entry of a config was loaded from, which is
+// another config file if the config extends or inherits from it
+type entrySource struct {
+	path  string
+	index int
+}
+
+// loadConfig loads the config file at the given path along with the configs
+// that it extends or inherits from, from the local filesystem if local is
+// set and otherwise from the filesystem of the manager
+func (c *ConfigManager) loadConfig(configPath string, local bool) (Config, error) {
+	config, err := c.decodeConfig(configPath, local)
+	if err != nil {
+		return Config{}, err
+	}
+
+	config, err = c.resolveConfig(config, local, []string{configPath})
+	if err != nil {
+		return Config{}, err
+	}
+
+	config.usage = c.ignoreUsage()
+	config.ignorePaths = compileIgnorePaths(config.IgnoredVulns)
+
+	return config, nil
+}
+
+// decodeConfig decodes the config file at the given path, without the
+// configs that it extends or inherits from
+func (c *ConfigManager) decodeConfig(configPath string, local bool) (Config, error) {
+	var file io.ReadCloser
+	var err error
+
+	if local {
+		file, err = os.Open(configPath)
+	} else {
+		file, err = c.open(configPath)
+	}
+	if err != nil {
+		return Config{}, fmt.Errorf("no config file found on this path: %s", configPath)
+	}
+	defer file.Close()
+
+	var config Config
+	if _, err := toml.NewDecoder(file).Decode(&config); err != nil {
+		return Config{}, fmt.Errorf("failed to parse config file: %w", err)
+	}
+
+	config.LoadPath = configPath
+	config.sources = []string{configPath}
+
+	for i := range config.IgnoredVulns {
+		config.ignoreSources = append(config.ignoreSources, entrySource{configPath, i})
+	}
+	for i := range config.Policies {
+		config.policySources = append(config.policySources, entrySource{configPath, i})
+	}
+	for i := range config.CVSSEnvironments {
+		config.environmentSources = append(config.environmentSources, entrySource{configPath, i})
+	}
+
+	return config, nil
+}
+
+// resolveConfig merges the configs that the config extends, followed by the
+// one it inherits from (if any), into it; visited are the config files that
+// have already been loaded, so that configs cannot extend themselves
+func (c *ConfigManager) resolveConfig(config Config, local bool, visited []string) (Config, error) {
+	dir := filepath.Dir(config.LoadPath)
+	parents := make([]string, 0, len(config.Extends)+1)
+
+	for _, extends := range config.Extends {
+		if !filepath.IsAbs(extends) {
+			extends = filepath.Join(dir, extends)
+		}
+
+		parents = append(parents, filepath.Clean(extends))
+	}
+
+	if config.Inherit {
+		if parent, ok := c.findParentConfig(dir, local); ok {
+			parents = append(parents, parent)
+		}
+	}
+
+	for _, parentPath := range parents {
+		if slices.Contains(visited, parentPath) {
+			return Config{}, fmt.Errorf("config file %s extends itself through %s", config.LoadPath, parentPath)
+		}
+
+		parent, err := c.decodeConfig(parentPath, local)
+		if err != nil {
+			return Config{}, fmt.Errorf("failed to load %s, which %s extends: %w", parentPath, config.LoadPath, err)
+		}
+
+		parent, err = c.resolveConfig(parent, local, append(slices.Clip(visited), parentPath))
+		if err != nil {
+			return Config{}, err
+		}
+
+		config = config.merge(parent)
+	}
+
+	return config, nil
+}
+
+// findParentConfig returns the path of the nearest config file in a parent
+// of the given directory
+func (c *ConfigManager) findParentConfig(dir string, local bool) (string, bool) {
+	if local || c.FS == nil {
+		if abs, err := filepath.Abs(dir); err == nil {
+			dir = abs
+		}
+	}
+
+	for {
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+
+		configPath := filepath.Join(dir, osvScannerConfigName)
+
+		var err error
+		if local {
+			_, err = os.Stat(configPath)
+		} else {
+			_, err = c.stat(configPath)
+		}
+
+		if err == nil {
+			return configPath, true
+		}
+	}
+}
+
+// merge adds the entries of the parent config after those of the config,
+// so that those of the config take precedence, and uses the settings of the
+// parent that the config does not set
+func (c Config) merge(parent Config) Config {
+	c.IgnoredVulns = append(slices.Clip(c.IgnoredVulns), parent.IgnoredVulns...)
+	c.ignoreSources = append(slices.Clip(c.ignoreSources), parent.ignoreSources...)
+	c.PackageOverrides = append(slices.Clip(c.PackageOverrides), parent.PackageOverrides...)
+	c.PathFilters = append(slices.Clip(c.PathFilters), parent.PathFilters...)
+	c.Policies = append(slices.Clip(c.Policies), parent.Policies...)
+	c.policySources = append(slices.Clip(c.policySources), parent.policySources...)
+	c.CVSSEnvironments = append(slices.Clip(c.CVSSEnvironments), parent.CVSSEnvironments...)
+	c.environmentSources = append(slices.Clip(c.environmentSources), parent.environmentSources...)
+	c.sources = append(slices.Clip(c.sources), parent.sources...)
+
+	if c.GoVersionOverride == "" {
+		c.GoVersionOverride = parent.GoVersionOverride
+	}
+
+	// the API settings are never inherited, as they are only trusted from
+	// the config file that is passed explicitly
+
+	if len(c.Licenses.Allow) == 0 {
+		c.Licenses.Allow = parent.Licenses.Allow
+	}
+	if len(c.Licenses.Deny) == 0 {
+		c.Licenses.Deny = parent.Licenses.Deny
+	}
+	c.Licenses.Exceptions = append(slices.Clip(c.Licenses.Exceptions), parent.Licenses.Exceptions...)
+
+	if c.FailOn.Severity == "" {
+		c.FailOn.Severity = parent.FailOn.Severity
+	}
+	if c.FailOn.UnknownSeverity == "" {
+		c.FailOn.UnknownSeverity = parent.FailOn.UnknownSeverity
+	}
+
+	return c
+}
+
+// ignoreSource returns where the ignore entry at the index was loaded from
+func (c *Config) ignoreSource(index int) entrySource {
+	if index < len(c.ignoreSources) {
+		return c.ignoreSources[index]
+	}
+
+	return entrySource{c.LoadPath, index}
+}
+
+// policySource returns where the policy at the index was loaded from
+func (c *Config) policySource(index int) entrySource {
+	if index < len(c.policySources) {
+		return c.policySources[index]
+	}
+
+	return entrySource{c.LoadPath, index}
+}
+
+// environmentSource returns where the CVSS environment at the index was loaded from
+func (c *Config) environmentSource(index int) entrySource {
+	if index < len(c.environmentSources) {
+		return c.environmentSources[index]
+	}
+
+	return entrySource{c.LoadPath, index}
+}
+
+// Sources returns the config files that the config was loaded from, starting
+// with its own followed by those that it extends or inherits from
+func (c *Config) Sources() []string {
+	if len(c.sources) == 0 && c.LoadPath != "" {
+		return []string{c.LoadPath}
+	}
+
+	return c.sources
+}
+
+// PolicyRelativePath returns the target path relative to the directory of
+// the config file that the policy at the index was loaded from, if it is
+// within it
+func (c *Config) PolicyRelativePath(index int, target string) (string, bool) {
+	return relativePath(c.policySource(index).path, target)
+}
+
+// CVSSEnvironmentRelativePath returns the target path relative to the
+// directory of the config file that the CVSS environment at the index was
+// loaded from, if it is within it
+func (c *Config) CVSSEnvironmentRelativePath(index int, target string) (string, bool) {
+	return relativePath(c.environmentSource(index).path, target)
+}
+
+// String returns the config as TOML, as it is after merging in any configs
+// that it extends or inherits from
+func (c Config) String() string {
+	var buf bytes.Buffer
+
+	c.Extends = nil
+	c.Inherit = false
+
+	if err := toml.NewEncoder(&buf).Encode(c); err != nil {
+		return err.Error()
+	}
+
+	return strings.TrimSpace(buf.String())
+}
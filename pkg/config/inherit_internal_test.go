@@ -0,0 +1,128 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/osv-scanner/pkg/reporter"
+)
+
+func TestConfigManager_Inheritance(t *testing.T) {
+	t.Parallel()
+
+	c := &ConfigManager{
+		FS: fstest.MapFS{
+			"osv-scanner.toml": {Data: []byte(`
+GoVersionOverride = "1.21.0"
+
+[[IgnoredVulns]]
+id = "GHSA-root"
+
+[[IgnoredVulns]]
+id = "GHSA-scoped"
+paths = ["services/api"]
+
+[[CVSSEnvironments]]
+paths = ["services/**"]
+cvssV3 = "MAV:A"
+`)},
+			"org/shared.toml": {Data: []byte(`
+[[PackageOverrides]]
+name = "lib"
+ecosystem = "Go"
+ignore = true
+`)},
+			"services/api/osv-scanner.toml": {Data: []byte(`
+Inherit = true
+Extends = ["../../org/shared.toml"]
+
+[[IgnoredVulns]]
+id = "GHSA-api"
+`)},
+			"services/api/go.mod": {Data: []byte("module api\n")},
+			"services/web/osv-scanner.toml": {Data: []byte(`
+[[IgnoredVulns]]
+id = "GHSA-web"
+`)},
+			"services/web/go.mod": {Data: []byte("module web\n")},
+		},
+	}
+
+	r := &reporter.VoidReporter{}
+	api := c.Get(r, "/services/api/go.mod")
+
+	var ids []string
+	for _, entry := range api.IgnoredVulns {
+		ids = append(ids, entry.ID)
+	}
+
+	// the entries of the config come first, followed by those it extends and then inherits
+	if diff := cmp.Diff([]string{"GHSA-api", "GHSA-root", "GHSA-scoped"}, ids); diff != "" {
+		t.Errorf("Get() ignores mismatch (-want +got):\n%s", diff)
+	}
+
+	wantSources := []string{"/services/api/osv-scanner.toml", "/org/shared.toml", "/osv-scanner.toml"}
+	if diff := cmp.Diff(wantSources, api.Sources()); diff != "" {
+		t.Errorf("Sources() mismatch (-want +got):\n%s", diff)
+	}
+
+	if ignore, _ := api.ShouldIgnorePackageVersion("lib", "1.0.0", "Go"); !ignore {
+		t.Errorf("expected the package override of the extended config to apply")
+	}
+
+	if api.GoVersionOverride != "1.21.0" {
+		t.Errorf("GoVersionOverride = %q, want the inherited %q", api.GoVersionOverride, "1.21.0")
+	}
+
+	// paths of inherited entries are relative to the config they are from
+	if ignore, _ := api.ShouldIgnoreIn("GHSA-scoped", IgnoreTarget{SourcePath: "/services/api/go.mod"}); !ignore {
+		t.Errorf("expected the inherited scoped ignore to apply")
+	}
+
+	if rel, ok := api.CVSSEnvironmentRelativePath(0, "/services/api/go.mod"); !ok || rel != "services/api/go.mod" {
+		t.Errorf("CVSSEnvironmentRelativePath() = %q, %v, want the path relative to the inherited config", rel, ok)
+	}
+
+	// configs do not inherit unless they opt in
+	web := c.Get(r, "/services/web/go.mod")
+	if ignore, _ := web.ShouldIgnore("GHSA-root"); ignore {
+		t.Errorf("expected the root config to not be inherited")
+	}
+
+	// unused inherited entries are reported against the config they are from
+	var unused []string
+	for _, issue := range c.UnusedIgnores() {
+		unused = append(unused, issue.Path+" "+issue.Key)
+	}
+
+	wantUnused := []string{"/osv-scanner.toml IgnoredVulns[0]", "/services/api/osv-scanner.toml IgnoredVulns[0]", "/services/web/osv-scanner.toml IgnoredVulns[0]"}
+	if diff := cmp.Diff(wantUnused, unused); diff != "" {
+		t.Errorf("UnusedIgnores() mismatch (-want +got):\n%s", diff)
+	}
+
+	if got := api.String(); !strings.Contains(got, `id = "GHSA-root"`) || strings.Contains(got, "Inherit") {
+		t.Errorf("String() = %s, want the merged config", got)
+	}
+}
+
+func TestConfigManager_Inheritance_Invalid(t *testing.T) {
+	t.Parallel()
+
+	for name, fsys := range map[string]fstest.MapFS{
+		"missing": {
+			"osv-scanner.toml": {Data: []byte("Extends = [\"missing.toml\"]\n")},
+		},
+		"cycle": {
+			"osv-scanner.toml": {Data: []byte("Extends = [\"other.toml\"]\n")},
+			"other.toml":       {Data: []byte("Extends = [\"osv-scanner.toml\"]\n")},
+		},
+	} {
+		c := &ConfigManager{FS: fsys}
+
+		if _, err := c.tryLoadConfig("/osv-scanner.toml"); err == nil {
+			t.Errorf("expected the %s config to not load", name)
+		}
+	}
+}
@@ -0,0 +1,432 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/google/osv-scanner/internal/pathfilter"
+	"github.com/google/osv-scanner/internal/utility/severity"
+	"github.com/google/osv-scanner/pkg/models"
+	"github.com/google/osv-scanner/pkg/reporter"
+	"github.com/google/osv-scanner/pkg/spdx"
+)
+
+// DefaultExpiringWithin is how soon an entry has to expire for it to be
+// reported as expiring, unless told otherwise
+const DefaultExpiringWithin = 30 * 24 * time.Hour
+
+// LintIssueKind is the kind of problem that was found with a config file
+type LintIssueKind string
+
+const (
+	// LintInvalidConfig is a config file that cannot be loaded at all,
+	// which means the default config is used instead
+	LintInvalidConfig LintIssueKind = "invalid-config"
+	// LintUnknownKey is a key that is not used by any setting, which is
+	// usually a typo of one that is
+	LintUnknownKey LintIssueKind = "unknown-key"
+	// LintInvalidEcosystem is an ecosystem that packages are never from
+	LintInvalidEcosystem LintIssueKind = "invalid-ecosystem"
+	// LintInvalidDate is an expiry date that is not a TOML date
+	LintInvalidDate LintIssueKind = "invalid-date"
+	// LintInvalidValue is a value that will not match anything, such as a
+	// malformed version range or glob
+	LintInvalidValue LintIssueKind = "invalid-value"
+	// LintExpired is an entry that has expired, so no longer applies
+	LintExpired LintIssueKind = "expired"
+	// LintExpiring is an entry that will soon expire
+	LintExpiring LintIssueKind = "expiring"
+	// LintUnused is an ignore that did not match any vulnerability
+	LintUnused LintIssueKind = "unused"
+)
+
+// LintIssue is a problem with a config file, which usually does not stop it
+// from being loaded but means it does not do what was intended
+type LintIssue struct {
+	Path string        `json:"path"`
+	Kind LintIssueKind `json:"kind"`
+	// Key is where the issue is in the config file, i.e. "IgnoredVulns[2]"
+	Key     string `json:"key,omitempty"`
+	Message string `json:"message"`
+}
+
+// ignoreUsage records which ignore entries have matched a vulnerability,
+// which is shared by all of the configs loaded by a manager as they can
+// inherit the same entries
+type ignoreUsage struct {
+	mu   sync.Mutex
+	used map[entrySource]bool
+}
+
+func (u *ignoreUsage) mark(source entrySource) {
+	if u == nil {
+		return
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	u.used[source] = true
+}
+
+func (u *ignoreUsage) isUsed(source entrySource) bool {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	return u.used[source]
+}
+
+// ignoreUsage returns the record of which ignore entries of the configs
+// loaded by the manager have matched a vulnerability
+func (c *ConfigManager) ignoreUsage() *ignoreUsage {
+	c.usageOnce.Do(func() {
+		c.usage = &ignoreUsage{used: make(map[entrySource]bool)}
+	})
+
+	return c.usage
+}
+
+// Lint checks the config file at the given path for problems, reporting
+// entries that expire within the given duration as expiring. An error is
+// only returned if the file cannot be read.
+func Lint(configPath string, expiringWithin time.Duration) ([]LintIssue, error) {
+	file, err := os.Open(configPath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	issues, err := lint(file, configPath, expiringWithin, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	// the configs that it extends or inherits from also have to be loadable
+	if !slices.ContainsFunc(issues, func(issue LintIssue) bool { return issue.Kind == LintInvalidConfig || issue.Kind == LintInvalidDate }) {
+		if _, err := (&ConfigManager{}).loadConfig(configPath, true); err != nil {
+			issues = append(issues, LintIssue{Path: configPath, Kind: LintInvalidConfig, Message: err.Error()})
+		}
+	}
+
+	return issues, nil
+}
+
+func lint(reader io.Reader, configPath string, expiringWithin time.Duration, now time.Time) ([]LintIssue, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	var issues []LintIssue
+	report := func(kind LintIssueKind, key string, format string, a ...any) {
+		issues = append(issues, LintIssue{Path: configPath, Kind: kind, Key: key, Message: fmt.Sprintf(format, a...)})
+	}
+
+	var raw map[string]any
+	if _, err := toml.Decode(string(data), &raw); err != nil {
+		report(LintInvalidConfig, "", "%v", err)
+
+		return issues, nil
+	}
+
+	// dates are checked before decoding the config, as any that are
+	// malformed stop it from being decoded
+	validDates := true
+	for _, field := range [][2]string{{"IgnoredVulns", "ignoreUntil"}, {"PackageOverrides", "effectiveUntil"}} {
+		entries, _ := raw[field[0]].([]map[string]any)
+
+		for i, entry := range entries {
+			value, ok := entry[field[1]]
+			if !ok {
+				continue
+			}
+
+			if _, ok := value.(time.Time); !ok {
+				validDates = false
+				report(LintInvalidDate, fmt.Sprintf("%s[%d].%s", field[0], i, field[1]), "%v is not a date, i.e. 2024-01-31", value)
+			}
+		}
+	}
+
+	var config Config
+	md, err := toml.Decode(string(data), &config)
+	if err != nil {
+		if validDates {
+			report(LintInvalidConfig, "", "%v", err)
+		}
+
+		return issues, nil
+	}
+
+	for _, key := range md.Undecoded() {
+		report(LintUnknownKey, key.String(), "%s is not a known setting", key)
+	}
+
+	checkExpiry := func(key string, id string, until time.Time) {
+		switch {
+		case until.IsZero():
+		case !until.After(now):
+			report(LintExpired, key, "%s expired on %s, so it no longer applies", id, until.Format(time.DateOnly))
+		case until.Sub(now) <= expiringWithin:
+			report(LintExpiring, key, "%s expires on %s", id, until.Format(time.DateOnly))
+		}
+	}
+
+	for i, entry := range config.IgnoredVulns {
+		key := fmt.Sprintf("IgnoredVulns[%d]", i)
+
+		if entry.ID == "" {
+			report(LintInvalidValue, key, "ignore does not have an id, so it does not match any vulnerability")
+		}
+
+		checkExpiry(key, entry.ID, entry.IgnoreUntil)
+
+		lintIgnoreScope(entry, key, report)
+	}
+
+	for i, entry := range config.PackageOverrides {
+		key := fmt.Sprintf("PackageOverrides[%d]", i)
+
+		checkExpiry(key, fmt.Sprintf("%s/%s", entry.Ecosystem, entry.Name), entry.EffectiveUntil)
+
+		// package overrides are matched case-sensitively
+		if !isKnownEcosystem(entry.Ecosystem, true) {
+			report(LintInvalidEcosystem, key+".ecosystem", "%q is not a known ecosystem", entry.Ecosystem)
+		}
+	}
+
+	for i, entry := range config.Policies {
+		for _, ecosystem := range entry.Ecosystems {
+			if !isKnownEcosystem(ecosystem, false) {
+				report(LintInvalidEcosystem, fmt.Sprintf("Policies[%d].ecosystems", i), "%q is not a known ecosystem", ecosystem)
+			}
+		}
+	}
+
+	for i, entry := range config.CVSSEnvironments {
+		key := fmt.Sprintf("CVSSEnvironments[%d]", i)
+
+		if entry.CVSSV3 == "" && entry.CVSSV4 == "" {
+			report(LintInvalidValue, key, "environment does not have any metrics, so it does not change any scores")
+		}
+
+		for _, ecosystem := range entry.Ecosystems {
+			if !isKnownEcosystem(ecosystem, false) {
+				report(LintInvalidEcosystem, key+".ecosystems", "%q is not a known ecosystem", ecosystem)
+			}
+		}
+
+		for _, glob := range entry.Paths {
+			if _, err := pathfilter.Compile(glob); err != nil {
+				report(LintInvalidValue, key+".paths", "%v", err)
+			}
+		}
+
+		if err := (severity.Environment{CVSSV3: entry.CVSSV3}).Validate(); err != nil {
+			report(LintInvalidValue, key+".cvssV3", "%v", err)
+		}
+
+		if err := (severity.Environment{CVSSV4: entry.CVSSV4}).Validate(); err != nil {
+			report(LintInvalidValue, key+".cvssV4", "%v", err)
+		}
+	}
+
+	for _, field := range []struct {
+		key      string
+		licenses []string
+	}{{"Licenses.allow", config.Licenses.Allow}, {"Licenses.deny", config.Licenses.Deny}} {
+		for _, license := range spdx.Unrecognized(field.licenses) {
+			report(LintInvalidValue, field.key, "%q is not a recognized SPDX license", license)
+		}
+	}
+
+	for i, entry := range config.Licenses.Exceptions {
+		key := fmt.Sprintf("Licenses.exceptions[%d]", i)
+
+		if entry.Package == "" {
+			report(LintInvalidValue, key, "exception does not have a package, so it does not match any package")
+		}
+
+		checkExpiry(key, entry.Package, entry.EffectiveUntil)
+
+		if entry.Ecosystem != "" && !isKnownEcosystem(entry.Ecosystem, false) {
+			report(LintInvalidEcosystem, key+".ecosystem", "%q is not a known ecosystem", entry.Ecosystem)
+		}
+
+		if entry.Versions != "" && !ValidVersionRange(entry.Versions) {
+			report(LintInvalidValue, key+".versions", "%q is not a valid range of versions, i.e. \">= 1.0.0, < 2.0.0\"", entry.Versions)
+		}
+
+		for _, license := range spdx.Unrecognized(entry.Licenses) {
+			report(LintInvalidValue, key+".licenses", "%q is not a recognized SPDX license", license)
+		}
+	}
+
+	return issues, nil
+}
+
+// lintIgnoreScope checks the values that the ignore entry is scoped with,
+// any of which that are invalid mean it does not match any vulnerability
+func lintIgnoreScope(entry IgnoreEntry, key string, report func(kind LintIssueKind, key string, format string, a ...any)) {
+	if entry.Ecosystem != "" && !isKnownEcosystem(entry.Ecosystem, false) {
+		report(LintInvalidEcosystem, key+".ecosystem", "%q is not a known ecosystem", entry.Ecosystem)
+	}
+
+	if entry.Versions != "" && !ValidVersionRange(entry.Versions) {
+		report(LintInvalidValue, key+".versions", "%q is not a valid range of versions, i.e. \">= 1.0.0, < 2.0.0\"", entry.Versions)
+	}
+
+	for _, glob := range entry.Paths {
+		if _, err := pathfilter.Compile(glob); err != nil {
+			report(LintInvalidValue, key+".paths", "%v", err)
+		}
+	}
+}
+
+// ignoreScopeIssues checks the scopes of the ignore entries of the config,
+// including those from the configs that it extends or inherits from
+func (c *Config) ignoreScopeIssues() []LintIssue {
+	var issues []LintIssue
+
+	for i, entry := range c.IgnoredVulns {
+		source := c.ignoreSource(i)
+
+		lintIgnoreScope(entry, fmt.Sprintf("IgnoredVulns[%d]", source.index), func(kind LintIssueKind, key string, format string, a ...any) {
+			issues = append(issues, LintIssue{Path: source.path, Kind: kind, Key: key, Message: fmt.Sprintf(format, a...)})
+		})
+	}
+
+	return issues
+}
+
+// warnIgnoreScopes reports the problems with the scopes of the ignore entries
+// of the config that have not already been reported, as otherwise the entries
+// would silently never apply; c.mu must be held
+func (c *ConfigManager) warnIgnoreScopes(r reporter.Reporter, config Config) {
+	for _, issue := range config.ignoreScopeIssues() {
+		if c.warned[issue] {
+			continue
+		}
+
+		if c.warned == nil {
+			c.warned = make(map[LintIssue]bool)
+		}
+		c.warned[issue] = true
+
+		r.Warnf("Warning: %s %s will never apply, as %s (%s)\n", issue.Path, issue.Key, issue.Message, issue.Kind)
+	}
+}
+
+// isKnownEcosystem reports if the ecosystem (ignoring any release, i.e. the
+// "10" of "Debian:10") is one that packages can be from
+func isKnownEcosystem(ecosystem string, caseSensitive bool) bool {
+	base, _, _ := strings.Cut(ecosystem, ":")
+
+	return slices.ContainsFunc(models.Ecosystems, func(e models.Ecosystem) bool {
+		if caseSensitive {
+			return string(e) == base
+		}
+
+		return strings.EqualFold(string(e), base)
+	})
+}
+
+// loaded returns the configs that have been loaded from config files,
+// sorted by the path they were loaded from
+func (c *ConfigManager) loaded() []Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var configs []Config
+	if c.OverrideConfig != nil {
+		configs = append(configs, *c.OverrideConfig)
+	}
+
+	for _, config := range c.ConfigMap {
+		if config.LoadPath != "" && !slices.ContainsFunc(configs, func(other Config) bool { return other.LoadPath == config.LoadPath }) {
+			configs = append(configs, config)
+		}
+	}
+
+	sort.Slice(configs, func(i, j int) bool { return configs[i].LoadPath < configs[j].LoadPath })
+
+	return configs
+}
+
+// UnusedIgnores returns an issue for each ignore entry of the configs that
+// have been loaded that has not matched any vulnerability
+func (c *ConfigManager) UnusedIgnores() []LintIssue {
+	var issues []LintIssue
+
+	usage := c.ignoreUsage()
+	seen := make(map[entrySource]bool)
+
+	for _, config := range c.loaded() {
+		for i, entry := range config.IgnoredVulns {
+			source := config.ignoreSource(i)
+			if seen[source] || usage.isUsed(source) {
+				continue
+			}
+			seen[source] = true
+
+			issues = append(issues, LintIssue{
+				Path:    source.path,
+				Kind:    LintUnused,
+				Key:     fmt.Sprintf("IgnoredVulns[%d]", source.index),
+				Message: entry.ID + " did not match any vulnerability",
+			})
+		}
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool { return issues[i].Path < issues[j].Path })
+
+	return issues
+}
+
+// Lint checks the config files that have been loaded (including those that
+// they extend or inherit from) for problems, along with any of their ignore
+// entries that have not matched a vulnerability
+func (c *ConfigManager) Lint(expiringWithin time.Duration) []LintIssue {
+	var issues []LintIssue
+	var linted []string
+
+	// override configs (and those they extend) are loaded from the local filesystem
+	local := c.OverrideConfig != nil
+
+	for _, config := range c.loaded() {
+		for _, source := range config.Sources() {
+			if slices.Contains(linted, source) {
+				continue
+			}
+			linted = append(linted, source)
+
+			var file io.ReadCloser
+			var err error
+
+			if local {
+				file, err = os.Open(source)
+			} else {
+				file, err = c.open(source)
+			}
+			if err != nil {
+				continue
+			}
+
+			found, err := lint(file, source, expiringWithin, time.Now())
+			file.Close()
+
+			if err == nil {
+				issues = append(issues, found...)
+			}
+		}
+	}
+
+	return append(issues, c.UnusedIgnores()...)
+}
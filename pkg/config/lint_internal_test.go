@@ -0,0 +1,142 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLint(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		config string
+		want   []LintIssue
+	}{
+		{
+			name:   "valid",
+			config: "[[IgnoredVulns]]\nid = \"GHSA-1\"\nignoreUntil = 2025-01-01\n\n[[PackageOverrides]]\nname = \"lib\"\necosystem = \"Go\"\nignore = true\n",
+		},
+		{
+			name:   "syntax",
+			config: "[[IgnoredVulns]\nid = \"GHSA-1\"\n",
+			want:   []LintIssue{{Kind: LintInvalidConfig}},
+		},
+		{
+			name:   "unknown keys",
+			config: "[[IgnoredVulns]]\nid = \"GHSA-1\"\nreasn = \"typo\"\n",
+			want:   []LintIssue{{Kind: LintUnknownKey, Key: "IgnoredVulns.reasn"}},
+		},
+		{
+			name:   "malformed dates",
+			config: "[[IgnoredVulns]]\nid = \"GHSA-1\"\nignoreUntil = \"next year\"\n\n[[PackageOverrides]]\nname = \"lib\"\necosystem = \"Go\"\neffectiveUntil = 2024\n",
+			want: []LintIssue{
+				{Kind: LintInvalidDate, Key: "IgnoredVulns[0].ignoreUntil"},
+				{Kind: LintInvalidDate, Key: "PackageOverrides[0].effectiveUntil"},
+			},
+		},
+		{
+			name:   "expired and expiring",
+			config: "[[IgnoredVulns]]\nid = \"GHSA-1\"\nignoreUntil = 2024-05-01\n\n[[IgnoredVulns]]\nid = \"GHSA-2\"\nignoreUntil = 2024-06-15\n\n[[PackageOverrides]]\nname = \"lib\"\necosystem = \"Go\"\neffectiveUntil = 2023-01-01\n",
+			want: []LintIssue{
+				{Kind: LintExpired, Key: "IgnoredVulns[0]"},
+				{Kind: LintExpiring, Key: "IgnoredVulns[1]"},
+				{Kind: LintExpired, Key: "PackageOverrides[0]"},
+			},
+		},
+		{
+			name:   "invalid ecosystems",
+			config: "[[IgnoredVulns]]\nid = \"GHSA-1\"\necosystem = \"NPM\"\n\n[[PackageOverrides]]\nname = \"lib\"\necosystem = \"go\"\n\n[[Policies]]\naction = \"warn\"\necosystems = [\"Debian:12\", \"cargo\"]\n",
+			want: []LintIssue{
+				{Kind: LintInvalidEcosystem, Key: "PackageOverrides[0].ecosystem"},
+				{Kind: LintInvalidEcosystem, Key: "Policies[0].ecosystems"},
+			},
+		},
+		{
+			name:   "invalid scopes",
+			config: "[[IgnoredVulns]]\nid = \"GHSA-1\"\nversions = \"=> 1.0.0\"\npaths = [\"[a-\"]\n",
+			want: []LintIssue{
+				{Kind: LintInvalidValue, Key: "IgnoredVulns[0].versions"},
+				{Kind: LintInvalidValue, Key: "IgnoredVulns[0].paths"},
+			},
+		},
+		{
+			name:   "invalid license policy",
+			config: "[Licenses]\nallow = [\"MIT\", \"Apache 2\"]\ndeny = [\"AGPL-3.0-only\"]\n\n[[Licenses.exceptions]]\necosystem = \"Pip\"\nlicenses = [\"GPL\"]\n\n[[Licenses.exceptions]]\npackage = \"tool\"\nversions = \"=> 1.0\"\neffectiveUntil = 2024-06-15\n",
+			want: []LintIssue{
+				{Kind: LintInvalidValue, Key: "Licenses.allow"},
+				{Kind: LintInvalidValue, Key: "Licenses.exceptions[0]"},
+				{Kind: LintInvalidEcosystem, Key: "Licenses.exceptions[0].ecosystem"},
+				{Kind: LintInvalidValue, Key: "Licenses.exceptions[0].licenses"},
+				{Kind: LintExpiring, Key: "Licenses.exceptions[1]"},
+				{Kind: LintInvalidValue, Key: "Licenses.exceptions[1].versions"},
+			},
+		},
+		{
+			name:   "invalid cvss environments",
+			config: "[[CVSSEnvironments]]\necosystems = [\"nmp\"]\n\n[[CVSSEnvironments]]\npaths = [\"services/[\"]\ncvssV3 = \"AV:L/MAV:A\"\ncvssV4 = \"MAV:A/MS:C\"\n",
+			want: []LintIssue{
+				{Kind: LintInvalidValue, Key: "CVSSEnvironments[0]"},
+				{Kind: LintInvalidEcosystem, Key: "CVSSEnvironments[0].ecosystems"},
+				{Kind: LintInvalidValue, Key: "CVSSEnvironments[1].paths"},
+				{Kind: LintInvalidValue, Key: "CVSSEnvironments[1].cvssV3"},
+				{Kind: LintInvalidValue, Key: "CVSSEnvironments[1].cvssV4"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := lint(strings.NewReader(tt.config), "osv-scanner.toml", DefaultExpiringWithin, now)
+			if err != nil {
+				t.Fatalf("lint() error = %v", err)
+			}
+
+			// only the kind and location of the issues are compared
+			for i := range got {
+				if got[i].Path != "osv-scanner.toml" || got[i].Message == "" {
+					t.Errorf("lint() returned an issue without a path or message: %+v", got[i])
+				}
+				got[i].Path, got[i].Message = "", ""
+			}
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("lint() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestConfigManager_UnusedIgnores(t *testing.T) {
+	t.Parallel()
+
+	configPath := filepath.Join(t.TempDir(), "osv-scanner.toml")
+	config := "[[IgnoredVulns]]\nid = \"GHSA-1\"\n\n[[IgnoredVulns]]\nid = \"GHSA-2\"\n\n[[IgnoredVulns]]\nid = \"GHSA-3\"\nignoreUntil = 2020-01-01\n"
+
+	if err := os.WriteFile(configPath, []byte(config), 0600); err != nil {
+		t.Fatalf("could not write config: %v", err)
+	}
+
+	c := &ConfigManager{}
+	if err := c.UseOverride(configPath); err != nil {
+		t.Fatalf("UseOverride() error = %v", err)
+	}
+
+	loaded := c.Get(nil, configPath)
+	loaded.ShouldIgnore("GHSA-1")
+	// expired ignores still count as being used, as they are reported as expired
+	loaded.ShouldIgnore("GHSA-3")
+
+	want := []LintIssue{{Path: configPath, Kind: LintUnused, Key: "IgnoredVulns[1]", Message: "GHSA-2 did not match any vulnerability"}}
+	if diff := cmp.Diff(want, c.UnusedIgnores()); diff != "" {
+		t.Errorf("UnusedIgnores() mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -56,6 +56,12 @@ func MakeVersionRequests(queries []*depsdevpb.GetVersionRequest) ([][]models.Lic
 // connection. The order in which the requests are specified should correspond
 // to the order of licenses returned by this function.
 func MakeVersionRequestsWithContext(ctx context.Context, queries []*depsdevpb.GetVersionRequest) ([][]models.License, error) {
+	return MakeVersionRequestsWithUserAgent(ctx, osv.RequestUserAgent, queries)
+}
+
+// MakeVersionRequestsWithUserAgent is like MakeVersionRequestsWithContext,
+// but identifies itself with the given user agent instead of osv.RequestUserAgent.
+func MakeVersionRequestsWithUserAgent(ctx context.Context, userAgent string, queries []*depsdevpb.GetVersionRequest) ([][]models.License, error) {
 	certPool, err := x509.SystemCertPool()
 	if err != nil {
 		return nil, fmt.Errorf("getting system cert pool: %w", err)
@@ -63,8 +69,8 @@ func MakeVersionRequestsWithContext(ctx context.Context, queries []*depsdevpb.Ge
 	creds := credentials.NewClientTLSFromCert(certPool, "")
 	dialOpts := []grpc.DialOption{grpc.WithTransportCredentials(creds)}
 
-	if osv.RequestUserAgent != "" {
-		dialOpts = append(dialOpts, grpc.WithUserAgent(osv.RequestUserAgent))
+	if userAgent != "" {
+		dialOpts = append(dialOpts, grpc.WithUserAgent(userAgent))
 	}
 
 	conn, err := grpc.NewClient(DepsdevAPI, dialOpts...)
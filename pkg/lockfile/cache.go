@@ -0,0 +1,370 @@
+package lockfile
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync/atomic"
+
+	"github.com/google/osv-scanner/internal/version"
+)
+
+const envKeyExtractionCacheDirectory = "OSV_SCANNER_EXTRACTION_CACHE_DIRECTORY"
+
+// cacheFormatVersion should be bumped whenever the structure of cached
+// entries changes, in addition to entries being specific to a release
+const cacheFormatVersion = "4"
+
+// Cache is an on-disk cache of extracted lockfiles, which allows unchanged
+// lockfiles to be skipped when scanning the same paths again.
+//
+// Entries are keyed by the path of the lockfile and the parser that was
+// requested, and are only reused if:
+//   - they were written by the same release of the scanner, as extractors
+//     can change between releases
+//   - the content of the lockfile has not changed
+//   - the same extractor would be used to extract the lockfile
+//   - the content of every other file that was read while extracting the
+//     lockfile (such as the package.json that packages are matched against)
+//     has not changed, including files that did not exist at the time
+//   - the files in every directory that was listed while extracting the
+//     lockfile are the same
+//   - the matchers of the extractor find the same source files
+type Cache struct {
+	// the directory that extracted lockfiles are stored in
+	Dir string
+
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+// CacheStats describes how many lockfiles were served from a Cache.
+type CacheStats struct {
+	Hits   int
+	Misses int
+}
+
+type cachedLockfile struct {
+	Version  string `json:"version"`
+	Hash     string `json:"hash"`
+	ParsedAs string `json:"parsed_as"`
+	// the hashes of the other files that were read while extracting the
+	// lockfile, which are empty for files that could not be read
+	Dependencies map[string]string `json:"dependencies"`
+	// the hashes of the listings of the directories that were read while
+	// extracting the lockfile, which are empty for directories that could
+	// not be read
+	Listings map[string]string `json:"listings,omitempty"`
+	// the source files that were found by the matchers of the extractor
+	SourceFiles []string `json:"source_files"`
+	Lockfile    Lockfile `json:"lockfile"`
+	MatchErrors []string `json:"match_errors,omitempty"`
+	Warnings    []string `json:"warnings,omitempty"`
+}
+
+// DefaultCacheDir returns the directory that the cache should be stored in
+// if one has not been explicitly provided, either the value of the
+// OSV_SCANNER_EXTRACTION_CACHE_DIRECTORY environment variable or a
+// directory within the user cache directory.
+func DefaultCacheDir() (string, error) {
+	if p, envSet := os.LookupEnv(envKeyExtractionCacheDirectory); envSet {
+		return p, nil
+	}
+
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+
+	return filepath.Join(dir, "osv-scanner", "extraction-cache"), nil
+}
+
+// NewCache creates a Cache that stores extracted lockfiles in the given
+// directory, creating it if it does not already exist.
+func NewCache(dir string) (*Cache, error) {
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return nil, fmt.Errorf("could not create cache directory: %w", err)
+	}
+
+	return &Cache{Dir: dir}, nil
+}
+
+// Stats returns how many lockfiles have been served from the cache so far.
+func (c *Cache) Stats() CacheStats {
+	return CacheStats{
+		Hits:   int(c.hits.Load()),
+		Misses: int(c.misses.Load()),
+	}
+}
+
+func (c *Cache) entryPath(path, extractAs string) string {
+	sum := sha256.Sum256([]byte(path + "\x00" + extractAs))
+
+	return filepath.Join(c.Dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// hashFile returns the hash of the content of the file at the given path,
+// or an empty string if it cannot be read
+func hashFile(path string) string {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+
+	sum := sha256.Sum256(b)
+
+	return hex.EncodeToString(sum[:])
+}
+
+// hashDir returns the hash of the names of the files in the directory at the
+// given path, or an empty string if it cannot be read
+func hashDir(path string) string {
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return ""
+	}
+
+	hash := sha256.New()
+
+	for _, entry := range entries {
+		hash.Write([]byte(entry.Name() + "\x00"))
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// ExtractDeps is like the package level ExtractDeps, except that the
+// lockfile at the given path is only extracted if there is not an
+// up-to-date result for it in the cache.
+//
+// Failing to read or write the cache is not an error, as the lockfile
+// can always be extracted again.
+func (c *Cache) ExtractDeps(path string, extractAs string, enabledParsers map[string]bool) (Lockfile, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return Lockfile{}, err
+	}
+
+	hash := hashFile(path)
+	entryPath := c.entryPath(path, extractAs)
+
+	if parsedLockfile, ok := c.get(entryPath, path, hash, extractAs, enabledParsers); ok {
+		c.hits.Add(1)
+
+		return parsedLockfile, nil
+	}
+
+	c.misses.Add(1)
+
+	f, err := OpenLocalDepFile(path)
+	if err != nil {
+		return Lockfile{}, err
+	}
+	defer f.Close()
+
+	recorder := &depFileRecorder{
+		dependencies: make(map[string]string),
+		listings:     make(map[string]string),
+	}
+	parsedLockfile, err := ExtractDeps(recordingDepFile{f, recorder}, extractAs, enabledParsers)
+
+	// only successful extractions are cached, as errors can be transient
+	if err != nil || hash == "" {
+		return parsedLockfile, err
+	}
+
+	entry := cachedLockfile{
+		Version:      cacheFormatVersion + "/" + version.OSVVersion,
+		Hash:         hash,
+		ParsedAs:     parsedLockfile.ParsedAs,
+		Dependencies: recorder.dependencies,
+		Listings:     recorder.listings,
+		SourceFiles:  MatcherSourceFiles(f, parsedLockfile.ParsedAs),
+		Lockfile:     parsedLockfile,
+	}
+
+	for _, matchErr := range parsedLockfile.MatchErrors {
+		entry.MatchErrors = append(entry.MatchErrors, matchErr.Error())
+	}
+
+	for _, warning := range parsedLockfile.Warnings {
+		entry.Warnings = append(entry.Warnings, warning.Error())
+	}
+
+	for _, sourceFile := range entry.SourceFiles {
+		entry.Dependencies[sourceFile] = hashFile(sourceFile)
+	}
+
+	_ = c.writeJSON(entryPath, entry)
+
+	return parsedLockfile, nil
+}
+
+func (c *Cache) get(entryPath, path, hash, extractAs string, enabledParsers map[string]bool) (Lockfile, bool) {
+	var entry cachedLockfile
+
+	if hash == "" || !c.readJSON(entryPath, &entry) {
+		return Lockfile{}, false
+	}
+
+	if entry.Version != cacheFormatVersion+"/"+version.OSVVersion || entry.Hash != hash {
+		return Lockfile{}, false
+	}
+
+	if _, extractedAs := FindExtractor(path, extractAs, enabledParsers); extractedAs != entry.ParsedAs {
+		return Lockfile{}, false
+	}
+
+	for dependency, dependencyHash := range entry.Dependencies {
+		if hashFile(dependency) != dependencyHash {
+			return Lockfile{}, false
+		}
+	}
+
+	for dir, listingHash := range entry.Listings {
+		if hashDir(dir) != listingHash {
+			return Lockfile{}, false
+		}
+	}
+
+	// source files that did not exist before might exist now
+	f, err := OpenLocalDepFile(path)
+	if err != nil {
+		return Lockfile{}, false
+	}
+	defer f.Close()
+
+	if !slices.Equal(MatcherSourceFiles(f, entry.ParsedAs), entry.SourceFiles) {
+		return Lockfile{}, false
+	}
+
+	parsedLockfile := entry.Lockfile
+
+	for _, matchErr := range entry.MatchErrors {
+		parsedLockfile.MatchErrors = append(parsedLockfile.MatchErrors, errors.New(matchErr))
+	}
+
+	for _, warning := range entry.Warnings {
+		parsedLockfile.Warnings = append(parsedLockfile.Warnings, errors.New(warning))
+	}
+
+	return parsedLockfile, true
+}
+
+func (c *Cache) readJSON(path string, v any) bool {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+
+	return json.Unmarshal(b, v) == nil
+}
+
+// writeJSON writes to a temporary file and then renames it, so that other
+// processes sharing the cache never read a partially written entry
+func (c *Cache) writeJSON(path string, v any) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+
+	_, err = f.Write(b)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err == nil {
+		err = os.Rename(f.Name(), path)
+	}
+
+	if err != nil {
+		_ = os.Remove(f.Name())
+	}
+
+	return err
+}
+
+// MatcherSourceFiles returns the paths of the source files that the matchers
+// of the given extractor would match the packages of the lockfile against,
+// which includes matchers that do not open their source file using the lockfile
+func MatcherSourceFiles(f DepFile, extractedAs string) []string {
+	extractor, ok := lockfileExtractors[extractedAs].(ExtractorWithMatcher)
+	if !ok {
+		return nil
+	}
+
+	var paths []string
+
+	for _, matcher := range extractor.GetMatchers() {
+		sourceFile, err := matcher.GetSourceFile(f)
+		if err != nil || sourceFile == nil {
+			continue
+		}
+
+		paths = append(paths, sourceFile.Path())
+
+		if closer, ok := sourceFile.(NestedDepFile); ok {
+			_ = closer.Close()
+		}
+	}
+
+	return paths
+}
+
+// depFileRecorder keeps track of the files that are opened and the
+// directories that are listed while extracting
+type depFileRecorder struct {
+	dependencies map[string]string
+	listings     map[string]string
+}
+
+// recordingDepFile is a DepFile that records the other files that are
+// opened and the directories that are listed relative to it, including
+// those that could not be opened
+type recordingDepFile struct {
+	NestedDepFile
+
+	recorder *depFileRecorder
+}
+
+func (f recordingDepFile) Open(path string) (NestedDepFile, error) {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(filepath.Dir(f.Path()), path)
+	}
+
+	f.recorder.dependencies[path] = hashFile(path)
+
+	nested, err := f.NestedDepFile.Open(path)
+	if err != nil {
+		return nested, err
+	}
+
+	return recordingDepFile{nested, f.recorder}, nil
+}
+
+func (f recordingDepFile) ReadDir(path string) ([]fs.DirEntry, error) {
+	dirFile, ok := f.NestedDepFile.(DirDepFile)
+	if !ok {
+		return nil, errors.ErrUnsupported
+	}
+
+	dir := path
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(filepath.Dir(f.Path()), dir)
+	}
+
+	f.recorder.listings[dir] = hashDir(dir)
+
+	return dirFile.ReadDir(path)
+}
@@ -0,0 +1,173 @@
+package lockfile_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/osv-scanner/pkg/lockfile"
+)
+
+func TestCache_ExtractDeps(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	enabledParsers := map[string]bool{"package-lock.json": true}
+
+	lockfilePath := filepath.Join(dir, "package-lock.json")
+	sourceFilePath := filepath.Join(dir, "package.json")
+
+	cache, err := lockfile.NewCache(t.TempDir())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// extracts the lockfile with the cache, checking if the result was
+	// served from the cache and that it is the same as extracting it directly
+	extract := func(wantHit bool) lockfile.Lockfile {
+		t.Helper()
+
+		before := cache.Stats()
+
+		got, err := cache.ExtractDeps(lockfilePath, "", enabledParsers)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if hit := cache.Stats().Hits > before.Hits; hit != wantHit {
+			t.Errorf("expected cache hit to be %v, but was %v", wantHit, hit)
+		}
+
+		f, err := lockfile.OpenLocalDepFile(lockfilePath)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		defer f.Close()
+
+		want, err := lockfile.ExtractDeps(f, "", enabledParsers)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if diff := cmp.Diff(want.Packages, got.Packages); diff != "" {
+			t.Errorf("cached extraction differs from extracting directly (-want +got):\n%s", diff)
+		}
+
+		return got
+	}
+
+	copyFile(t, "fixtures/package-json/one-package/npm-v2.json", lockfilePath)
+
+	extract(false)
+	if got := extract(true); got.Packages[0].NameLocation != nil {
+		t.Errorf("expected package to not be matched without a package.json")
+	}
+
+	// adding the source file that packages are matched against invalidates the entry
+	copyFile(t, "fixtures/package-json/one-package/package.json", sourceFilePath)
+
+	extract(false)
+	if got := extract(true); got.Packages[0].NameLocation == nil {
+		t.Errorf("expected package to be matched against the package.json")
+	}
+
+	// as does changing it
+	if err := os.WriteFile(sourceFilePath, []byte(`{ "devDependencies": { "lodash": "^4.0.0" } }`), 0600); err != nil {
+		t.Fatalf("could not write file: %v", err)
+	}
+
+	extract(false)
+	extract(true)
+
+	// and changing the lockfile itself
+	copyFile(t, "fixtures/package-json/transitive/npm-v2.json", lockfilePath)
+
+	extract(false)
+	extract(true)
+
+	// and removing the source file
+	if err := os.Remove(sourceFilePath); err != nil {
+		t.Fatalf("could not remove file: %v", err)
+	}
+
+	extract(false)
+	extract(true)
+}
+
+func TestCache_ExtractDeps_Warnings(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	lockfilePath := filepath.Join(dir, "go.mod")
+	enabledParsers := map[string]bool{"go.mod": true}
+
+	copyFile(t, "fixtures/go/without-supported-versioning.mod", lockfilePath)
+
+	cache, err := lockfile.NewCache(t.TempDir())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want, err := cache.ExtractDeps(lockfilePath, "", enabledParsers)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(want.Warnings) == 0 {
+		t.Fatalf("expected extracting the lockfile to have warnings")
+	}
+
+	got, err := cache.ExtractDeps(lockfilePath, "", enabledParsers)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if stats := cache.Stats(); stats.Hits != 1 {
+		t.Errorf("expected the lockfile to be served from the cache, got %+v", stats)
+	}
+
+	if diff := cmp.Diff(want.Warnings, got.Warnings, cmp.Comparer(func(a, b error) bool {
+		return a.Error() == b.Error()
+	})); diff != "" {
+		t.Errorf("cached warnings differ from extracting (-want +got):\n%s", diff)
+	}
+}
+
+func TestCache_ExtractDeps_DirectoryListings(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	lockfilePath := filepath.Join(dir, "packages.lock.json")
+	enabledParsers := map[string]bool{"packages.lock.json": true}
+
+	copyFile(t, "fixtures/nuget/one-framework-one-package-with-csproj/packages.lock.json", lockfilePath)
+
+	cache, err := lockfile.NewCache(t.TempDir())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	extract := func(wantHit bool) {
+		t.Helper()
+
+		before := cache.Stats()
+
+		if _, err := cache.ExtractDeps(lockfilePath, "", enabledParsers); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if hit := cache.Stats().Hits > before.Hits; hit != wantHit {
+			t.Errorf("expected cache hit to be %v, but was %v", wantHit, hit)
+		}
+	}
+
+	extract(false)
+	extract(true)
+
+	// a new project file in the directory that is listed invalidates the entry
+	copyFile(t, "fixtures/nuget/one-framework-one-package-with-csproj/project.csproj", filepath.Join(dir, "project.csproj"))
+
+	extract(false)
+	extract(true)
+}
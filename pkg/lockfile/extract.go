@@ -3,7 +3,6 @@ package lockfile
 import (
 	"errors"
 	"fmt"
-	"os"
 	"sort"
 	"strings"
 )
@@ -64,7 +63,7 @@ func ExtractDeps(f DepFile, extractAs string, enabledParsers map[string]bool) (L
 		return Lockfile{}, fmt.Errorf("%w for %s", ErrExtractorNotFound, f.Path())
 	}
 
-	packages, err := extractor.Extract(f)
+	packages, warnings, err := extract(f, extractor)
 
 	if err != nil && extractedAs != "" {
 		//nolint:all
@@ -72,16 +71,7 @@ func ExtractDeps(f DepFile, extractAs string, enabledParsers map[string]bool) (L
 	}
 
 	// Match extracted packages with source file to enrich their details
-	if e, ok := extractor.(ExtractorWithMatcher); ok {
-		if matchers := e.GetMatchers(); len(matchers) > 0 {
-			for _, matcher := range matchers {
-				matchError := matchWithFile(f, packages, matcher)
-				if matchError != nil {
-					_, _ = fmt.Fprintf(os.Stderr, "there was an error matching the source file %s: %s\n", f.Path(), matchError.Error())
-				}
-			}
-		}
-	}
+	matchErrors := matchPackages(f, packages, extractor)
 
 	sort.Slice(packages, func(i, j int) bool {
 		if packages[i].Name == packages[j].Name {
@@ -92,12 +82,15 @@ func ExtractDeps(f DepFile, extractAs string, enabledParsers map[string]bool) (L
 	})
 
 	parsedLockfile := Lockfile{
-		FilePath: f.Path(),
-		ParsedAs: extractedAs,
-		Packages: packages,
+		FilePath:    f.Path(),
+		ParsedAs:    extractedAs,
+		Packages:    packages,
+		MatchErrors: matchErrors,
+		Warnings:    warnings,
 	}
 
-	depFile, err := OpenLocalDepFile(f.Path())
+	// reopened through the file itself so that files within archives work too
+	depFile, err := f.Open(f.Path())
 	if err != nil {
 		return parsedLockfile, err
 	}
@@ -3,6 +3,7 @@ package lockfile
 import (
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
 
@@ -34,6 +35,15 @@ type NestedDepFile interface {
 	DepFile
 }
 
+// DirDepFile is a DepFile that can also list the files in a directory based
+// on the path of the current DepFile, for matchers that look for source files
+// without knowing their exact name.
+type DirDepFile interface {
+	DepFile
+
+	ReadDir(path string) ([]fs.DirEntry, error)
+}
+
 type Extractor interface {
 	// ShouldExtract checks if the Extractor should be used for the given path.
 	ShouldExtract(path string) bool
@@ -49,6 +59,26 @@ type ExtractorWithMatcher interface {
 	GetMatchers() []Matcher
 }
 
+// ExtractorWithWarnings is an Extractor that can find problems which do not
+// stop the packages from being extracted, such as versions that could not be
+// resolved, which Extract ignores
+type ExtractorWithWarnings interface {
+	Extractor
+	ExtractWithWarnings(f DepFile) ([]PackageDetails, []error, error)
+}
+
+// extract extracts the packages from the file, along with any warnings if
+// the extractor can find them
+func extract(f DepFile, extractor Extractor) ([]PackageDetails, []error, error) {
+	if e, ok := extractor.(ExtractorWithWarnings); ok {
+		return e.ExtractWithWarnings(f)
+	}
+
+	packages, err := extractor.Extract(f)
+
+	return packages, nil, err
+}
+
 type ArtifactExtractor interface {
 	GetArtifact(f DepFile) (*models.ScannedArtifact, error)
 }
@@ -73,6 +103,14 @@ func (f LocalFile) Open(path string) (NestedDepFile, error) {
 	return OpenLocalDepFile(filepath.Join(filepath.Dir(f.path), path))
 }
 
+func (f LocalFile) ReadDir(path string) ([]fs.DirEntry, error) {
+	if filepath.IsAbs(path) {
+		return os.ReadDir(path)
+	}
+
+	return os.ReadDir(filepath.Join(filepath.Dir(f.path), path))
+}
+
 func (f LocalFile) Path() string { return f.path }
 
 func OpenLocalDepFile(path string) (NestedDepFile, error) {
@@ -94,32 +132,48 @@ func OpenLocalDepFile(path string) (NestedDepFile, error) {
 
 var _ DepFile = LocalFile{}
 var _ NestedDepFile = LocalFile{}
+var _ DirDepFile = LocalFile{}
 
 func ExtractFromFile(pathToLockfile string, extractor Extractor) ([]PackageDetails, error) {
+	packages, _, err := ExtractFromFileWithMatchErrors(pathToLockfile, extractor)
+
+	return packages, err
+}
+
+// ExtractFromFileWithMatchErrors is like ExtractFromFile, but also returns the
+// errors from matching the packages against their source files, which are
+// otherwise ignored as they only mean some packages are missing locations
+func ExtractFromFileWithMatchErrors(pathToLockfile string, extractor Extractor) ([]PackageDetails, []error, error) {
 	f, err := OpenLocalDepFile(pathToLockfile)
 
 	if err != nil {
-		return []PackageDetails{}, err
+		return []PackageDetails{}, nil, err
 	}
 
 	defer f.Close()
 
 	packages, err := extractor.Extract(f)
 	if err != nil {
-		return []PackageDetails{}, err
+		return []PackageDetails{}, nil, err
+	}
+
+	return packages, matchPackages(f, packages, extractor), nil
+}
+
+// matchPackages matches the extracted packages with their source files to
+// enrich their details, if the extractor has any matchers
+func matchPackages(f DepFile, packages []PackageDetails, extractor Extractor) []error {
+	e, ok := extractor.(ExtractorWithMatcher)
+	if !ok {
+		return nil
 	}
 
-	// Match extracted packages with source file to enrich their details
-	if e, ok := extractor.(ExtractorWithMatcher); ok {
-		if matchers := e.GetMatchers(); len(matchers) > 0 {
-			for _, matcher := range matchers {
-				matchError := matchWithFile(f, packages, matcher)
-				if matchError != nil {
-					_, _ = fmt.Fprintf(os.Stderr, "there was an error matching the source file %s: %s\n", pathToLockfile, matchError.Error())
-				}
-			}
+	var errs []error
+	for _, matcher := range e.GetMatchers() {
+		if err := matchWithFile(f, packages, matcher); err != nil {
+			errs = append(errs, fmt.Errorf("there was an error matching the source file %s: %w", f.Path(), err))
 		}
 	}
 
-	return packages, nil
+	return errs
 }
@@ -0,0 +1,97 @@
+package lockfile
+
+import (
+	"io"
+	"io/fs"
+	"path"
+	"path/filepath"
+
+	"github.com/google/osv-scanner/internal/utility/fspath"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+)
+
+// A FSFile represents a file within an fs.FS, such as an embed.FS, a
+// fstest.MapFS or a zip.Reader, which opens other files from the same
+// filesystem.
+//
+// Paths are always absolute, with "/" being the root of the filesystem.
+type FSFile struct {
+	io.Reader
+
+	file fs.File
+	fsys fs.FS
+	name string
+}
+
+// fsFileWithReaderAt is used for files that support random access, which
+// some extractors (such as for Go binaries) use to avoid reading everything
+type fsFileWithReaderAt struct {
+	FSFile
+	io.ReaderAt
+}
+
+// OpenFSDepFile opens the file at the given path within the filesystem
+func OpenFSDepFile(fsys fs.FS, p string) (NestedDepFile, error) {
+	name := fspath.Name(p)
+
+	file, err := fsys.Open(name)
+	if err != nil {
+		return FSFile{}, err
+	}
+
+	// We apply a decoder on it to avoid issues with utf-16, like local files
+	decodedReader := transform.NewReader(file, unicode.BOMOverride(encoding.Nop.NewDecoder()))
+
+	f := FSFile{Reader: decodedReader, file: file, fsys: fsys, name: name}
+
+	if readerAt, ok := file.(io.ReaderAt); ok {
+		return fsFileWithReaderAt{f, readerAt}, nil
+	}
+
+	return f, nil
+}
+
+// resolve returns the path of the given path relative to the directory of the file
+func (f FSFile) resolve(p string) string {
+	p = filepath.ToSlash(p)
+
+	if path.IsAbs(p) {
+		return p
+	}
+
+	return path.Join(path.Dir(f.Path()), p)
+}
+
+func (f FSFile) Open(p string) (NestedDepFile, error) {
+	return OpenFSDepFile(f.fsys, f.resolve(p))
+}
+
+func (f FSFile) ReadDir(p string) ([]fs.DirEntry, error) {
+	return fs.ReadDir(f.fsys, fspath.Name(f.resolve(p)))
+}
+
+func (f FSFile) Path() string {
+	if f.fsys == nil {
+		return ""
+	}
+
+	if f.name == "." {
+		return "/"
+	}
+
+	return "/" + f.name
+}
+
+func (f FSFile) Close() error {
+	if f.file == nil {
+		return nil
+	}
+
+	return f.file.Close()
+}
+
+var _ NestedDepFile = FSFile{}
+var _ DirDepFile = FSFile{}
+var _ DirDepFile = fsFileWithReaderAt{}
@@ -0,0 +1,80 @@
+package lockfile_test
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	"github.com/google/osv-scanner/pkg/lockfile"
+)
+
+func TestOpenFSDepFile(t *testing.T) {
+	t.Parallel()
+
+	fsys := fstest.MapFS{
+		"app/Gemfile.lock":   {Data: []byte("lockfile")},
+		"app/my-app.gemspec": {Data: []byte("gemspec")},
+		"app/vendor/Gemfile": {Data: []byte("vendored")},
+		"build.gradle":       {Data: []byte("gradle")},
+		"app/gradle/.keep":   {Data: []byte("")},
+	}
+
+	f, err := lockfile.OpenFSDepFile(fsys, "/app/Gemfile.lock")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer f.Close()
+
+	if f.Path() != "/app/Gemfile.lock" {
+		t.Errorf("expected path to be /app/Gemfile.lock, got %s", f.Path())
+	}
+
+	b, err := io.ReadAll(f)
+	if err != nil || string(b) != "lockfile" {
+		t.Errorf("expected to read %q, got %q (%v)", "lockfile", b, err)
+	}
+
+	for name, want := range map[string]string{
+		"vendor/Gemfile":  "/app/vendor/Gemfile",
+		"../build.gradle": "/build.gradle",
+		"/build.gradle":   "/build.gradle",
+		// paths never go above the root of the filesystem
+		"../../build.gradle": "/build.gradle",
+	} {
+		nested, err := f.Open(name)
+		if err != nil {
+			t.Errorf("Open(%q) unexpected error: %v", name, err)
+
+			continue
+		}
+
+		if nested.Path() != want {
+			t.Errorf("Open(%q).Path() = %q, want %q", name, nested.Path(), want)
+		}
+		nested.Close()
+	}
+
+	if _, err := f.Open("Gemfile"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected opening a missing file to not exist, got %v", err)
+	}
+
+	entries, err := f.(lockfile.DirDepFile).ReadDir(".")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(entries) != 4 {
+		t.Errorf("expected 4 entries in /app, got %d", len(entries))
+	}
+
+	sourceFile, err := lockfile.GemspecFileMatcher{}.GetSourceFile(f)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if sourceFile.Path() != "/app/my-app.gemspec" {
+		t.Errorf("expected gemspec matcher to find /app/my-app.gemspec, got %s", sourceFile.Path())
+	}
+}
@@ -3,7 +3,6 @@ package lockfile
 import (
 	"encoding/json"
 	"io"
-	"path/filepath"
 
 	jsonUtils "github.com/google/osv-scanner/internal/json"
 )
@@ -51,11 +50,7 @@ func (depMap *ComposerMatcherDependencyMap) UnmarshalJSON(bytes []byte) error {
 }
 
 func (matcher ComposerMatcher) GetSourceFile(lockfile DepFile) (DepFile, error) {
-	lockfileDir := filepath.Dir(lockfile.Path())
-	sourceFilePath := filepath.Join(lockfileDir, composerFilename)
-	file, err := OpenLocalDepFile(sourceFilePath)
-
-	return file, err
+	return lockfile.Open(composerFilename)
 }
 
 /*
@@ -1,8 +1,7 @@
 package lockfile
 
 import (
-	"log"
-	"path/filepath"
+	"fmt"
 
 	"github.com/google/osv-scanner/pkg/models"
 )
@@ -34,11 +33,7 @@ type gemMetadata struct {
 type GemfileMatcher struct{}
 
 func (matcher GemfileMatcher) GetSourceFile(lockfile DepFile) (DepFile, error) {
-	lockfileDir := filepath.Dir(lockfile.Path())
-	sourceFilePath := filepath.Join(lockfileDir, gemfileFilename)
-	file, err := OpenLocalDepFile(sourceFilePath)
-
-	return file, err
+	return lockfile.Open(gemfileFilename)
 }
 
 func (matcher GemfileMatcher) Match(sourceFile DepFile, packages []PackageDetails) error {
@@ -54,13 +49,17 @@ func (matcher GemfileMatcher) Match(sourceFile DepFile, packages []PackageDetail
 	if err != nil {
 		return err
 	}
-	enrichPackagesWithLocation(sourceFile, rootGems, packagesByName)
+	skipped := enrichPackagesWithLocation(sourceFile, rootGems, packagesByName)
 
 	remainingGems, err := findGroupedGems(treeResult.Node)
 	if err != nil {
 		return err
 	}
-	enrichPackagesWithLocation(sourceFile, remainingGems, packagesByName)
+	skipped = append(skipped, enrichPackagesWithLocation(sourceFile, remainingGems, packagesByName)...)
+
+	if len(skipped) > 0 {
+		return fmt.Errorf("skipped packages %q from Gemfile as they do not exist in the Gemfile.lock", skipped)
+	}
 
 	return nil
 }
@@ -220,13 +219,17 @@ func indexPackages(packages []PackageDetails) map[string]*PackageDetails {
 	return result
 }
 
-func enrichPackagesWithLocation(sourceFile DepFile, gems []gemMetadata, packagesByName map[string]*PackageDetails) {
+// enrichPackagesWithLocation sets the locations of the packages from the gems
+// found in the Gemfile, returning the names of the gems that were skipped
+func enrichPackagesWithLocation(sourceFile DepFile, gems []gemMetadata, packagesByName map[string]*PackageDetails) []string {
+	var skipped []string
+
 	for _, gem := range gems {
 		pkg, ok := packagesByName[gem.name]
 		// If packages exist in the Gemfile but not in the Gemfile.lock, we skip the package as we treat the lockfile as
 		// the source of truth
 		if !ok {
-			log.Printf("Skipping package %q from Gemfile as it does not exist in the Gemfile.lock\n", gem.name)
+			skipped = append(skipped, gem.name)
 			continue
 		}
 
@@ -251,4 +254,6 @@ func enrichPackagesWithLocation(sourceFile DepFile, gems []gemMetadata, packages
 			pkg.DepGroups = gem.groups
 		}
 	}
+
+	return skipped
 }
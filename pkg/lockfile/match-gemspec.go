@@ -1,9 +1,7 @@
 package lockfile
 
 import (
-	"log"
-	"os"
-	"path/filepath"
+	"fmt"
 	"strings"
 
 	"github.com/google/osv-scanner/pkg/models"
@@ -25,16 +23,19 @@ type gemspecMetadata struct {
 type GemspecFileMatcher struct{}
 
 func (matcher GemspecFileMatcher) GetSourceFile(lockfile DepFile) (DepFile, error) {
-	var dir = filepath.Dir(lockfile.Path())
+	dirLockfile, ok := lockfile.(DirDepFile)
+	if !ok {
+		return nil, nil
+	}
 
-	var dirs, err = os.ReadDir(dir)
+	var dirs, err = dirLockfile.ReadDir(".")
 	if err != nil {
 		return nil, err
 	}
 
 	for _, file := range dirs {
 		if strings.HasSuffix(file.Name(), gemspecFileSuffix) {
-			return OpenLocalDepFile(filepath.Join(dir, file.Name()))
+			return lockfile.Open(file.Name())
 		}
 	}
 
@@ -55,11 +56,74 @@ func (matcher GemspecFileMatcher) Match(sourceFile DepFile, packages []PackageDe
 	if err != nil {
 		return err
 	}
-	matcher.enrichPackagesWithLocation(sourceFile, gems, packagesByName)
+	skipped := matcher.enrichPackagesWithLocation(sourceFile, gems, packagesByName)
+
+	name, licenses, err := matcher.findLicenses(treeResult.Node)
+	if err != nil {
+		return err
+	}
+
+	// the gem of the gemspec itself is in the Gemfile.lock if it is bundled from its path
+	if pkg, ok := packagesByName[name]; ok && len(licenses) > 0 {
+		pkg.Licenses = licenses
+	}
+
+	if len(skipped) > 0 {
+		return fmt.Errorf("skipped packages %q from gemspec as they do not exist in the Gemfile.lock", skipped)
+	}
 
 	return nil
 }
 
+// findLicenses returns the name of the gem that is specified by the gemspec
+// along with the licenses that it declares, which can be used under any of them
+func (matcher GemspecFileMatcher) findLicenses(node *Node) (string, []string, error) {
+	attributeQuery := `(
+		(assignment
+			left: (call
+				receiver: (identifier)
+				method: (identifier) @attribute
+				(#any-of? @attribute "name" "license" "licenses"))
+			right: [(string) (array)] @value
+		)
+	)`
+
+	var name string
+	var licenses []string
+
+	err := node.Query(attributeQuery, func(match *MatchResult) error {
+		attributeNode := match.FindFirstByName("attribute")
+		attribute, err := node.Ctx.ExtractTextValue(attributeNode.TSNode)
+		if err != nil {
+			return err
+		}
+
+		values, err := node.Ctx.ExtractTextValues(match.FindFirstByName("value").TSNode)
+		if err != nil {
+			return err
+		}
+
+		if attribute == "name" {
+			if len(values) > 0 {
+				name = values[0]
+			}
+		} else {
+			licenses = values
+		}
+
+		return nil
+	})
+	if err != nil {
+		return "", nil, err
+	}
+
+	if len(licenses) > 1 {
+		licenses = []string{"(" + strings.Join(licenses, " OR ") + ")"}
+	}
+
+	return name, licenses, nil
+}
+
 func (matcher GemspecFileMatcher) findGemspecs(node *Node) ([]gemspecMetadata, error) {
 	// Matches method calls to add_dependency, add_runtime_dependency and add_development_dependency
 	// extracting the gem dependency name and gem dependency requirements
@@ -133,13 +197,17 @@ func (matcher GemspecFileMatcher) findGemspecs(node *Node) ([]gemspecMetadata, e
 	return gems, nil
 }
 
-func (matcher GemspecFileMatcher) enrichPackagesWithLocation(sourceFile DepFile, gems []gemspecMetadata, packagesByName map[string]*PackageDetails) {
+// enrichPackagesWithLocation sets the locations of the packages from the gems
+// found in the gemspec, returning the names of the gems that were skipped
+func (matcher GemspecFileMatcher) enrichPackagesWithLocation(sourceFile DepFile, gems []gemspecMetadata, packagesByName map[string]*PackageDetails) []string {
+	var skipped []string
+
 	for _, gem := range gems {
 		pkg, ok := packagesByName[gem.name]
 		// If packages exist in a .gemspec but not in the Gemfile.lock, we skip the package as we treat the lockfile as
 		// the source of truth
 		if !ok {
-			log.Printf("Skipping package %q from gemspec as it does not exist in the Gemfile.lock\n", gem.name)
+			skipped = append(skipped, gem.name)
 			continue
 		}
 
@@ -164,4 +232,6 @@ func (matcher GemspecFileMatcher) enrichPackagesWithLocation(sourceFile DepFile,
 			pkg.DepGroups = []string{string(DepGroupDev)}
 		}
 	}
+
+	return skipped
 }
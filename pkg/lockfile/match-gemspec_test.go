@@ -3,6 +3,7 @@ package lockfile_test
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/google/osv-scanner/internal/testutility"
@@ -64,6 +65,11 @@ func TestGemspecFileMatcher_Match(t *testing.T) {
 			Version:        "0.2.0",
 			PackageManager: models.Bundler,
 		},
+		{
+			Name:           "dependency_spy",
+			Version:        "0.1.0",
+			PackageManager: models.Bundler,
+		},
 		{
 			Name:           "json",
 			Version:        "2.9.1",
@@ -126,8 +132,8 @@ func TestGemspecFileMatcher_NotInLockfile(t *testing.T) {
 	}
 
 	err = gemspecFileMatcher.Match(sourceFile, packages)
-	if err != nil {
-		t.Errorf("Got unexpected error: %v", err)
+	if err == nil || !strings.Contains(err.Error(), `"nonexistent_gem"`) {
+		t.Errorf("Expected an error about skipping nonexistent_gem, got %v", err)
 	}
 
 	testutility.NewSnapshot().WithJSONNormalization().MatchJSON(t, packages)
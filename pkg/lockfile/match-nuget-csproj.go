@@ -2,14 +2,11 @@ package lockfile
 
 import (
 	"encoding/xml"
-	"errors"
 	"io"
-	"os"
 
 	"github.com/google/osv-scanner/internal/utility/fileposition"
 	"github.com/google/osv-scanner/pkg/models"
 
-	"path/filepath"
 	"strings"
 )
 
@@ -78,20 +75,24 @@ DecodingLoop:
 }
 
 func (m NugetCsprojMatcher) GetSourceFile(lockfile DepFile) (DepFile, error) {
-	var dir = filepath.Dir(lockfile.Path())
+	dirLockfile, ok := lockfile.(DirDepFile)
+	if !ok {
+		return nil, nil
+	}
 
-	var dirs, err = os.ReadDir(dir)
+	var dirs, err = dirLockfile.ReadDir(".")
 	if err != nil {
 		return nil, err
 	}
 
 	for _, file := range dirs {
 		if strings.HasSuffix(file.Name(), ".csproj") {
-			return OpenLocalDepFile(filepath.Join(dir, file.Name()))
+			return lockfile.Open(file.Name())
 		}
 	}
 
-	return nil, errors.New("no csproj file found")
+	// the packages are not required to come from a .csproj, so it is fine if there is none
+	return nil, nil
 }
 
 func (m NugetCsprojMatcher) unmarshalProjectFile(content []byte) (map[string]PackageReference, error) {
@@ -9,6 +9,7 @@ import (
 
 	"github.com/google/osv-scanner/pkg/lockfile"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 var nugetCsprojMatcher = lockfile.NugetCsprojMatcher{}
@@ -22,7 +23,7 @@ func TestNugetCsprojMatcher_GetSourceFile_FileDoesNotExist(t *testing.T) {
 	}
 
 	sourceFile, err := nugetCsprojMatcher.GetSourceFile(lockFile)
-	assert.Equal(t, "no csproj file found", err.Error())
+	require.NoError(t, err)
 	assert.Nil(t, sourceFile)
 }
 
@@ -1,5 +1,10 @@
 package lockfile
 
+import (
+	"path/filepath"
+	"slices"
+)
+
 type Matcher interface {
 	GetSourceFile(lockfile DepFile) (DepFile, error)
 	Match(sourceFile DepFile, packages []PackageDetails) error
@@ -17,3 +22,30 @@ func matchWithFile(lockfile DepFile, packages []PackageDetails, matcher Matcher)
 
 	return matcher.Match(sourceFile, packages)
 }
+
+// sourceFileNames are the names of the files that matchers and extractors
+// open alongside a lockfile, other than lockfiles themselves
+var sourceFileNames = []string{
+	"package.json",
+	"composer.json",
+	"Gemfile",
+	"build.gradle",
+	"build.gradle.kts",
+	"pyproject.toml",
+	"Pipfile",
+	"Cargo.toml",
+}
+
+// sourceFileExtensions are the extensions of the files that matchers and
+// extractors open alongside a lockfile, including the requirements files
+// that are included by others regardless of their names
+var sourceFileExtensions = []string{".gemspec", ".csproj", ".txt"}
+
+// IsSourceFile reports if the file at the given path could be opened by a
+// matcher or extractor when extracting a lockfile near it, such as the
+// package.json of a package-lock.json
+func IsSourceFile(path string) bool {
+	base := filepath.Base(path)
+
+	return slices.Contains(sourceFileNames, base) || slices.Contains(sourceFileExtensions, filepath.Ext(base))
+}
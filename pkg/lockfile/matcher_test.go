@@ -0,0 +1,28 @@
+package lockfile_test
+
+import (
+	"testing"
+
+	"github.com/google/osv-scanner/pkg/lockfile"
+)
+
+func TestIsSourceFile(t *testing.T) {
+	t.Parallel()
+
+	for p, want := range map[string]bool{
+		"app/package.json":              true,
+		"Gemfile":                       true,
+		"lib/my-gem.gemspec":            true,
+		"src/App/App.csproj":            true,
+		"build.gradle.kts":              true,
+		"requirements/base.txt":         true,
+		"app/package-lock.json":         false,
+		"bin/server":                    false,
+		"docs/README.md":                false,
+		"node_modules/lodash/lodash.js": false,
+	} {
+		if got := lockfile.IsSourceFile(p); got != want {
+			t.Errorf("IsSourceFile(%q) = %v, want %v", p, got, want)
+		}
+	}
+}
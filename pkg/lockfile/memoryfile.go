@@ -0,0 +1,91 @@
+package lockfile
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+)
+
+// A MemoryFile represents a file that has been read into memory from
+// somewhere other than a filesystem, such as a lockfile piped into stdin,
+// and which is reported using a synthetic path.
+//
+// Other files are opened relative to the source directory on the local
+// filesystem if one was provided, otherwise they are treated as not
+// existing, which effectively disables matchers.
+type MemoryFile struct {
+	io.Reader
+
+	content   []byte
+	path      string
+	sourceDir string
+}
+
+// ReadDepFile reads the whole of the given reader into memory so that it can
+// be extracted (and reopened) like any other file, using the given synthetic
+// path for reporting and optionally a directory to open source files from
+func ReadDepFile(r io.Reader, path string, sourceDir string) (NestedDepFile, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return MemoryFile{}, err
+	}
+
+	return newMemoryFile(content, path, sourceDir), nil
+}
+
+func newMemoryFile(content []byte, path string, sourceDir string) MemoryFile {
+	// We apply a decoder on it to avoid issues with utf-16, like local files
+	decodedReader := transform.NewReader(bytes.NewReader(content), unicode.BOMOverride(encoding.Nop.NewDecoder()))
+
+	return MemoryFile{Reader: decodedReader, content: content, path: path, sourceDir: sourceDir}
+}
+
+// resolve returns the path of the given path within the source directory,
+// or an error if there is no source directory to open files from
+func (f MemoryFile) resolve(op string, path string) (string, error) {
+	if f.sourceDir == "" {
+		return "", &fs.PathError{Op: op, Path: path, Err: fs.ErrNotExist}
+	}
+
+	if filepath.IsAbs(path) {
+		return path, nil
+	}
+
+	return filepath.Join(f.sourceDir, path), nil
+}
+
+func (f MemoryFile) Open(path string) (NestedDepFile, error) {
+	// this is how extractors reopen the file itself, which is not on disk
+	if path == f.path {
+		return newMemoryFile(f.content, f.path, f.sourceDir), nil
+	}
+
+	path, err := f.resolve("open", path)
+	if err != nil {
+		return LocalFile{}, err
+	}
+
+	return OpenLocalDepFile(path)
+}
+
+func (f MemoryFile) ReadDir(path string) ([]fs.DirEntry, error) {
+	path, err := f.resolve("readdir", path)
+	if err != nil {
+		return nil, err
+	}
+
+	return os.ReadDir(path)
+}
+
+func (f MemoryFile) Path() string { return f.path }
+
+func (f MemoryFile) Close() error { return nil }
+
+var _ NestedDepFile = MemoryFile{}
+var _ DirDepFile = MemoryFile{}
@@ -0,0 +1,78 @@
+package lockfile_test
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/osv-scanner/pkg/lockfile"
+)
+
+func TestReadDepFile(t *testing.T) {
+	t.Parallel()
+
+	content, err := os.ReadFile("fixtures/package-json/one-package/npm-v2.json")
+	if err != nil {
+		t.Fatalf("could not read fixture: %v", err)
+	}
+
+	sourceDir, err := filepath.Abs("fixtures/package-json/one-package")
+	if err != nil {
+		t.Fatalf("could not resolve source directory: %v", err)
+	}
+
+	f, err := lockfile.ReadDepFile(strings.NewReader(string(content)), "<stdin>", sourceDir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer f.Close()
+
+	parsedLockfile, err := lockfile.ExtractDeps(f, "package-lock.json", map[string]bool{"package-lock.json": true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if parsedLockfile.FilePath != "<stdin>" {
+		t.Errorf("expected lockfile path to be <stdin>, got %s", parsedLockfile.FilePath)
+	}
+
+	if len(parsedLockfile.Packages) != 1 {
+		t.Fatalf("expected one package, got %d", len(parsedLockfile.Packages))
+	}
+
+	want := filepath.Join(sourceDir, "package.json")
+	if location := parsedLockfile.Packages[0].NameLocation; location == nil || location.Filename != want {
+		t.Errorf("expected package to be matched against %s, got %v", want, location)
+	}
+}
+
+func TestReadDepFile_WithoutSourceDir(t *testing.T) {
+	t.Parallel()
+
+	f, err := lockfile.ReadDepFile(strings.NewReader("django==4.2.0\n"), "<stdin>", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Open("package.json"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected files to not exist without a source directory, got %v", err)
+	}
+
+	if _, err := f.(lockfile.DirDepFile).ReadDir("."); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected directories to not exist without a source directory, got %v", err)
+	}
+
+	// the file itself can always be reopened, even once it has been read
+	parsedLockfile, err := lockfile.ExtractDeps(f, "requirements.txt", map[string]bool{"requirements.txt": true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(parsedLockfile.Packages) != 1 || parsedLockfile.Packages[0].Name != "django" {
+		t.Errorf("expected only django to be extracted, got %v", parsedLockfile.Packages)
+	}
+}
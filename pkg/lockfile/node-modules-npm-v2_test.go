@@ -225,6 +225,7 @@ func TestNodeModulesExtractor_Extract_npm_v2_Commits(t *testing.T) {
 			Ecosystem:      lockfile.NpmEcosystem,
 			CompareAs:      lockfile.NpmEcosystem,
 			Commit:         "3b1bb80b302c2e552685dc8a029797ec832ea7c9",
+			Licenses:       []string{"SEE LICENSE IN LICENSE"},
 			DepGroups:      []string{"prod"},
 		},
 		{
@@ -244,6 +245,7 @@ func TestNodeModulesExtractor_Extract_npm_v2_Commits(t *testing.T) {
 			Ecosystem:      lockfile.NpmEcosystem,
 			CompareAs:      lockfile.NpmEcosystem,
 			Commit:         "c5a7ba5e0ad98b8db1cb8ce105403dd4b768cced",
+			Licenses:       []string{"MIT"},
 			DepGroups:      []string{"dev"},
 		},
 		{
@@ -254,6 +256,7 @@ func TestNodeModulesExtractor_Extract_npm_v2_Commits(t *testing.T) {
 			Ecosystem:      lockfile.NpmEcosystem,
 			CompareAs:      lockfile.NpmEcosystem,
 			Commit:         "af885e2e890b9ef0875edd2b117305119ee5bdc5",
+			Licenses:       []string{"MIT"},
 			DepGroups:      []string{"dev"},
 		},
 		{
@@ -263,6 +266,7 @@ func TestNodeModulesExtractor_Extract_npm_v2_Commits(t *testing.T) {
 			Ecosystem:      lockfile.NpmEcosystem,
 			CompareAs:      lockfile.NpmEcosystem,
 			Commit:         "be5935f8d2595bcd97b05718ef1eeae08d812e10",
+			Licenses:       []string{"MIT"},
 			DepGroups:      []string{"dev"},
 			IsDirect:       false,
 		},
@@ -274,6 +278,7 @@ func TestNodeModulesExtractor_Extract_npm_v2_Commits(t *testing.T) {
 			Ecosystem:      lockfile.NpmEcosystem,
 			CompareAs:      lockfile.NpmEcosystem,
 			Commit:         "d5ac0584ee9ae7bd9288220a39780f155b9ad4c8",
+			Licenses:       []string{"MIT"},
 			DepGroups:      []string{"dev"},
 		},
 		{
@@ -283,6 +288,7 @@ func TestNodeModulesExtractor_Extract_npm_v2_Commits(t *testing.T) {
 			Ecosystem:      lockfile.NpmEcosystem,
 			CompareAs:      lockfile.NpmEcosystem,
 			Commit:         "82dcc8e914dabd9305ab9ae580709a7825e824f5",
+			Licenses:       []string{"MIT"},
 			DepGroups:      []string{"dev"},
 			IsDirect:       false,
 		},
@@ -294,6 +300,7 @@ func TestNodeModulesExtractor_Extract_npm_v2_Commits(t *testing.T) {
 			Ecosystem:      lockfile.NpmEcosystem,
 			CompareAs:      lockfile.NpmEcosystem,
 			Commit:         "d5ac0584ee9ae7bd9288220a39780f155b9ad4c8",
+			Licenses:       []string{"MIT"},
 			DepGroups:      []string{"dev"},
 		},
 		{
@@ -303,6 +310,7 @@ func TestNodeModulesExtractor_Extract_npm_v2_Commits(t *testing.T) {
 			Ecosystem:      lockfile.NpmEcosystem,
 			CompareAs:      lockfile.NpmEcosystem,
 			Commit:         "82ae8802978da40d7f1be5ad5943c9e550ab2c89",
+			Licenses:       []string{"MIT"},
 			DepGroups:      []string{"dev"},
 			IsDirect:       false,
 		},
@@ -314,6 +322,7 @@ func TestNodeModulesExtractor_Extract_npm_v2_Commits(t *testing.T) {
 			Ecosystem:      lockfile.NpmEcosystem,
 			CompareAs:      lockfile.NpmEcosystem,
 			Commit:         "af885e2e890b9ef0875edd2b117305119ee5bdc5",
+			Licenses:       []string{"MIT"},
 			DepGroups:      []string{"dev"},
 		},
 		{
@@ -324,6 +333,7 @@ func TestNodeModulesExtractor_Extract_npm_v2_Commits(t *testing.T) {
 			Ecosystem:      lockfile.NpmEcosystem,
 			CompareAs:      lockfile.NpmEcosystem,
 			Commit:         "af885e2e890b9ef0875edd2b117305119ee5bdc5",
+			Licenses:       []string{"MIT"},
 			DepGroups:      []string{"dev"},
 		},
 		{
@@ -353,6 +363,7 @@ func TestNodeModulesExtractor_Extract_npm_v2_Commits(t *testing.T) {
 			Ecosystem:      lockfile.NpmEcosystem,
 			CompareAs:      lockfile.NpmEcosystem,
 			Commit:         "280b560161b751ba226d50c7db1e0a14a78c2de0",
+			Licenses:       []string{"MIT"},
 			DepGroups:      []string{"dev"},
 		},
 	})
@@ -465,3 +476,20 @@ func TestNodeModulesExtractor_Extract_npm_v2_OptionalPackage(t *testing.T) {
 		},
 	})
 }
+
+func TestNodeModulesExtractor_Extract_npm_v2_Licenses(t *testing.T) {
+	t.Parallel()
+
+	f, err := lockfile.OpenLocalDepFile("fixtures/npm/licenses/node_modules/.package-lock.json")
+	if err != nil {
+		t.Fatalf("could not open file %v", err)
+	}
+	defer f.Close()
+
+	packages, err := lockfile.NodeModulesExtractor{}.Extract(f)
+	if err != nil {
+		t.Errorf("Got unexpected error: %v", err)
+	}
+
+	expectPackagesWithoutLocations(t, packages, expectedNpmLicensesPackages("", ".."))
+}
@@ -0,0 +1,209 @@
+package lockfile
+
+import (
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/osv-scanner/internal/utility/filereader"
+)
+
+// mavenLicenseNames are the SPDX identifiers of the names and urls that are
+// commonly used for licenses in POMs, which are free text rather than SPDX
+var mavenLicenseNames = map[string]string{
+	"apache 2":                                           "Apache-2.0",
+	"apache 2.0":                                         "Apache-2.0",
+	"apache license 2.0":                                 "Apache-2.0",
+	"apache license, version 2.0":                        "Apache-2.0",
+	"the apache license, version 2.0":                    "Apache-2.0",
+	"the apache software license, version 2.0":           "Apache-2.0",
+	"bsd 3-clause license":                               "BSD-3-Clause",
+	"the bsd 3-clause license":                           "BSD-3-Clause",
+	"eclipse public license - v 1.0":                     "EPL-1.0",
+	"eclipse public license - v 2.0":                     "EPL-2.0",
+	"eclipse public license v2.0":                        "EPL-2.0",
+	"mit license":                                        "MIT",
+	"the mit license":                                    "MIT",
+	"http://www.apache.org/licenses/license-2.0":         "Apache-2.0",
+	"http://www.apache.org/licenses/license-2.0.txt":     "Apache-2.0",
+	"https://www.apache.org/licenses/license-2.0":        "Apache-2.0",
+	"https://www.apache.org/licenses/license-2.0.txt":    "Apache-2.0",
+	"http://www.eclipse.org/legal/epl-v10.html":          "EPL-1.0",
+	"https://www.eclipse.org/legal/epl-v20.html":         "EPL-2.0",
+	"http://opensource.org/licenses/mit":                 "MIT",
+	"https://opensource.org/licenses/mit":                "MIT",
+	"http://www.opensource.org/licenses/mit-license.php": "MIT",
+}
+
+// the furthest that licenses are looked for up the parents of a POM
+const maxMavenParentDepth = 10
+
+// PackageCaches are the directories that package managers keep the packages
+// they have downloaded in, which declare the licenses of the packages even
+// though they are outside of the directory being scanned
+type PackageCaches struct {
+	// the NuGet global packages folder, which has a .nuspec for each package
+	NuGet string
+	// the local Maven repository, which has a POM for each package
+	Maven string
+}
+
+// DefaultPackageCaches returns the locations that NuGet and Maven keep
+// packages in by default, taking the NUGET_PACKAGES environment variable
+// into account like NuGet does
+func DefaultPackageCaches() PackageCaches {
+	var caches PackageCaches
+
+	home, err := os.UserHomeDir()
+	if err == nil {
+		caches.NuGet = filepath.Join(home, ".nuget", "packages")
+		caches.Maven = filepath.Join(home, ".m2", "repository")
+	}
+
+	if p, envSet := os.LookupEnv("NUGET_PACKAGES"); envSet {
+		caches.NuGet = p
+	}
+
+	return caches
+}
+
+// Licenses returns the licenses that the given package declares in the
+// package cache of its ecosystem, if it has been downloaded
+func (c PackageCaches) Licenses(ecosystem Ecosystem, name string, version string) []string {
+	switch ecosystem {
+	case NuGetEcosystem:
+		return c.nugetLicenses(name, version)
+	case MavenEcosystem:
+		groupID, artifactID, found := strings.Cut(name, ":")
+		if !found {
+			return nil
+		}
+
+		return c.mavenLicenses(groupID, artifactID, version, 0)
+	default:
+		return nil
+	}
+}
+
+// decodeXMLFile decodes the XML file at the given path, which can use any of
+// the character sets that POMs and .nuspec files are written in
+func decodeXMLFile(path string, v any) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	decoder := xml.NewDecoder(f)
+	decoder.CharsetReader = filereader.CharsetDecoder
+
+	return decoder.Decode(v)
+}
+
+type nuspecFile struct {
+	Metadata struct {
+		License struct {
+			Type  string `xml:"type,attr"`
+			Value string `xml:",chardata"`
+		} `xml:"license"`
+	} `xml:"metadata"`
+}
+
+// nugetLicenses returns the license expression of the .nuspec of the package,
+// which NuGet stores using the lowercase id and version of the package
+func (c PackageCaches) nugetLicenses(name string, version string) []string {
+	if c.NuGet == "" {
+		return nil
+	}
+
+	id, version := strings.ToLower(name), strings.ToLower(version)
+
+	var nuspec nuspecFile
+	if err := decodeXMLFile(filepath.Join(c.NuGet, id, version, id+".nuspec"), &nuspec); err != nil {
+		return nil
+	}
+
+	// licenses can also be a file within the package, which is not an expression
+	license := nuspec.Metadata.License
+	if license.Type != "expression" || strings.TrimSpace(license.Value) == "" {
+		return nil
+	}
+
+	return []string{strings.TrimSpace(license.Value)}
+}
+
+type mavenPOMLicenses struct {
+	Parent struct {
+		GroupID    string `xml:"groupId"`
+		ArtifactID string `xml:"artifactId"`
+		Version    string `xml:"version"`
+	} `xml:"parent"`
+	Licenses []struct {
+		Name string `xml:"name"`
+		URL  string `xml:"url"`
+	} `xml:"licenses>license"`
+}
+
+// mavenLicenseID returns the SPDX identifier of a license from a POM, which
+// is either its name if that is already an SPDX expression, or the license
+// that its name or url is known to refer to
+func mavenLicenseID(name string, url string) (string, bool) {
+	name = strings.TrimSpace(name)
+
+	if isSPDXExpression(name) {
+		return name, true
+	}
+
+	for _, s := range []string{name, url} {
+		s = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(s)), "/")
+
+		if id, ok := mavenLicenseNames[s]; ok {
+			return id, true
+		}
+	}
+
+	return "", false
+}
+
+// mavenLicenses returns the licenses of the POM of the package in the local
+// repository, or otherwise those of its parents as licenses are inherited
+func (c PackageCaches) mavenLicenses(groupID string, artifactID string, version string, depth int) []string {
+	if c.Maven == "" || groupID == "" || artifactID == "" || version == "" || depth > maxMavenParentDepth {
+		return nil
+	}
+
+	path := filepath.Join(
+		c.Maven,
+		filepath.FromSlash(strings.ReplaceAll(groupID, ".", "/")),
+		artifactID,
+		version,
+		artifactID+"-"+version+".pom",
+	)
+
+	var pom mavenPOMLicenses
+	if err := decodeXMLFile(path, &pom); err != nil {
+		return nil
+	}
+
+	if len(pom.Licenses) == 0 {
+		return c.mavenLicenses(pom.Parent.GroupID, pom.Parent.ArtifactID, pom.Parent.Version, depth+1)
+	}
+
+	var licenses []string
+	for _, license := range pom.Licenses {
+		if id, ok := mavenLicenseID(license.Name, license.URL); ok {
+			licenses = append(licenses, id)
+		}
+	}
+
+	switch len(licenses) {
+	case 0:
+		return nil
+	case 1:
+		return licenses
+	default:
+		// packages that list several licenses can be used under any of them
+		return []string{"(" + strings.Join(licenses, " OR ") + ")"}
+	}
+}
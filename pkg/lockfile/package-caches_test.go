@@ -0,0 +1,90 @@
+package lockfile_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/osv-scanner/pkg/lockfile"
+)
+
+func TestPackageCaches_Licenses(t *testing.T) {
+	t.Parallel()
+
+	caches := lockfile.PackageCaches{
+		NuGet: "fixtures/package-caches/nuget",
+		Maven: "fixtures/package-caches/maven",
+	}
+
+	tests := []struct {
+		name      string
+		ecosystem lockfile.Ecosystem
+		pkg       string
+		version   string
+		want      []string
+	}{
+		{
+			name:      "nuspec with a license expression",
+			ecosystem: lockfile.NuGetEcosystem,
+			pkg:       "Newtonsoft.Json",
+			version:   "13.0.3",
+			want:      []string{"MIT"},
+		},
+		{
+			name:      "nuspec with a license file",
+			ecosystem: lockfile.NuGetEcosystem,
+			pkg:       "Legacy.Package",
+			version:   "1.0.0",
+			want:      nil,
+		},
+		{
+			name:      "nuget package that has not been downloaded",
+			ecosystem: lockfile.NuGetEcosystem,
+			pkg:       "Newtonsoft.Json",
+			version:   "12.0.0",
+			want:      nil,
+		},
+		{
+			name:      "pom with a well known license name",
+			ecosystem: lockfile.MavenEcosystem,
+			pkg:       "org.example:lib",
+			version:   "1.0.0",
+			want:      []string{"Apache-2.0"},
+		},
+		{
+			name:      "pom inheriting several licenses from its parent",
+			ecosystem: lockfile.MavenEcosystem,
+			pkg:       "org.example:child",
+			version:   "2.0.0",
+			want:      []string{"(EPL-2.0 OR MIT)"},
+		},
+		{
+			name:      "pom with an unknown license",
+			ecosystem: lockfile.MavenEcosystem,
+			pkg:       "org.example:unknown",
+			version:   "1.0.0",
+			want:      nil,
+		},
+		{
+			name:      "maven package that has not been downloaded",
+			ecosystem: lockfile.MavenEcosystem,
+			pkg:       "org.example:lib",
+			version:   "2.0.0",
+			want:      nil,
+		},
+		{
+			name:      "other ecosystems",
+			ecosystem: lockfile.NpmEcosystem,
+			pkg:       "lodash",
+			version:   "4.17.21",
+			want:      nil,
+		},
+	}
+
+	for _, tt := range tests {
+		got := caches.Licenses(tt.ecosystem, tt.pkg, tt.version)
+
+		if diff := cmp.Diff(tt.want, got); diff != "" {
+			t.Errorf("%s: Licenses() mismatch (-want +got):\n%s", tt.name, diff)
+		}
+	}
+}
@@ -2,7 +2,9 @@ package lockfile
 
 import (
 	"fmt"
+	"path"
 	"path/filepath"
+	"strings"
 
 	"github.com/google/osv-scanner/pkg/models"
 
@@ -12,6 +14,8 @@ import (
 type CargoLockPackage struct {
 	Name    string `toml:"name"`
 	Version string `toml:"version"`
+	// Source is empty for the crates of the workspace
+	Source string `toml:"source"`
 }
 
 type CargoLockFile struct {
@@ -37,20 +41,138 @@ func (e CargoLockExtractor) Extract(f DepFile) ([]PackageDetails, error) {
 	}
 
 	packages := make([]PackageDetails, 0, len(parsedLockfile.Packages))
+	crates := readCargoManifests(f)
 
 	for _, lockPackage := range parsedLockfile.Packages {
-		packages = append(packages, PackageDetails{
+		key := lockPackage.Name
+		if lockPackage.Source != "" {
+			key += "@" + lockPackage.Version
+		}
+
+		details := PackageDetails{
 			Name:           lockPackage.Name,
 			Version:        lockPackage.Version,
 			PackageManager: models.Crates,
 			Ecosystem:      CargoEcosystem,
 			CompareAs:      CargoEcosystem,
-		})
+		}
+
+		if crate, ok := crates[key]; ok {
+			if crate.license != "" {
+				details.Licenses = []string{crate.license}
+			}
+			details.Dir = crate.dir
+		}
+
+		packages = append(packages, details)
 	}
 
 	return packages, nil
 }
 
+type cargoManifest struct {
+	Package struct {
+		Name    string `toml:"name"`
+		Version string `toml:"version"`
+		// License is either an SPDX expression or a table with
+		// "workspace = true" to use the license of the workspace
+		License any `toml:"license"`
+	} `toml:"package"`
+	Workspace struct {
+		Members []string `toml:"members"`
+		Package struct {
+			License string `toml:"license"`
+		} `toml:"package"`
+	} `toml:"workspace"`
+}
+
+func (m cargoManifest) license(workspaceLicense string) string {
+	var license string
+
+	switch l := m.Package.License.(type) {
+	case string:
+		license = l
+	case map[string]any:
+		if inherit, _ := l["workspace"].(bool); inherit {
+			license = workspaceLicense
+		}
+	}
+
+	// "/" is the deprecated way of separating licenses that can be chosen from
+	return strings.ReplaceAll(license, "/", " OR ")
+}
+
+func openCargoManifest(f DepFile, manifestPath string) (cargoManifest, bool) {
+	var manifest cargoManifest
+
+	file, err := f.Open(manifestPath)
+	if err != nil {
+		return manifest, false
+	}
+	defer file.Close()
+
+	if _, err := toml.NewDecoder(file).Decode(&manifest); err != nil {
+		return manifest, false
+	}
+
+	return manifest, true
+}
+
+// cargoLocalCrate is a crate whose manifest is in the workspace
+type cargoLocalCrate struct {
+	license string
+	// dir is only set for crates that have been vendored
+	dir string
+}
+
+// readCargoManifests reads the crates of the workspace (by name) and those
+// that have been vendored (by name and version) from their manifests
+func readCargoManifests(f DepFile) map[string]cargoLocalCrate {
+	crates := make(map[string]cargoLocalCrate)
+
+	root, ok := openCargoManifest(f, "Cargo.toml")
+	if !ok {
+		return crates
+	}
+
+	workspaceLicense := root.Workspace.Package.License
+	add := func(key string, manifest cargoManifest, dir string) {
+		crates[key] = cargoLocalCrate{license: manifest.license(workspaceLicense), dir: dir}
+	}
+
+	if root.Package.Name != "" {
+		add(root.Package.Name, root, "")
+	}
+
+	for _, member := range root.Workspace.Members {
+		// globs of members are not expanded
+		if strings.ContainsAny(member, "*?[") {
+			continue
+		}
+
+		if manifest, ok := openCargoManifest(f, path.Join(member, "Cargo.toml")); ok {
+			add(manifest.Package.Name, manifest, "")
+		}
+	}
+
+	if dir, ok := f.(DirDepFile); ok {
+		entries, _ := dir.ReadDir("vendor")
+
+		for _, entry := range entries {
+			if !entry.IsDir() {
+				continue
+			}
+
+			dir := path.Join("vendor", entry.Name())
+			if manifest, ok := openCargoManifest(f, path.Join(dir, "Cargo.toml")); ok {
+				add(manifest.Package.Name+"@"+manifest.Package.Version, manifest, dir)
+			}
+		}
+	}
+
+	return crates
+}
+
 var _ Extractor = CargoLockExtractor{}
 
 //nolint:gochecknoinits
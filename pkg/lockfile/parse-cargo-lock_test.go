@@ -183,3 +183,48 @@ func TestParseCargoLock_PackageWithBuildString(t *testing.T) {
 		},
 	})
 }
+
+func TestParseCargoLock_Licenses(t *testing.T) {
+	t.Parallel()
+
+	packages, err := lockfile.ParseCargoLock("fixtures/cargo/licenses/licenses.lock")
+
+	if err != nil {
+		t.Errorf("Got unexpected error: %v", err)
+	}
+
+	expectPackages(t, packages, []lockfile.PackageDetails{
+		{
+			Name:           "addr2line",
+			Version:        "0.15.2",
+			PackageManager: models.Crates,
+			Ecosystem:      lockfile.CargoEcosystem,
+			CompareAs:      lockfile.CargoEcosystem,
+		},
+		{
+			Name:           "member",
+			Version:        "0.1.0",
+			PackageManager: models.Crates,
+			Ecosystem:      lockfile.CargoEcosystem,
+			CompareAs:      lockfile.CargoEcosystem,
+			Licenses:       []string{"Apache-2.0 WITH LLVM-exception"},
+		},
+		{
+			Name:           "root",
+			Version:        "0.1.0",
+			PackageManager: models.Crates,
+			Ecosystem:      lockfile.CargoEcosystem,
+			CompareAs:      lockfile.CargoEcosystem,
+			Licenses:       []string{"MIT OR Apache-2.0"},
+		},
+		{
+			Name:           "syn",
+			Version:        "1.0.73",
+			PackageManager: models.Crates,
+			Ecosystem:      lockfile.CargoEcosystem,
+			CompareAs:      lockfile.CargoEcosystem,
+			Licenses:       []string{"MIT OR Apache-2.0"},
+			Dir:            "vendor/syn",
+		},
+	})
+}
@@ -3,7 +3,6 @@ package lockfile
 import (
 	"fmt"
 	"io"
-	"os"
 	"path/filepath"
 	"strings"
 
@@ -34,24 +33,30 @@ func deduplicatePackages(packages map[string]PackageDetails) map[string]PackageD
 
 type GoLockExtractor struct{}
 
-func defaultNonCanonicalVersions(path, version string) (string, error) {
-	resolvedVersion := module.CanonicalVersion(version)
+// nonCanonicalVersionsFixer returns a modfile.VersionFixer which resolves
+// non-canonical versions, adding a warning to warnings for each one it
+// cannot resolve
+func nonCanonicalVersionsFixer(warnings *[]error) modfile.VersionFixer {
+	return func(path, version string) (string, error) {
+		resolvedVersion := module.CanonicalVersion(version)
+
+		// If the resolvedVersion is not canonical, we try to find the major resolvedVersion in the path and report that
+		if resolvedVersion == "" {
+			_, pathMajor, ok := module.SplitPathVersion(path)
+			if ok {
+				resolvedVersion = module.PathMajorPrefix(pathMajor)
+			}
+		}
 
-	// If the resolvedVersion is not canonical, we try to find the major resolvedVersion in the path and report that
-	if resolvedVersion == "" {
-		_, pathMajor, ok := module.SplitPathVersion(path)
-		if ok {
-			resolvedVersion = module.PathMajorPrefix(pathMajor)
+		if resolvedVersion == "" {
+			// If it is still not resolved, we default on 0.0.0 as we do with other package managers
+			*warnings = append(*warnings, fmt.Errorf("%s@%s is not a canonical version, defaulting to %s", path, version, unknownVersion))
+
+			return unknownVersion, nil
 		}
-	}
 
-	if resolvedVersion == "" {
-		// If it is still not resolved, we default on 0.0.0 as we do with other package managers
-		_, _ = fmt.Fprintf(os.Stderr, "%s@%s is not a canonical path, defaulting to %s\n", path, resolvedVersion, unknownVersion)
-		return unknownVersion, nil
+		return resolvedVersion, nil
 	}
-
-	return resolvedVersion, nil
 }
 
 func extractLocations(block []string, start modfile.Position, end modfile.Position, path string, name string, version string) (models.FilePosition, *models.FilePosition, *models.FilePosition) {
@@ -79,17 +84,24 @@ func (e GoLockExtractor) ShouldExtract(path string) bool {
 }
 
 func (e GoLockExtractor) Extract(f DepFile) ([]PackageDetails, error) {
+	packages, _, err := e.ExtractWithWarnings(f)
+
+	return packages, err
+}
+
+func (e GoLockExtractor) ExtractWithWarnings(f DepFile) ([]PackageDetails, []error, error) {
 	var parsedLockfile *modfile.File
+	var warnings []error
 
 	b, err := io.ReadAll(f)
 	lines := fileposition.BytesToLines(b)
 
 	if err == nil {
-		parsedLockfile, err = modfile.Parse(f.Path(), b, defaultNonCanonicalVersions)
+		parsedLockfile, err = modfile.Parse(f.Path(), b, nonCanonicalVersionsFixer(&warnings))
 	}
 
 	if err != nil {
-		return []PackageDetails{}, fmt.Errorf("could not extract from %s: %w", f.Path(), err)
+		return []PackageDetails{}, nil, fmt.Errorf("could not extract from %s: %w", f.Path(), err)
 	}
 
 	packages := map[string]PackageDetails{}
@@ -191,10 +203,10 @@ func (e GoLockExtractor) Extract(f DepFile) ([]PackageDetails, error) {
 		}
 	}
 
-	return maps.Values(deduplicatePackages(packages)), nil
+	return maps.Values(deduplicatePackages(packages)), warnings, nil
 }
 
-var _ Extractor = GoLockExtractor{}
+var _ ExtractorWithWarnings = GoLockExtractor{}
 
 //nolint:gochecknoinits
 func init() {
@@ -193,6 +193,30 @@ func TestParseGoLock_WithoutSupportedVersioning(t *testing.T) {
 	})
 }
 
+func TestGoLockExtractor_ExtractWithWarnings_WithoutSupportedVersioning(t *testing.T) {
+	t.Parallel()
+
+	f, err := lockfile.OpenLocalDepFile("fixtures/go/without-supported-versioning.mod")
+	if err != nil {
+		t.Fatalf("Got unexpected error: %v", err)
+	}
+	defer f.Close()
+
+	_, warnings, err := lockfile.GoLockExtractor{}.ExtractWithWarnings(f)
+	if err != nil {
+		t.Errorf("Got unexpected error: %v", err)
+	}
+
+	if len(warnings) != 1 {
+		t.Fatalf("Expected 1 warning, got %v", warnings)
+	}
+
+	want := "github.com/elastic/go-elasticsearch@master is not a canonical version, defaulting to v0.0.0-unresolved-version"
+	if warnings[0].Error() != want {
+		t.Errorf("Expected warning %q, got %q", want, warnings[0])
+	}
+}
+
 func TestParseGoLock_OnePackage(t *testing.T) {
 	t.Parallel()
 	dir, err := os.Getwd()
@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"net/http"
 	"net/url"
 	"os"
@@ -66,8 +67,9 @@ func NewMavenRegistryAPIClient(url string) (DepFile, error) {
 	}, nil
 }
 
-func (m *MavenRegistryProject) Open(_ string) (NestedDepFile, error) {
-	panic("Should not be called")
+// Open always fails, as there are no local files relative to a remote project
+func (m *MavenRegistryProject) Open(path string) (NestedDepFile, error) {
+	return nil, &fs.PathError{Op: "open", Path: path, Err: fs.ErrNotExist}
 }
 
 func (m *MavenRegistryProject) Path() string {
@@ -169,9 +171,8 @@ func (mld MavenLockDependency) resolvePropertiesValue(lockfile MavenLockFile, fi
 		}
 
 		if !ok {
-			fmt.Fprintf(
-				os.Stderr,
-				"Failed to resolve a property. fieldToResolve \"%s\" could not be found for \"%s\" (%s)\n",
+			lockfile.warnings.add(
+				"failed to resolve a property: \"%s\" could not be found for \"%s\" (%s)",
 				string(bytes),
 				lockfile.GroupID.Value+":"+lockfile.ArtifactID.Value,
 				mld.SourceFile,
@@ -222,6 +223,20 @@ type MavenLockFile struct {
 	ManagedDependencies      MavenLockDependencyHolder `xml:"dependencyManagement>dependencies"`
 	MainSourceFile           string
 	ProjectVersionSourceFile string
+
+	warnings *mavenWarnings
+}
+
+// mavenWarnings collects the problems found while decoding a pom.xml and its
+// parents which do not stop its packages from being extracted
+type mavenWarnings []error
+
+func (w *mavenWarnings) add(format string, a ...any) {
+	if w == nil {
+		return
+	}
+
+	*w = append(*w, fmt.Errorf(format, a...))
 }
 
 const MavenEcosystem Ecosystem = "Maven"
@@ -382,7 +397,7 @@ func (e MavenLockExtractor) enrichProperties(path string, properties map[string]
 	return MavenLockProperties{m: properties}
 }
 
-func (e MavenLockExtractor) resolveParentFilename(parent MavenLockParent, currentPath string) string {
+func (e MavenLockExtractor) resolveParentFilename(f DepFile, parent MavenLockParent, currentPath string, warnings *mavenWarnings) string {
 	// If a parent is defined, use its relative path to find the File, then recurse to decode it properly and enrich its dependencies
 	// If the relativePath is not defined, default to ../pom.xml
 	parentRelativePath := parent.RelativePath
@@ -393,8 +408,10 @@ func (e MavenLockExtractor) resolveParentFilename(parent MavenLockParent, curren
 
 		shouldComputeURL := strings.HasPrefix(currentPath, "https")
 		if !shouldComputeURL {
-			localPath := filepath.Join(filepath.Dir(currentPath), parentRelativePath)
-			_, err := os.Stat(localPath)
+			localFile, err := f.Open(filepath.Join(filepath.Dir(currentPath), parentRelativePath))
+			if err == nil {
+				localFile.Close()
+			}
 			shouldComputeURL = errors.Is(err, os.ErrNotExist)
 		}
 
@@ -407,7 +424,8 @@ func (e MavenLockExtractor) resolveParentFilename(parent MavenLockParent, curren
 				fmt.Sprintf("%s-%s.pom", parent.ArtifactID, parent.Version),
 			)
 			if err != nil {
-				_, _ = fmt.Fprintf(os.Stderr, "Failed to construct remote path: %s\n", err)
+				warnings.add("failed to construct remote path: %w", err)
+
 				return ""
 			}
 
@@ -421,7 +439,7 @@ func (e MavenLockExtractor) resolveParentFilename(parent MavenLockParent, curren
 	return filepath.FromSlash(filepath.Join(filepath.Dir(currentPath), parentRelativePath))
 }
 
-func (e MavenLockExtractor) decodeMavenFile(f DepFile, depth int, visitedPath map[string]bool) (*MavenLockFile, error) {
+func (e MavenLockExtractor) decodeMavenFile(f DepFile, depth int, visitedPath map[string]bool, warnings *mavenWarnings) (*MavenLockFile, error) {
 	var parsedLockfile *MavenLockFile
 
 	// Decoding the original lockfile and enrich its dependencies
@@ -450,11 +468,16 @@ func (e MavenLockExtractor) decodeMavenFile(f DepFile, depth int, visitedPath ma
 		return parsedLockfile, nil
 	}
 
-	parentPath := e.resolveParentFilename(parsedLockfile.Parent, f.Path())
+	parentPath := e.resolveParentFilename(f, parsedLockfile.Parent, f.Path(), warnings)
+
+	if parentPath == "" {
+		return parsedLockfile, nil
+	}
+
+	if ok := visitedPath[parentPath]; ok {
+		// Parent has already been visited, lets stop there
+		warnings.add("already visited parent path, stopping there to avoid a circular dependency: %s", parentPath)
 
-	if ok := visitedPath[parentPath]; ok || parentPath == "" {
-		// Parent has already been visited or is empty, lets stop there
-		fmt.Fprintf(os.Stdout, "Already visited parent path, stopping there to avoid a circular dependency %s\n", parentPath)
 		return parsedLockfile, nil
 	}
 
@@ -469,28 +492,29 @@ func (e MavenLockExtractor) decodeMavenFile(f DepFile, depth int, visitedPath ma
 		mavenRegistryClient, clientErr := NewMavenRegistryAPIClient(parentPath)
 		// If the remote pom does not exist, we can't do anything.
 		if clientErr != nil {
-			_, _ = fmt.Fprintf(os.Stderr, "Failed to fetch parent pom from remote repository: %s\n", parentPath)
+			warnings.add("failed to fetch parent pom from remote repository: %s", parentPath)
 			//nolint:nilerr // we don't want to consider a network request failing for the parent as being unable to handle the lockfile
 			return parsedLockfile, nil
 		}
 
-		parentLockfile, err = e.decodeMavenFile(mavenRegistryClient, depth+1, visitedPath)
+		parentLockfile, err = e.decodeMavenFile(mavenRegistryClient, depth+1, visitedPath, warnings)
 		if err != nil {
 			return nil, err
 		}
 
 		parentFilePath = parentPath
-	} else if _, err = os.Stat(parentPath); errors.Is(err, os.ErrNotExist) {
-		// If the parent pom does not exist and is not a remote file, we can't do anything.
-		_, _ = fmt.Fprintf(os.Stderr, "Maven lockfile parser couldn't reach the parent because it is not locally defined: %s\n", parentPath)
-
-		return parsedLockfile, nil
 	} else {
-		parentFile, err := OpenLocalDepFile(parentPath)
+		parentFile, err := f.Open(parentPath)
+		if errors.Is(err, os.ErrNotExist) {
+			// If the parent pom does not exist and is not a remote file, we can't do anything.
+			warnings.add("couldn't reach the parent pom because it is not locally defined: %s", parentPath)
+
+			return parsedLockfile, nil
+		}
 		if err != nil {
 			return nil, err
 		}
-		parentLockfile, parentErr = e.decodeMavenFile(parentFile, depth+1, visitedPath)
+		parentLockfile, parentErr = e.decodeMavenFile(parentFile, depth+1, visitedPath, warnings)
 		if parentErr != nil {
 			return nil, parentErr
 		}
@@ -507,13 +531,23 @@ func (e MavenLockExtractor) decodeMavenFile(f DepFile, depth int, visitedPath ma
 }
 
 func (e MavenLockExtractor) Extract(f DepFile) ([]PackageDetails, error) {
+	packages, _, err := e.ExtractWithWarnings(f)
+
+	return packages, err
+}
+
+func (e MavenLockExtractor) ExtractWithWarnings(f DepFile) ([]PackageDetails, []error, error) {
+	var warnings mavenWarnings
+
 	visitedPath := make(map[string]bool)
 	visitedPath[f.Path()] = true
-	parsedLockfile, err := e.decodeMavenFile(f, 0, visitedPath)
+	parsedLockfile, err := e.decodeMavenFile(f, 0, visitedPath, &warnings)
 	if err != nil {
-		return []PackageDetails{}, fmt.Errorf("could not extract from %s: %w", f.Path(), err)
+		return []PackageDetails{}, nil, fmt.Errorf("could not extract from %s: %w", f.Path(), err)
 	}
 
+	parsedLockfile.warnings = &warnings
+
 	details := map[string]PackageDetails{}
 
 	for _, lockPackage := range parsedLockfile.Dependencies.Dependencies {
@@ -585,13 +619,13 @@ func (e MavenLockExtractor) Extract(f DepFile) ([]PackageDetails, error) {
 		details[finalName] = pkgDetails
 	}
 
-	return maps.Values(details), nil
+	return maps.Values(details), warnings, nil
 }
 
 func (e MavenLockExtractor) GetArtifact(f DepFile) (*models.ScannedArtifact, error) {
 	visitedPath := make(map[string]bool)
 	visitedPath[f.Path()] = true
-	parsedLockfile, err := e.decodeMavenFile(f, 0, visitedPath)
+	parsedLockfile, err := e.decodeMavenFile(f, 0, visitedPath, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -612,7 +646,7 @@ func (e MavenLockExtractor) GetArtifact(f DepFile) (*models.ScannedArtifact, err
 		artifact.DependsOn = &models.ArtifactDetail{
 			Name:      parentArtifact,
 			Version:   parsedLockfile.Parent.Version,
-			Filename:  e.resolveParentFilename(parsedLockfile.Parent, f.Path()),
+			Filename:  e.resolveParentFilename(f, parsedLockfile.Parent, f.Path(), nil),
 			Ecosystem: models.EcosystemMaven,
 		}
 	}
@@ -620,7 +654,7 @@ func (e MavenLockExtractor) GetArtifact(f DepFile) (*models.ScannedArtifact, err
 	return &artifact, nil
 }
 
-var _ Extractor = MavenLockExtractor{}
+var _ ExtractorWithWarnings = MavenLockExtractor{}
 
 //nolint:gochecknoinits
 func init() {
@@ -3,7 +3,6 @@ package lockfile
 import (
 	"bufio"
 	"fmt"
-	"os"
 	"path/filepath"
 	"strings"
 
@@ -21,11 +20,18 @@ func (e MixLockExtractor) ShouldExtract(path string) bool {
 }
 
 func (e MixLockExtractor) Extract(f DepFile) ([]PackageDetails, error) {
+	packages, _, err := e.ExtractWithWarnings(f)
+
+	return packages, err
+}
+
+func (e MixLockExtractor) ExtractWithWarnings(f DepFile) ([]PackageDetails, []error, error) {
 	re := cachedregexp.MustCompile(`^ +"(\w+)": \{.+,$`)
 
 	scanner := bufio.NewScanner(f)
 
 	var packages []PackageDetails
+	var warnings []error
 
 	for scanner.Scan() {
 		line := scanner.Text()
@@ -44,10 +50,7 @@ func (e MixLockExtractor) Extract(f DepFile) ([]PackageDetails, error) {
 		})
 
 		if len(fields) < 4 {
-			_, _ = fmt.Fprintf(
-				os.Stderr,
-				"Found less than four fields when parsing a line that looks like a dependency in a mix.lock - please report this!\n",
-			)
+			warnings = append(warnings, fmt.Errorf("found less than four fields when parsing %q, which looks like a dependency", match[1]))
 
 			continue
 		}
@@ -75,13 +78,13 @@ func (e MixLockExtractor) Extract(f DepFile) ([]PackageDetails, error) {
 	}
 
 	if err := scanner.Err(); err != nil {
-		return []PackageDetails{}, fmt.Errorf("error while scanning %s: %w", f.Path(), err)
+		return []PackageDetails{}, nil, fmt.Errorf("error while scanning %s: %w", f.Path(), err)
 	}
 
-	return packages, nil
+	return packages, warnings, nil
 }
 
-var _ Extractor = MixLockExtractor{}
+var _ ExtractorWithWarnings = MixLockExtractor{}
 
 //nolint:gochecknoinits
 func init() {
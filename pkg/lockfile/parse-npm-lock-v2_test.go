@@ -1,18 +1,15 @@
 package lockfile_test
 
 import (
-	"bytes"
 	"errors"
-	"io"
 	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
 	"testing"
 
 	"github.com/google/osv-scanner/pkg/models"
 
-	"github.com/stretchr/testify/assert"
-
 	"github.com/google/osv-scanner/pkg/lockfile"
 )
 
@@ -93,34 +90,19 @@ func TestParseNpmLock_v2_OnePackage_MatcherFailed(t *testing.T) {
 		t.Errorf("Got unexpected error: %v", err)
 	}
 
-	stderr := os.Stderr
-	r, w, err := os.Pipe()
-	if err != nil {
-		t.Errorf("Got unexpected error: %v", err)
-	}
-	os.Stderr = w
-
 	// Mock packageJSONMatcher to fail
 	matcherError := errors.New("packageJSONMatcher failed")
 	lockfile.NpmExtractor.Matchers = []lockfile.Matcher{FailingMatcher{Error: matcherError}}
 
 	path := filepath.FromSlash(filepath.Join(dir, "fixtures/npm/one-package.v2.json"))
-	packages, err := lockfile.ParseNpmLock(path)
+	packages, matchErrors, err := lockfile.ExtractFromFileWithMatchErrors(path, lockfile.NpmExtractor)
 	if err != nil {
 		t.Errorf("Got unexpected error: %v", err)
 	}
 
-	// Capture stderr
-	_ = w.Close()
-	os.Stderr = stderr
-	var buffer bytes.Buffer
-	_, err = io.Copy(&buffer, r)
-	if err != nil {
-		t.Errorf("failed to copy stderr output: %v", err)
+	if len(matchErrors) != 1 || !errors.Is(matchErrors[0], matcherError) {
+		t.Errorf("Expected the matcher error, got %v", matchErrors)
 	}
-	_ = r.Close()
-
-	assert.Contains(t, buffer.String(), matcherError.Error())
 	expectPackagesWithoutLocations(t, packages, []lockfile.PackageDetails{
 		{
 			Name:           "wrappy",
@@ -370,6 +352,7 @@ func TestParseNpmLock_v2_Commits(t *testing.T) {
 			Ecosystem:      lockfile.NpmEcosystem,
 			CompareAs:      lockfile.NpmEcosystem,
 			Commit:         "3b1bb80b302c2e552685dc8a029797ec832ea7c9",
+			Licenses:       []string{"SEE LICENSE IN LICENSE"},
 		},
 		{
 			Name:           "ansi-styles",
@@ -388,6 +371,7 @@ func TestParseNpmLock_v2_Commits(t *testing.T) {
 			Ecosystem:      lockfile.NpmEcosystem,
 			CompareAs:      lockfile.NpmEcosystem,
 			Commit:         "c5a7ba5e0ad98b8db1cb8ce105403dd4b768cced",
+			Licenses:       []string{"MIT"},
 			DepGroups:      []string{"dev"},
 		},
 		{
@@ -398,6 +382,7 @@ func TestParseNpmLock_v2_Commits(t *testing.T) {
 			Ecosystem:      lockfile.NpmEcosystem,
 			CompareAs:      lockfile.NpmEcosystem,
 			Commit:         "af885e2e890b9ef0875edd2b117305119ee5bdc5",
+			Licenses:       []string{"MIT"},
 			DepGroups:      []string{"dev"},
 		},
 		{
@@ -407,6 +392,7 @@ func TestParseNpmLock_v2_Commits(t *testing.T) {
 			Ecosystem:      lockfile.NpmEcosystem,
 			CompareAs:      lockfile.NpmEcosystem,
 			Commit:         "be5935f8d2595bcd97b05718ef1eeae08d812e10",
+			Licenses:       []string{"MIT"},
 			DepGroups:      []string{"dev"},
 			IsDirect:       false,
 		},
@@ -418,6 +404,7 @@ func TestParseNpmLock_v2_Commits(t *testing.T) {
 			Ecosystem:      lockfile.NpmEcosystem,
 			CompareAs:      lockfile.NpmEcosystem,
 			Commit:         "d5ac0584ee9ae7bd9288220a39780f155b9ad4c8",
+			Licenses:       []string{"MIT"},
 			DepGroups:      []string{"dev"},
 		},
 		{
@@ -427,6 +414,7 @@ func TestParseNpmLock_v2_Commits(t *testing.T) {
 			Ecosystem:      lockfile.NpmEcosystem,
 			CompareAs:      lockfile.NpmEcosystem,
 			Commit:         "82dcc8e914dabd9305ab9ae580709a7825e824f5",
+			Licenses:       []string{"MIT"},
 			DepGroups:      []string{"dev"},
 			IsDirect:       false,
 		},
@@ -438,6 +426,7 @@ func TestParseNpmLock_v2_Commits(t *testing.T) {
 			Ecosystem:      lockfile.NpmEcosystem,
 			CompareAs:      lockfile.NpmEcosystem,
 			Commit:         "d5ac0584ee9ae7bd9288220a39780f155b9ad4c8",
+			Licenses:       []string{"MIT"},
 			DepGroups:      []string{"dev"},
 		},
 		{
@@ -447,6 +436,7 @@ func TestParseNpmLock_v2_Commits(t *testing.T) {
 			Ecosystem:      lockfile.NpmEcosystem,
 			CompareAs:      lockfile.NpmEcosystem,
 			Commit:         "82ae8802978da40d7f1be5ad5943c9e550ab2c89",
+			Licenses:       []string{"MIT"},
 			DepGroups:      []string{"dev"},
 			IsDirect:       false,
 		},
@@ -458,6 +448,7 @@ func TestParseNpmLock_v2_Commits(t *testing.T) {
 			Ecosystem:      lockfile.NpmEcosystem,
 			CompareAs:      lockfile.NpmEcosystem,
 			Commit:         "af885e2e890b9ef0875edd2b117305119ee5bdc5",
+			Licenses:       []string{"MIT"},
 			DepGroups:      []string{"dev"},
 		},
 		{
@@ -468,6 +459,7 @@ func TestParseNpmLock_v2_Commits(t *testing.T) {
 			Ecosystem:      lockfile.NpmEcosystem,
 			CompareAs:      lockfile.NpmEcosystem,
 			Commit:         "af885e2e890b9ef0875edd2b117305119ee5bdc5",
+			Licenses:       []string{"MIT"},
 			DepGroups:      []string{"dev"},
 		},
 		{
@@ -498,6 +490,7 @@ func TestParseNpmLock_v2_Commits(t *testing.T) {
 			Ecosystem:      lockfile.NpmEcosystem,
 			CompareAs:      lockfile.NpmEcosystem,
 			Commit:         "280b560161b751ba226d50c7db1e0a14a78c2de0",
+			Licenses:       []string{"MIT"},
 			DepGroups:      []string{"dev"},
 		},
 	})
@@ -661,3 +654,48 @@ func TestParseNpmLock_v2_SamePackageDifferentGroups(t *testing.T) {
 		},
 	})
 }
+
+func TestParseNpmLock_v2_Licenses(t *testing.T) {
+	t.Parallel()
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Errorf("got unexpected error: %v", err)
+	}
+
+	path := filepath.FromSlash(filepath.Join(dir, "fixtures/npm/licenses/package-lock.json"))
+	packages, err := lockfile.ParseNpmLock(path)
+	if err != nil {
+		t.Errorf("Got unexpected error: %v", err)
+	}
+
+	expectPackagesWithoutLocations(t, packages, expectedNpmLicensesPackages("^1.0.0", "."))
+}
+
+// expectedNpmLicensesPackages are the packages of the fixtures/npm/licenses
+// lockfiles, with licenses from both the lockfile and the installed manifests,
+// whose directories are relative to root
+func expectedNpmLicensesPackages(targetVersion string, root string) []lockfile.PackageDetails {
+	var targetVersions []string
+	if targetVersion != "" {
+		targetVersions = []string{targetVersion}
+	}
+
+	packages := []lockfile.PackageDetails{
+		{Name: "@scope/manifest-license", Licenses: []string{"Apache-2.0 WITH LLVM-exception"}, Dir: path.Join(root, "node_modules/@scope/manifest-license")},
+		{Name: "expression", Licenses: []string{"(MIT OR Apache-2.0)"}},
+		{Name: "legacy-object", Licenses: []string{"BSD-3-Clause"}},
+		{Name: "manifest-licenses", Licenses: []string{"(MIT OR Apache-2.0)"}, Dir: path.Join(root, "node_modules/manifest-licenses")},
+		{Name: "no-license"},
+	}
+
+	for i := range packages {
+		packages[i].Version = "1.0.0"
+		packages[i].TargetVersions = targetVersions
+		packages[i].PackageManager = models.NPM
+		packages[i].Ecosystem = lockfile.NpmEcosystem
+		packages[i].CompareAs = lockfile.NpmEcosystem
+		packages[i].DepGroups = []string{"prod"}
+	}
+
+	return packages
+}
@@ -57,9 +57,66 @@ type NpmLockPackage struct {
 
 	Link bool `json:"link,omitempty"`
 
+	License NpmLicense `json:"license,omitempty"`
+
+	// dir is the directory that the package is installed in, if it is
+	dir string
+
 	models.FilePosition
 }
 
+// NpmLicense is the license declared by an npm package, which is usually an
+// SPDX expression but is an object (or an array of them) in older packages
+type NpmLicense string
+
+func (l *NpmLicense) UnmarshalJSON(data []byte) error {
+	type licenseObject struct {
+		Type string `json:"type"`
+	}
+
+	var expression string
+	if err := json.Unmarshal(data, &expression); err == nil {
+		*l = NpmLicense(expression)
+
+		return nil
+	}
+
+	var object licenseObject
+	if err := json.Unmarshal(data, &object); err == nil {
+		*l = NpmLicense(object.Type)
+
+		return nil
+	}
+
+	// an array of licenses means the package can be used under any of them
+	var objects []licenseObject
+	if err := json.Unmarshal(data, &objects); err != nil {
+		return fmt.Errorf("invalid license: %w", err)
+	}
+
+	types := make([]string, 0, len(objects))
+	for _, object := range objects {
+		if object.Type != "" {
+			types = append(types, object.Type)
+		}
+	}
+
+	*l = NpmLicense(strings.Join(types, " OR "))
+	if len(types) > 1 {
+		*l = "(" + *l + ")"
+	}
+
+	return nil
+}
+
+func (l NpmLicense) licenses() []string {
+	if l == "" {
+		return nil
+	}
+
+	return []string{string(l)}
+}
+
 type NpmLockfile struct {
 	Version    int `json:"lockfileVersion"`
 	SourceFile string
@@ -287,6 +344,8 @@ func parseNpmLockPackages(packages map[string]*NpmLockPackage) map[string]Packag
 				CompareAs:      NpmEcosystem,
 				Commit:         commit,
 				DepGroups:      detail.depGroups(),
+				Licenses:       detail.License.licenses(),
+				Dir:            detail.dir,
 			})
 		}
 	}
@@ -330,9 +389,56 @@ func (e NpmLockExtractor) Extract(f DepFile) ([]PackageDetails, error) {
 	}
 	parsedLockfile.SourceFile = f.Path()
 
+	readNpmManifests(f, parsedLockfile.Packages)
+
 	return maps.Values(parseNpmLock(*parsedLockfile, lines)), nil
 }
 
+// readNpmManifests finds the packages that are installed in node_modules from
+// their manifests, reading the licenses of those that the lockfile does not
+// declare one for
+func readNpmManifests(f DepFile, packages map[string]*NpmLockPackage) {
+	// the packages of hidden lockfiles are relative to the directory that
+	// node_modules is in, rather than to the lockfile itself
+	root := "."
+	if (NodeModulesExtractor{}).ShouldExtract(f.Path()) {
+		root = ".."
+	}
+
+	for namePath, pkg := range packages {
+		if namePath == "" || pkg.Link || !strings.Contains(namePath, "node_modules/") {
+			continue
+		}
+
+		dir := path.Join(root, namePath)
+		manifest, err := f.Open(path.Join(dir, "package.json"))
+		if err != nil {
+			continue
+		}
+
+		pkg.dir = dir
+		if pkg.License != "" {
+			manifest.Close()
+
+			continue
+		}
+
+		var packageJSON struct {
+			License NpmLicense `json:"license"`
+			// older packages use an array of licenses instead
+			Licenses NpmLicense `json:"licenses"`
+		}
+		if err := json.NewDecoder(manifest).Decode(&packageJSON); err == nil {
+			pkg.License = packageJSON.License
+			if pkg.License == "" {
+				pkg.License = packageJSON.Licenses
+			}
+		}
+
+		manifest.Close()
+	}
+}
+
 var NpmExtractor = NpmLockExtractor{
 	WithMatcher{Matchers: []Matcher{&PackageJSONMatcher{}}},
 }
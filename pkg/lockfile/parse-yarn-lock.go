@@ -4,7 +4,6 @@ import (
 	"bufio"
 	"fmt"
 	"net/url"
-	"os"
 	"path/filepath"
 	"strings"
 
@@ -35,14 +34,6 @@ func shouldSkipYarnLine(line string) bool {
 
 func parseYarnPackageGroup(group []string) YarnPackage {
 	name, targetVersions := extractYarnPackageNameAndTargetVersions(group[0])
-	if strings.Contains(name, "?") {
-		_, _ = fmt.Fprintf(
-			os.Stderr,
-			"Received package name of %s including question mark for group %v \n",
-			name,
-			group,
-		)
-	}
 
 	return YarnPackage{
 		Name:           name,
@@ -305,14 +296,6 @@ func buildDependencyTree(rootPkgName, rootPkgTargetVersion, rootPkgRegistry stri
 }
 
 func parseYarnPackage(dependency YarnPackage) PackageDetails {
-	if dependency.Version == "" {
-		_, _ = fmt.Fprintf(
-			os.Stderr,
-			"Failed to determine version of %s while parsing a yarn.lock - please report this!\n",
-			dependency.Name,
-		)
-	}
-
 	return PackageDetails{
 		Name:           dependency.Name,
 		Version:        dependency.Version,
@@ -354,6 +337,12 @@ func (e YarnLockExtractor) ShouldExtract(path string) bool {
 }
 
 func (e YarnLockExtractor) Extract(f DepFile) ([]PackageDetails, error) {
+	packages, _, err := e.ExtractWithWarnings(f)
+
+	return packages, err
+}
+
+func (e YarnLockExtractor) ExtractWithWarnings(f DepFile) ([]PackageDetails, []error, error) {
 	scanner := bufio.NewScanner(f)
 
 	yarnPackages := groupYarnPackageLines(scanner)
@@ -363,16 +352,25 @@ func (e YarnLockExtractor) Extract(f DepFile) ([]PackageDetails, error) {
 	// Then use all this in the matcher to know is-dev / is-direct and propagate it everywhere
 
 	if err := scanner.Err(); err != nil {
-		return []PackageDetails{}, fmt.Errorf("error while scanning %s: %w", f.Path(), err)
+		return []PackageDetails{}, nil, fmt.Errorf("error while scanning %s: %w", f.Path(), err)
 	}
 
 	packages := make([]PackageDetails, 0, len(yarnPackages))
+	var warnings []error
 
 	for _, yarnPackage := range yarnPackages {
 		if yarnPackage.Name == "__metadata" {
 			continue
 		}
 
+		if strings.Contains(yarnPackage.Name, "?") {
+			warnings = append(warnings, fmt.Errorf("received package name of %s including question mark", yarnPackage.Name))
+		}
+
+		if yarnPackage.Version == "" {
+			warnings = append(warnings, fmt.Errorf("failed to determine version of %s", yarnPackage.Name))
+		}
+
 		packages = append(packages, parseYarnPackage(yarnPackage))
 	}
 	pkgIndex := indexByNameAndVersions(packages)
@@ -380,13 +378,15 @@ func (e YarnLockExtractor) Extract(f DepFile) ([]PackageDetails, error) {
 		packages[index].Dependencies = buildDependencyTree(pkg.Name, pkg.TargetVersions[0], "npm", yarnPackageIndex, pkgIndex)
 	}
 
-	return packages, nil
+	return packages, warnings, nil
 }
 
 var YarnExtractor = YarnLockExtractor{
 	WithMatcher{Matchers: []Matcher{&PackageJSONMatcher{}}},
 }
 
+var _ ExtractorWithWarnings = YarnExtractor
+
 //nolint:gochecknoinits
 func init() {
 	registerExtractor("yarn.lock", YarnExtractor)
@@ -83,6 +83,13 @@ type Lockfile struct {
 	ParsedAs string                  `json:"parsedAs"`
 	Packages Packages                `json:"packages"`
 	Artifact *models.ScannedArtifact `json:"artifact,omitempty"`
+	// MatchErrors are the errors from matching the packages against their
+	// source files, which only means some packages are missing locations
+	MatchErrors []error `json:"-"`
+	// Warnings are the problems found while extracting the packages that did
+	// not stop them from being extracted, but might mean some are missing
+	// or incomplete
+	Warnings []error `json:"-"`
 }
 
 func (l Lockfile) String() string {
@@ -0,0 +1,121 @@
+package lockfile
+
+import (
+	"fmt"
+	"net/mail"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/osv-scanner/pkg/models"
+	"github.com/google/osv-scanner/pkg/spdx"
+)
+
+// pythonLicenseClassifiers are the SPDX identifiers of the trove classifiers
+// that unambiguously identify a license
+var pythonLicenseClassifiers = map[string]string{
+	"License :: OSI Approved :: Apache Software License":                            "Apache-2.0",
+	"License :: OSI Approved :: Boost Software License 1.0 (BSL-1.0)":               "BSL-1.0",
+	"License :: OSI Approved :: Eclipse Public License 2.0 (EPL-2.0)":               "EPL-2.0",
+	"License :: OSI Approved :: GNU Affero General Public License v3":               "AGPL-3.0-only",
+	"License :: OSI Approved :: GNU General Public License v2 (GPLv2)":              "GPL-2.0-only",
+	"License :: OSI Approved :: GNU General Public License v3 (GPLv3)":              "GPL-3.0-only",
+	"License :: OSI Approved :: GNU Lesser General Public License v2 (LGPLv2)":      "LGPL-2.0-only",
+	"License :: OSI Approved :: GNU Lesser General Public License v3 (LGPLv3)":      "LGPL-3.0-only",
+	"License :: OSI Approved :: ISC License (ISCL)":                                 "ISC",
+	"License :: OSI Approved :: MIT License":                                        "MIT",
+	"License :: OSI Approved :: MIT No Attribution License (MIT-0)":                 "MIT-0",
+	"License :: OSI Approved :: Mozilla Public License 2.0 (MPL 2.0)":               "MPL-2.0",
+	"License :: OSI Approved :: Python Software Foundation License":                 "PSF-2.0",
+	"License :: OSI Approved :: The Unlicense (Unlicense)":                          "Unlicense",
+	"License :: OSI Approved :: zlib/libpng License":                                "Zlib",
+	"License :: OSI Approved :: Universal Permissive License (UPL)":                 "UPL-1.0",
+	"License :: OSI Approved :: Historical Permission Notice and Disclaimer (HPND)": "HPND",
+}
+
+// PythonMetadataExtractor extracts the Python package that is installed (or
+// packaged in a wheel) from the METADATA file of its .dist-info directory
+type PythonMetadataExtractor struct{}
+
+func (e PythonMetadataExtractor) ShouldExtract(path string) bool {
+	return filepath.Base(path) == "METADATA" && strings.HasSuffix(filepath.Dir(path), ".dist-info")
+}
+
+func (e PythonMetadataExtractor) Extract(f DepFile) ([]PackageDetails, error) {
+	message, err := mail.ReadMessage(f)
+	if err != nil {
+		return []PackageDetails{}, fmt.Errorf("could not extract from %s: %w", f.Path(), err)
+	}
+
+	name := message.Header.Get("Name")
+	if name == "" {
+		return []PackageDetails{}, fmt.Errorf("could not extract from %s: missing package name", f.Path())
+	}
+
+	return []PackageDetails{{
+		Name:           normalizedRequirementName(name),
+		Version:        message.Header.Get("Version"),
+		PackageManager: models.Unknown,
+		Ecosystem:      PipEcosystem,
+		CompareAs:      PipEcosystem,
+		Licenses:       pythonLicenses(message.Header),
+		// license files are in the .dist-info directory of the package
+		Dir: ".",
+	}}, nil
+}
+
+// pythonLicenses returns the license of the package, preferring the SPDX
+// expression of newer metadata over the free-form license field, which is
+// only used if it is an SPDX expression, and then the license classifiers
+func pythonLicenses(header mail.Header) []string {
+	if expression := header.Get("License-Expression"); expression != "" {
+		return []string{expression}
+	}
+
+	if license := header.Get("License"); isSPDXExpression(license) {
+		return []string{license}
+	}
+
+	var classified []string
+	for _, classifier := range header["Classifier"] {
+		if id, ok := pythonLicenseClassifiers[strings.TrimSpace(classifier)]; ok {
+			classified = append(classified, id)
+		}
+	}
+
+	switch len(classified) {
+	case 0:
+		return nil
+	case 1:
+		return classified
+	default:
+		// packages with several license classifiers can be used under any of them
+		return []string{"(" + strings.Join(classified, " OR ") + ")"}
+	}
+}
+
+// isSPDXExpression reports if the license is an expression of known SPDX licenses
+func isSPDXExpression(license string) bool {
+	expr, err := spdx.Parse(license)
+	if err != nil {
+		return false
+	}
+
+	for _, l := range expr.Licenses() {
+		if len(spdx.Unrecognized([]string{l.License})) > 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+var _ Extractor = PythonMetadataExtractor{}
+
+//nolint:gochecknoinits
+func init() {
+	registerExtractor("dist-info/METADATA", PythonMetadataExtractor{})
+}
+
+func ParsePythonMetadata(pathToMetadata string) ([]PackageDetails, error) {
+	return ExtractFromFile(pathToMetadata, PythonMetadataExtractor{})
+}
@@ -0,0 +1,128 @@
+package lockfile_test
+
+import (
+	"io/fs"
+	"testing"
+
+	"github.com/google/osv-scanner/pkg/lockfile"
+	"github.com/google/osv-scanner/pkg/models"
+)
+
+func TestPythonMetadataExtractor_ShouldExtract(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		path string
+		want bool
+	}{
+		{
+			name: "empty",
+			path: "",
+			want: false,
+		},
+		{
+			name: "plain",
+			path: "METADATA",
+			want: false,
+		},
+		{
+			name: "installed",
+			path: "/usr/lib/python3/site-packages/requests-2.31.0.dist-info/METADATA",
+			want: true,
+		},
+		{
+			name: "in wheel",
+			path: "dist/requests-2.31.0-py3-none-any.whl!/requests-2.31.0.dist-info/METADATA",
+			want: true,
+		},
+		{
+			name: "egg-info",
+			path: "requests.egg-info/PKG-INFO",
+			want: false,
+		},
+		{
+			name: "other file in dist-info",
+			path: "requests-2.31.0.dist-info/RECORD",
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			e := lockfile.PythonMetadataExtractor{}
+			should := e.ShouldExtract(tt.path)
+			if should != tt.want {
+				t.Errorf("ShouldExtract() - got %v, expected %v", should, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePythonMetadata_FileDoesNotExist(t *testing.T) {
+	t.Parallel()
+
+	packages, err := lockfile.ParsePythonMetadata("fixtures/python-metadata/does-not-exist.dist-info/METADATA")
+
+	expectErrIs(t, err, fs.ErrNotExist)
+	expectPackages(t, packages, []lockfile.PackageDetails{})
+}
+
+func TestParsePythonMetadata_NotMetadata(t *testing.T) {
+	t.Parallel()
+
+	packages, err := lockfile.ParsePythonMetadata("fixtures/python-metadata/not-metadata.dist-info/METADATA")
+
+	expectErrContaining(t, err, "could not extract from")
+	expectPackages(t, packages, []lockfile.PackageDetails{})
+}
+
+func TestParsePythonMetadata_Licenses(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		fixture string
+		want    lockfile.PackageDetails
+	}{
+		{
+			// the license field is not an SPDX expression, so the classifier is used
+			fixture: "requests-2.31.0",
+			want:    lockfile.PackageDetails{Name: "requests", Version: "2.31.0", Licenses: []string{"Apache-2.0"}},
+		},
+		{
+			fixture: "attrs-24.2.0",
+			want:    lockfile.PackageDetails{Name: "attrs", Version: "24.2.0", Licenses: []string{"MIT"}},
+		},
+		{
+			fixture: "Dual_Licensed-1.0.0",
+			want:    lockfile.PackageDetails{Name: "dual-licensed", Version: "1.0.0", Licenses: []string{"MIT OR Apache-2.0"}},
+		},
+		{
+			// ambiguous classifiers such as "BSD License" are not used
+			fixture: "license-text-1.0.0",
+			want:    lockfile.PackageDetails{Name: "license-text", Version: "1.0.0", Licenses: []string{"(MIT OR ISC)"}},
+		},
+		{
+			fixture: "headers-only-1.0.0",
+			want:    lockfile.PackageDetails{Name: "headers-only", Version: "1.0.0"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			t.Parallel()
+
+			packages, err := lockfile.ParsePythonMetadata("fixtures/python-metadata/" + tt.fixture + ".dist-info/METADATA")
+			if err != nil {
+				t.Errorf("Got unexpected error: %v", err)
+			}
+
+			tt.want.PackageManager = models.Unknown
+			tt.want.Ecosystem = lockfile.PipEcosystem
+			tt.want.CompareAs = lockfile.PipEcosystem
+			tt.want.Dir = "."
+
+			expectPackages(t, packages, []lockfile.PackageDetails{tt.want})
+		})
+	}
+}
@@ -20,6 +20,12 @@ type PackageDetails struct {
 	PackageManager  models.PackageManager `json:"packageManager,omitempty"`
 	IsDirect        bool                  `json:"isDirect,omitempty"`
 	Dependencies    []*PackageDetails     `json:"dependencies,omitempty"`
+	// Licenses are the licenses that the package declares in the files that
+	// it was extracted from, which are SPDX expressions where possible
+	Licenses []string `json:"licenses,omitempty"`
+	// Dir is the directory that the package is installed or vendored in,
+	// relative to the directory of the file that it was extracted from
+	Dir string `json:"dir,omitempty"`
 }
 
 type Ecosystem string
@@ -10,6 +10,9 @@ type VulnerabilityResults struct {
 	Results                    []PackageSource            `json:"results"`
 	Artifacts                  []ScannedArtifact          `json:"artifacts,omitempty"`
 	ExperimentalAnalysisConfig ExperimentalAnalysisConfig `json:"experimental_config"`
+	// Resolved are the packages affected by vulnerabilities that are no longer
+	// present, when comparing the results of two revisions
+	Resolved []PackageSource `json:"resolved,omitempty"`
 }
 
 type ArtifactDetail struct {
@@ -33,6 +36,15 @@ type ExperimentalAnalysisConfig struct {
 type ExperimentalLicenseConfig struct {
 	Summary   bool      `json:"summary"`
 	Allowlist []License `json:"allowlist"`
+	Denylist  []License `json:"denylist,omitempty"`
+	// FromConfig is if the licenses of packages were checked against the
+	// license policies of the configs of their sources
+	FromConfig bool `json:"from_config,omitempty"`
+}
+
+// HasPolicy reports if the licenses of packages were checked against a policy
+func (c ExperimentalLicenseConfig) HasPolicy() bool {
+	return len(c.Allowlist) > 0 || len(c.Denylist) > 0 || c.FromConfig
 }
 
 // Flatten the grouped/nested vulnerability results into one flat array.
@@ -107,6 +119,27 @@ type PackageSource struct {
 // License is an SPDX license.
 type License string
 
+// LicenseSource is where the licenses of a package were found
+type LicenseSource string
+
+const (
+	// LicenseSourceDepsDev is for licenses retrieved from the deps.dev API
+	LicenseSourceDepsDev LicenseSource = "deps.dev"
+	// LicenseSourceLocal is for licenses declared by the package in the
+	// files that it was extracted from, such as its manifest
+	LicenseSourceLocal LicenseSource = "local"
+	// LicenseSourceOverride is for licenses overridden by the config
+	LicenseSourceOverride LicenseSource = "override"
+)
+
+// LicenseNotice is a file in which a package gives its license or copyright
+// notices, such as its LICENSE file
+type LicenseNotice struct {
+	// Path is relative to the directory of the package
+	Path string `json:"path"`
+	Text string `json:"text"`
+}
+
 // Vulnerabilities grouped by package
 // TODO: rename this to be Package as it now includes license information too.
 type PackageVulns struct {
@@ -116,7 +149,9 @@ type PackageVulns struct {
 	Vulnerabilities   []Vulnerability    `json:"vulnerabilities,omitempty"`
 	Groups            []GroupInfo        `json:"groups,omitempty"`
 	Licenses          []License          `json:"licenses,omitempty"`
+	LicenseSource     LicenseSource      `json:"license_source,omitempty"`
 	LicenseViolations []License          `json:"license_violations,omitempty"`
+	LicenseNotices    []LicenseNotice    `json:"license_notices,omitempty"`
 	Metadata          PackageMetadata    `json:"metadata,omitempty"`
 }
 
@@ -128,6 +163,38 @@ type GroupInfo struct {
 	// Map of Vulnerability IDs to AnalysisInfo
 	ExperimentalAnalysis map[string]AnalysisInfo `json:"experimentalAnalysis,omitempty"`
 	MaxSeverity          string                  `json:"max_severity"`
+	// EnvironmentalSeverity is the highest CVSS score of the group when it is
+	// scored with the environmental metrics of the config, if any apply to it
+	EnvironmentalSeverity string `json:"environmental_severity,omitempty"`
+	// Policy is the decision of the policy from the config that applied to
+	// the group, if any
+	Policy *PolicyDecision `json:"policy,omitempty"`
+}
+
+// PolicyAction is what happens to the vulnerabilities that a policy applies to
+type PolicyAction string
+
+const (
+	PolicyActionFail   PolicyAction = "fail"
+	PolicyActionWarn   PolicyAction = "warn"
+	PolicyActionIgnore PolicyAction = "ignore"
+)
+
+// PolicyDecision is the action of a policy that applied to a group of
+// vulnerabilities, along with the reason given for the policy
+type PolicyDecision struct {
+	Action PolicyAction `json:"action"`
+	Reason string       `json:"reason,omitempty"`
+}
+
+// Severity returns the highest CVSS score of the group that it is ranked
+// by, which is its environmental score if it has one
+func (groupInfo *GroupInfo) Severity() string {
+	if groupInfo.EnvironmentalSeverity != "" {
+		return groupInfo.EnvironmentalSeverity
+	}
+
+	return groupInfo.MaxSeverity
 }
 
 // IsCalled returns true if any analysis performed determines that the vulnerability is being called
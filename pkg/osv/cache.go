@@ -0,0 +1,243 @@
+package osv
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/osv-scanner/pkg/models"
+)
+
+// DefaultCacheQueryTTL is how long query results are reused for by default.
+const DefaultCacheQueryTTL = time.Hour
+
+const envKeyAPICacheDirectory = "OSV_SCANNER_API_CACHE_DIRECTORY"
+
+// Cache is an on-disk cache of responses from the OSV API, which can be
+// shared between runs (and processes) by pointing them at the same directory.
+//
+// Vulnerabilities are keyed by their ID and modified timestamp, so they are
+// only fetched again when the advisory has changed, while the results of
+// queries are reused for QueryTTL as new advisories can be published at any time.
+//
+// Responses are stored separately for each endpoint, so that the same
+// directory can be shared by scans that use different instances of the API.
+type Cache struct {
+	// the directory that responses are stored in
+	Dir string
+	// how long the results of a query should be reused for
+	QueryTTL time.Duration
+
+	queryHits   atomic.Int64
+	queryMisses atomic.Int64
+	vulnHits    atomic.Int64
+	vulnMisses  atomic.Int64
+}
+
+// CacheStats describes how many responses were served from a Cache.
+type CacheStats struct {
+	QueryHits   int
+	QueryMisses int
+	VulnHits    int
+	VulnMisses  int
+}
+
+type cachedQuery struct {
+	FetchedAt time.Time              `json:"fetched_at"`
+	Vulns     []MinimalVulnerability `json:"vulns"`
+}
+
+type cachedVulnerability struct {
+	// the modified timestamp reported by the query endpoint, which is kept
+	// separately as the vulnerability does not round-trip sub-second precision
+	Modified      time.Time            `json:"modified"`
+	Vulnerability models.Vulnerability `json:"vulnerability"`
+}
+
+// DefaultCacheDir returns the directory that the cache should be stored in
+// if one has not been explicitly provided, either the value of the
+// OSV_SCANNER_API_CACHE_DIRECTORY environment variable or a directory
+// within the user cache directory.
+func DefaultCacheDir() (string, error) {
+	if p, envSet := os.LookupEnv(envKeyAPICacheDirectory); envSet {
+		return p, nil
+	}
+
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+
+	return filepath.Join(dir, "osv-scanner", "api-cache"), nil
+}
+
+// NewCache creates a Cache that stores responses in the given directory,
+// creating it if it does not already exist.
+func NewCache(dir string, queryTTL time.Duration) (*Cache, error) {
+	for _, sub := range []string{"queries", "vulns"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0750); err != nil {
+			return nil, fmt.Errorf("could not create cache directory: %w", err)
+		}
+	}
+
+	return &Cache{Dir: dir, QueryTTL: queryTTL}, nil
+}
+
+// Stats returns how many queries and vulnerabilities have been served
+// from the cache so far.
+func (c *Cache) Stats() CacheStats {
+	return CacheStats{
+		QueryHits:   int(c.queryHits.Load()),
+		QueryMisses: int(c.queryMisses.Load()),
+		VulnHits:    int(c.vulnHits.Load()),
+		VulnMisses:  int(c.vulnMisses.Load()),
+	}
+}
+
+// endpointDir returns the directory that responses from the given endpoint
+// are stored in, within the given subdirectory of the cache
+func (c *Cache) endpointDir(sub, endpoint string) string {
+	sum := sha256.Sum256([]byte(endpoint))
+
+	return filepath.Join(c.Dir, sub, hex.EncodeToString(sum[:]))
+}
+
+// queryCachePath returns where the results of the given query to the given
+// endpoint are stored
+func (c *Cache) queryCachePath(endpoint string, query *Query) (string, error) {
+	b, err := json.Marshal(query)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(b)
+
+	return filepath.Join(c.endpointDir("queries", endpoint), hex.EncodeToString(sum[:])+".json"), nil
+}
+
+// vulnCachePath returns where the vulnerability with the given id from the
+// given endpoint is stored, replacing characters that are not valid in file
+// names on all platforms
+func (c *Cache) vulnCachePath(endpoint, id string) string {
+	return filepath.Join(c.endpointDir("vulns", endpoint), strings.NewReplacer(":", "_", "/", "_", "\\", "_").Replace(id)+".json")
+}
+
+func (c *Cache) readJSON(path string, v any) bool {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+
+	return json.Unmarshal(b, v) == nil
+}
+
+// writeJSON writes to a temporary file and then renames it, so that other
+// processes sharing the cache never read a partially written entry
+func (c *Cache) writeJSON(path string, v any) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+
+	_, err = f.Write(b)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err == nil {
+		err = os.Rename(f.Name(), path)
+	}
+
+	if err != nil {
+		_ = os.Remove(f.Name())
+	}
+
+	return err
+}
+
+func (c *Cache) getQuery(endpoint string, query *Query) ([]MinimalVulnerability, bool) {
+	path, err := c.queryCachePath(endpoint, query)
+	if err != nil {
+		return nil, false
+	}
+
+	var cached cachedQuery
+	if !c.readJSON(path, &cached) || time.Since(cached.FetchedAt) >= c.QueryTTL {
+		c.queryMisses.Add(1)
+
+		return nil, false
+	}
+
+	c.queryHits.Add(1)
+
+	return cached.Vulns, true
+}
+
+func (c *Cache) putQuery(endpoint string, query *Query, vulns []MinimalVulnerability) error {
+	path, err := c.queryCachePath(endpoint, query)
+	if err != nil {
+		return err
+	}
+
+	return c.writeJSON(path, cachedQuery{
+		FetchedAt: time.Now().UTC(),
+		Vulns:     vulns,
+	})
+}
+
+func (c *Cache) getVuln(endpoint string, vuln MinimalVulnerability) (*models.Vulnerability, bool) {
+	var cached cachedVulnerability
+
+	// without a modified timestamp there is no way of knowing if the cached copy is stale
+	if vuln.Modified.IsZero() || !c.readJSON(c.vulnCachePath(endpoint, vuln.ID), &cached) || !cached.Modified.Equal(vuln.Modified) {
+		c.vulnMisses.Add(1)
+
+		return nil, false
+	}
+
+	c.vulnHits.Add(1)
+
+	return &cached.Vulnerability, true
+}
+
+func (c *Cache) putVuln(endpoint string, modified time.Time, vuln *models.Vulnerability) error {
+	if modified.IsZero() {
+		return nil
+	}
+
+	return c.writeJSON(c.vulnCachePath(endpoint, vuln.ID), cachedVulnerability{
+		Modified:      modified,
+		Vulnerability: *vuln,
+	})
+}
+
+// MakeRequestWithCache sends a batched query to osv.dev with the provided
+// http client, only querying for the packages that do not have a result
+// in the cache.
+func MakeRequestWithCache(request BatchedQuery, client *http.Client, cache *Cache) (*BatchedResponse, error) {
+	return (&Client{HTTPClient: client, Cache: cache}).MakeRequest(context.Background(), request)
+}
+
+// HydrateWithCache fills the results of the batched response with the full
+// Vulnerability details using the provided http client, only fetching the
+// vulnerabilities that are not in the cache or that have since been modified.
+func HydrateWithCache(resp *BatchedResponse, client *http.Client, cache *Cache) (*HydratedBatchedResponse, error) {
+	return (&Client{HTTPClient: client, Cache: cache}).Hydrate(context.Background(), resp)
+}
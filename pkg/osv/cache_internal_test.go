@@ -0,0 +1,96 @@
+package osv
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/osv-scanner/pkg/models"
+)
+
+func TestCache_Queries(t *testing.T) {
+	t.Parallel()
+
+	cache, err := NewCache(t.TempDir(), time.Hour)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	query := MakePURLRequest("pkg:npm/lodash@4.17.20")
+	vulns := []MinimalVulnerability{{ID: "GHSA-1"}, {ID: "GHSA-2"}}
+
+	if _, ok := cache.getQuery(QueryEndpoint, query); ok {
+		t.Errorf("expected query to not be cached")
+	}
+
+	if err := cache.putQuery(QueryEndpoint, query, vulns); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got, ok := cache.getQuery(QueryEndpoint, query)
+	if !ok {
+		t.Fatalf("expected query to be cached")
+	}
+	if len(got) != 2 || got[0].ID != "GHSA-1" || got[1].ID != "GHSA-2" {
+		t.Errorf("unexpected cached vulns: %v", got)
+	}
+
+	// nor should the results of other instances of the API
+	if _, ok := cache.getQuery("https://osv.example.com/v1/querybatch", query); ok {
+		t.Errorf("expected query to another endpoint to not be cached")
+	}
+
+	// the results should not be reused once they have expired
+	cache.QueryTTL = 0
+	if _, ok := cache.getQuery(QueryEndpoint, query); ok {
+		t.Errorf("expected expired query to not be used")
+	}
+
+	want := CacheStats{QueryHits: 1, QueryMisses: 3}
+	if stats := cache.Stats(); stats != want {
+		t.Errorf("Stats() = %+v, want %+v", stats, want)
+	}
+}
+
+func TestCache_Vulns(t *testing.T) {
+	t.Parallel()
+
+	cache, err := NewCache(t.TempDir(), time.Hour)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	modified := time.Date(2024, 5, 10, 12, 34, 56, 123456000, time.UTC)
+	vuln := &models.Vulnerability{ID: "RHSA-2024:1234", Modified: modified.Truncate(time.Second)}
+
+	if err := cache.putVuln(GetEndpoint, modified, vuln); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got, ok := cache.getVuln(GetEndpoint, MinimalVulnerability{ID: vuln.ID, Modified: modified})
+	if !ok {
+		t.Fatalf("expected vulnerability to be cached")
+	}
+	if got.ID != vuln.ID {
+		t.Errorf("unexpected cached vulnerability: %v", got.ID)
+	}
+
+	// a vulnerability that has since been modified should be fetched again
+	if _, ok := cache.getVuln(GetEndpoint, MinimalVulnerability{ID: vuln.ID, Modified: modified.Add(time.Hour)}); ok {
+		t.Errorf("expected modified vulnerability to not be used")
+	}
+
+	// nor should the vulnerabilities of other instances of the API
+	if _, ok := cache.getVuln("https://osv.example.com/v1/vulns", MinimalVulnerability{ID: vuln.ID, Modified: modified}); ok {
+		t.Errorf("expected vulnerability from another endpoint to not be used")
+	}
+
+	// without a modified timestamp there is no way to tell if the cache is stale
+	if _, ok := cache.getVuln(GetEndpoint, MinimalVulnerability{ID: vuln.ID}); ok {
+		t.Errorf("expected vulnerability without a timestamp to not be used")
+	}
+
+	want := CacheStats{VulnHits: 1, VulnMisses: 3}
+	if stats := cache.Stats(); stats != want {
+		t.Errorf("Stats() = %+v, want %+v", stats, want)
+	}
+}
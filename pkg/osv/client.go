@@ -0,0 +1,329 @@
+package osv
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/osv-scanner/pkg/models"
+)
+
+// ClientConfig describes how to connect to the OSV API, or to any service
+// that implements the same API (such as an internal proxy).
+type ClientConfig struct {
+	// BaseURL is the URL that the API endpoints are relative to,
+	// defaulting to DefaultBaseURL (i.e. without the "/v1" path)
+	BaseURL string
+	// Headers are added to every request, such as for authentication
+	Headers map[string]string
+	// UserAgent is sent with every request, defaulting to RequestUserAgent
+	UserAgent string
+
+	// CACertFile is the path to a PEM bundle of certificates that are
+	// trusted in addition to the system certificates
+	CACertFile string
+	// InsecureSkipVerify disables verifying the certificate of the server
+	InsecureSkipVerify bool
+	// ProxyURL is the HTTP proxy to connect through, otherwise the proxy
+	// is taken from the environment
+	ProxyURL string
+	// Timeout is the time limit for each request, which is unlimited if zero
+	Timeout time.Duration
+
+	// MaxRetryAttempts is how many times a request is attempted before
+	// giving up, defaulting to 4
+	MaxRetryAttempts int
+	// MaxConcurrentRequests is how many vulnerabilities are fetched at once
+	// when hydrating, defaulting to 25
+	MaxConcurrentRequests int
+}
+
+// Client makes requests to the OSV API.
+//
+// The zero value is usable and behaves like the package level functions,
+// using http.DefaultClient to make requests to osv.dev.
+type Client struct {
+	HTTPClient *http.Client
+	BaseURL    string
+	Headers    map[string]string
+	UserAgent  string
+
+	MaxRetryAttempts      int
+	MaxConcurrentRequests int
+
+	// Cache is used to reuse responses from previous requests, if set
+	Cache *Cache
+}
+
+// NewClient creates a Client from the given config, setting up an
+// http.Client with the requested transport settings.
+func NewClient(config ClientConfig) (*Client, error) {
+	httpClient, err := newHTTPClient(config)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.MaxRetryAttempts < 0 {
+		return nil, errors.New("max retry attempts cannot be negative")
+	}
+
+	if config.MaxConcurrentRequests < 0 {
+		return nil, errors.New("max concurrent requests cannot be negative")
+	}
+
+	return &Client{
+		HTTPClient:            httpClient,
+		BaseURL:               config.BaseURL,
+		Headers:               config.Headers,
+		UserAgent:             config.UserAgent,
+		MaxRetryAttempts:      config.MaxRetryAttempts,
+		MaxConcurrentRequests: config.MaxConcurrentRequests,
+	}, nil
+}
+
+func newHTTPClient(config ClientConfig) (*http.Client, error) {
+	if config.CACertFile == "" && !config.InsecureSkipVerify && config.ProxyURL == "" && config.Timeout == 0 {
+		return http.DefaultClient, nil
+	}
+
+	transport, ok := http.DefaultTransport.(*http.Transport)
+	if !ok {
+		return nil, errors.New("default transport is not an *http.Transport")
+	}
+	transport = transport.Clone()
+
+	if config.CACertFile != "" || config.InsecureSkipVerify {
+		tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+		if config.CACertFile != "" {
+			pool, err := x509.SystemCertPool()
+			if err != nil {
+				pool = x509.NewCertPool()
+			}
+
+			pem, err := os.ReadFile(config.CACertFile)
+			if err != nil {
+				return nil, fmt.Errorf("could not read CA certificates: %w", err)
+			}
+
+			if !pool.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("no certificates found in %s", config.CACertFile)
+			}
+
+			tlsConfig.RootCAs = pool
+		}
+
+		//nolint:gosec // this is explicitly requested by the user
+		tlsConfig.InsecureSkipVerify = config.InsecureSkipVerify
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	if config.ProxyURL != "" {
+		proxy, err := url.Parse(config.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy url: %w", err)
+		}
+
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+
+	return &http.Client{Transport: transport, Timeout: config.Timeout}, nil
+}
+
+func (c *Client) httpClient() *http.Client {
+	if c.HTTPClient == nil {
+		return http.DefaultClient
+	}
+
+	return c.HTTPClient
+}
+
+func (c *Client) queryEndpoint() string {
+	if c.BaseURL == "" {
+		return QueryEndpoint
+	}
+
+	return strings.TrimSuffix(c.BaseURL, "/") + "/v1/querybatch"
+}
+
+func (c *Client) getEndpoint() string {
+	if c.BaseURL == "" {
+		return GetEndpoint
+	}
+
+	return strings.TrimSuffix(c.BaseURL, "/") + "/v1/vulns"
+}
+
+func (c *Client) maxRetryAttempts() int {
+	if c.MaxRetryAttempts <= 0 {
+		return maxRetryAttempts
+	}
+
+	return c.MaxRetryAttempts
+}
+
+func (c *Client) maxConcurrentRequests() int {
+	if c.MaxConcurrentRequests <= 0 {
+		return maxConcurrentRequests
+	}
+
+	return c.MaxConcurrentRequests
+}
+
+// setHeaders adds the user agent and any configured headers to the request
+func (c *Client) setHeaders(req *http.Request) {
+	userAgent := c.UserAgent
+	if userAgent == "" {
+		userAgent = RequestUserAgent
+	}
+
+	if userAgent != "" {
+		req.Header.Set("User-Agent", userAgent)
+	}
+
+	for name, value := range c.Headers {
+		req.Header.Set(name, value)
+	}
+}
+
+// MakeRequest sends a batched query to the API, only querying for the
+// packages that do not have a result in the cache (if there is one).
+func (c *Client) MakeRequest(ctx context.Context, request BatchedQuery) (*BatchedResponse, error) {
+	if c.Cache == nil {
+		return c.makeRequest(ctx, request)
+	}
+
+	results := make([]MinimalResponse, len(request.Queries))
+
+	var uncached BatchedQuery
+	var uncachedIdx []int
+
+	for i, query := range request.Queries {
+		if vulns, ok := c.Cache.getQuery(c.queryEndpoint(), query); ok {
+			results[i] = MinimalResponse{Vulns: vulns}
+
+			continue
+		}
+
+		uncached.Queries = append(uncached.Queries, query)
+		uncachedIdx = append(uncachedIdx, i)
+	}
+
+	if len(uncached.Queries) > 0 {
+		resp, err := c.makeRequest(ctx, uncached)
+		if err != nil {
+			return nil, err
+		}
+
+		for i, result := range resp.Results {
+			results[uncachedIdx[i]] = result
+
+			// failing to write to the cache only means we'll have to query again next time
+			_ = c.Cache.putQuery(c.queryEndpoint(), uncached.Queries[i], result.Vulns)
+		}
+	}
+
+	return &BatchedResponse{Results: results}, nil
+}
+
+func (c *Client) makeRequest(ctx context.Context, request BatchedQuery) (*BatchedResponse, error) {
+	// API has a limit of 1000 bulk query per request
+	queryChunks := chunkBy(request.Queries, maxQueriesPerRequest)
+	var totalOsvResp BatchedResponse
+	for _, queries := range queryChunks {
+		requestBytes, err := json.Marshal(BatchedQuery{Queries: queries})
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := makeRetryRequest(ctx, c.maxRetryAttempts(), func() (*http.Response, error) {
+			// Make sure request buffer is inside retry, if outside
+			// http request would finish the buffer, and retried requests would be empty
+			requestBuf := bytes.NewBuffer(requestBytes)
+			req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.queryEndpoint(), requestBuf)
+			if err != nil {
+				return nil, err
+			}
+			req.Header.Set("Content-Type", "application/json")
+			c.setHeaders(req)
+
+			return c.httpClient().Do(req)
+		})
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+
+		var osvResp BatchedResponse
+		decoder := json.NewDecoder(resp.Body)
+		err = decoder.Decode(&osvResp)
+		if err != nil {
+			return nil, err
+		}
+
+		totalOsvResp.Results = append(totalOsvResp.Results, osvResp.Results...)
+	}
+
+	return &totalOsvResp, nil
+}
+
+// Get gets a Vulnerability for the given ID.
+func (c *Client) Get(ctx context.Context, id string) (*models.Vulnerability, error) {
+	resp, err := makeRetryRequest(ctx, c.maxRetryAttempts(), func() (*http.Response, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.getEndpoint()+"/"+id, nil)
+		if err != nil {
+			return nil, err
+		}
+		c.setHeaders(req)
+
+		return c.httpClient().Do(req)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	var vuln models.Vulnerability
+	decoder := json.NewDecoder(resp.Body)
+	err = decoder.Decode(&vuln)
+	if err != nil {
+		return nil, err
+	}
+
+	return &vuln, nil
+}
+
+// Hydrate fills the results of the batched response with the full
+// Vulnerability details, only fetching the vulnerabilities that are not in
+// the cache (if there is one) or that have since been modified.
+func (c *Client) Hydrate(ctx context.Context, resp *BatchedResponse) (*HydratedBatchedResponse, error) {
+	return hydrate(ctx, resp, c.maxConcurrentRequests(), func(ctx context.Context, vuln MinimalVulnerability) (*models.Vulnerability, error) {
+		if c.Cache != nil {
+			if cached, ok := c.Cache.getVuln(c.getEndpoint(), vuln); ok {
+				return cached, nil
+			}
+		}
+
+		hydrated, err := c.Get(ctx, vuln.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		if c.Cache != nil {
+			// failing to write to the cache only means we'll have to fetch it again next time
+			_ = c.Cache.putVuln(c.getEndpoint(), vuln.Modified, hydrated)
+		}
+
+		return hydrated, nil
+	})
+}
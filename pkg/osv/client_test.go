@@ -0,0 +1,161 @@
+package osv_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/osv-scanner/pkg/models"
+	"github.com/google/osv-scanner/pkg/osv"
+)
+
+func TestClient_CustomEndpointAndHeaders(t *testing.T) {
+	t.Parallel()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/querybatch", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(osv.BatchedResponse{
+			Results: []osv.MinimalResponse{{Vulns: []osv.MinimalVulnerability{{ID: "OSV-1"}}}},
+		})
+	})
+	mux.HandleFunc("/api/v1/vulns/OSV-1", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(models.Vulnerability{ID: "OSV-1", Summary: "from the proxy"})
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client, err := osv.NewClient(osv.ClientConfig{
+		BaseURL:          server.URL + "/api/",
+		Headers:          map[string]string{"Authorization": "Bearer token"},
+		MaxRetryAttempts: 1,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	resp, err := client.MakeRequest(context.Background(), osv.BatchedQuery{Queries: []*osv.Query{osv.MakePURLRequest("pkg:npm/lodash@4.17.20")}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	hydrated, err := client.Hydrate(context.Background(), resp)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(hydrated.Results) != 1 || len(hydrated.Results[0].Vulns) != 1 {
+		t.Fatalf("unexpected results: %v", hydrated.Results)
+	}
+
+	if got := hydrated.Results[0].Vulns[0].Summary; got != "from the proxy" {
+		t.Errorf("unexpected summary: %s", got)
+	}
+}
+
+func TestClient_MaxRetryAttempts(t *testing.T) {
+	t.Parallel()
+
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	client, err := osv.NewClient(osv.ClientConfig{BaseURL: server.URL, MaxRetryAttempts: 2})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := client.Get(context.Background(), "OSV-1"); err == nil {
+		t.Errorf("expected an error")
+	}
+
+	if attempts != 2 {
+		t.Errorf("expected 2 attempts, got %d", attempts)
+	}
+}
+
+func TestClient_Cancelled(t *testing.T) {
+	t.Parallel()
+
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	client, err := osv.NewClient(osv.ClientConfig{BaseURL: server.URL})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = client.Get(ctx, "OSV-1")
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+
+	if attempts != 0 {
+		t.Errorf("expected no attempts, got %d", attempts)
+	}
+}
+
+func TestClient_HydrateCancelled(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(models.Vulnerability{ID: "OSV-1"})
+	}))
+	defer server.Close()
+
+	client, err := osv.NewClient(osv.ClientConfig{BaseURL: server.URL})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	resp := &osv.BatchedResponse{Results: []osv.MinimalResponse{{Vulns: []osv.MinimalVulnerability{{ID: "OSV-1"}}}}}
+
+	if _, err := client.Hydrate(ctx, resp); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestNewClient_InvalidConfig(t *testing.T) {
+	t.Parallel()
+
+	tests := []osv.ClientConfig{
+		{CACertFile: "fixtures/does-not-exist.pem"},
+		{ProxyURL: "://not a url"},
+		{MaxRetryAttempts: -1},
+		{MaxConcurrentRequests: -1},
+	}
+
+	for _, config := range tests {
+		if _, err := osv.NewClient(config); err == nil {
+			t.Errorf("expected an error for %+v", config)
+		}
+	}
+}
@@ -1,9 +1,7 @@
 package osv
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
 	"io"
 	"math/rand"
@@ -17,10 +15,12 @@ import (
 )
 
 const (
+	// DefaultBaseURL is the base URL of the OSV API.
+	DefaultBaseURL = "https://api.osv.dev"
 	// QueryEndpoint is the URL for posting queries to OSV.
-	QueryEndpoint = "https://api.osv.dev/v1/querybatch"
+	QueryEndpoint = DefaultBaseURL + "/v1/querybatch"
 	// GetEndpoint is the URL for getting vulenrabilities from OSV.
-	GetEndpoint = "https://api.osv.dev/v1/vulns"
+	GetEndpoint = DefaultBaseURL + "/v1/vulns"
 	// BaseVulnerabilityURL is the base URL for detailed vulnerability views.
 	BaseVulnerabilityURL = "https://osv.dev/"
 	// maxQueriesPerRequest splits up querybatch into multiple requests if
@@ -57,7 +57,8 @@ type BatchedQuery struct {
 
 // MinimalVulnerability represents an unhydrated vulnerability entry from OSV.
 type MinimalVulnerability struct {
-	ID string `json:"id"`
+	ID       string    `json:"id"`
+	Modified time.Time `json:"modified"`
 }
 
 // Response represents a full response from OSV.
@@ -153,48 +154,7 @@ func MakeRequest(request BatchedQuery) (*BatchedResponse, error) {
 // MakeRequestWithClient sends a batched query to osv.dev with the provided
 // http client.
 func MakeRequestWithClient(request BatchedQuery, client *http.Client) (*BatchedResponse, error) {
-	// API has a limit of 1000 bulk query per request
-	queryChunks := chunkBy(request.Queries, maxQueriesPerRequest)
-	var totalOsvResp BatchedResponse
-	for _, queries := range queryChunks {
-		requestBytes, err := json.Marshal(BatchedQuery{Queries: queries})
-		if err != nil {
-			return nil, err
-		}
-
-		resp, err := makeRetryRequest(func() (*http.Response, error) {
-			// Make sure request buffer is inside retry, if outside
-			// http request would finish the buffer, and retried requests would be empty
-			requestBuf := bytes.NewBuffer(requestBytes)
-			// We do not need a specific context
-			//nolint:noctx
-			req, err := http.NewRequest(http.MethodPost, QueryEndpoint, requestBuf)
-			if err != nil {
-				return nil, err
-			}
-			req.Header.Set("Content-Type", "application/json")
-			if RequestUserAgent != "" {
-				req.Header.Set("User-Agent", RequestUserAgent)
-			}
-
-			return client.Do(req)
-		})
-		if err != nil {
-			return nil, err
-		}
-		defer resp.Body.Close()
-
-		var osvResp BatchedResponse
-		decoder := json.NewDecoder(resp.Body)
-		err = decoder.Decode(&osvResp)
-		if err != nil {
-			return nil, err
-		}
-
-		totalOsvResp.Results = append(totalOsvResp.Results, osvResp.Results...)
-	}
-
-	return &totalOsvResp, nil
+	return (&Client{HTTPClient: client}).MakeRequest(context.Background(), request)
 }
 
 // Get a Vulnerability for the given ID.
@@ -205,33 +165,7 @@ func Get(id string) (*models.Vulnerability, error) {
 // GetWithClient gets a Vulnerability for the given ID with the provided http
 // client.
 func GetWithClient(id string, client *http.Client) (*models.Vulnerability, error) {
-	resp, err := makeRetryRequest(func() (*http.Response, error) {
-		// We do not need a specific context
-		//nolint:noctx
-		req, err := http.NewRequest(http.MethodGet, GetEndpoint+"/"+id, nil)
-		if err != nil {
-			return nil, err
-		}
-		if RequestUserAgent != "" {
-			req.Header.Set("User-Agent", RequestUserAgent)
-		}
-
-		return client.Do(req)
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	defer resp.Body.Close()
-
-	var vuln models.Vulnerability
-	decoder := json.NewDecoder(resp.Body)
-	err = decoder.Decode(&vuln)
-	if err != nil {
-		return nil, err
-	}
-
-	return &vuln, nil
+	return (&Client{HTTPClient: client}).Get(context.Background(), id)
 }
 
 // Hydrate fills the results of the batched response with the full
@@ -243,6 +177,12 @@ func Hydrate(resp *BatchedResponse) (*HydratedBatchedResponse, error) {
 // HydrateWithClient fills the results of the batched response with the full
 // Vulnerability details using the provided http client.
 func HydrateWithClient(resp *BatchedResponse, client *http.Client) (*HydratedBatchedResponse, error) {
+	return (&Client{HTTPClient: client}).Hydrate(context.Background(), resp)
+}
+
+// hydrate fills the results of the batched response using fetch to get
+// the full details of each vulnerability, making at most limit requests at once
+func hydrate(ctx context.Context, resp *BatchedResponse, limit int, fetch func(context.Context, MinimalVulnerability) (*models.Vulnerability, error)) (*HydratedBatchedResponse, error) {
 	hydrated := HydratedBatchedResponse{}
 	// Preallocate the array to avoid slice reallocations when inserting later
 	hydrated.Results = make([]Response, len(resp.Results))
@@ -250,22 +190,21 @@ func HydrateWithClient(resp *BatchedResponse, client *http.Client) (*HydratedBat
 		hydrated.Results[idx].Vulns = make([]models.Vulnerability, len(resp.Results[idx].Vulns))
 	}
 
-	g, ctx := errgroup.WithContext(context.TODO())
-	g.SetLimit(maxConcurrentRequests)
+	g, groupCtx := errgroup.WithContext(ctx)
+	g.SetLimit(limit)
 	for batchIdx, response := range resp.Results {
 		for resultIdx, vuln := range response.Vulns {
-			id := vuln.ID
 			g.Go(func() error {
 				// exit early if another hydration request has already failed
 				// results are thrown away later, so avoid needless work
-				if ctx.Err() != nil {
+				if groupCtx.Err() != nil {
 					return nil //nolint:nilerr // this value doesn't matter to errgroup.Wait()
 				}
-				vuln, err := GetWithClient(id, client)
+				hydratedVuln, err := fetch(groupCtx, vuln)
 				if err != nil {
 					return err
 				}
-				hydrated.Results[batchIdx].Vulns[resultIdx] = *vuln
+				hydrated.Results[batchIdx].Vulns[resultIdx] = *hydratedVuln
 
 				return nil
 			})
@@ -276,20 +215,33 @@ func HydrateWithClient(resp *BatchedResponse, client *http.Client) (*HydratedBat
 		return nil, err
 	}
 
+	// requests that were skipped because the scan was cancelled do not error,
+	// so the results would otherwise be missing vulnerabilities without saying
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	return &hydrated, nil
 }
 
-// makeRetryRequest will return an error on both network errors, and if the response is not 200
-func makeRetryRequest(action func() (*http.Response, error)) (*http.Response, error) {
+// makeRetryRequest will return an error on both network errors, and if the response is not 200,
+// stopping early if the context is cancelled while waiting to retry
+func makeRetryRequest(ctx context.Context, attempts int, action func() (*http.Response, error)) (*http.Response, error) {
 	var resp *http.Response
 	var err error
 
-	for i := range maxRetryAttempts {
+	for i := range attempts {
 		// rand is initialized with a random number (since go1.20), and is also safe to use concurrently
 		// we do not need to use a cryptographically secure random jitter, this is just to spread out the retry requests
 		// #nosec G404
 		jitterAmount := (rand.Float64() * float64(jitterMultiplier) * float64(i))
-		time.Sleep(time.Duration(i*i)*time.Second + time.Duration(jitterAmount*1000)*time.Millisecond)
+		delay := time.Duration(i*i)*time.Second + time.Duration(jitterAmount*1000)*time.Millisecond
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
 
 		resp, err = action()
 		if err == nil {
@@ -0,0 +1,281 @@
+package osvscanner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/google/osv-scanner/internal/ci"
+	"github.com/google/osv-scanner/internal/output"
+	"github.com/google/osv-scanner/pkg/models"
+)
+
+// baselineKey identifies a package within a source, without its version so
+// that findings remain matched when the package is upgraded (or downgraded)
+type baselineKey struct {
+	source    string
+	ecosystem string
+	name      string
+	commit    string
+}
+
+func newBaselineKey(path string, pkg models.PackageInfo) baselineKey {
+	key := baselineKey{source: path, ecosystem: pkg.Ecosystem, name: pkg.Name}
+
+	// packages without a name (such as git repositories) are identified by commit
+	if pkg.Name == "" {
+		key.commit = pkg.Commit
+	}
+
+	return key
+}
+
+// baselineRoots returns the directories that the paths of sources are made
+// relative to in baselines, being the directories that were scanned (closest
+// first) and then the working directory for sources outside of all of them
+func baselineRoots(actions ScannerActions) []string {
+	var roots []string
+
+	for _, dir := range actions.DirectoryPaths {
+		if abs, err := filepath.Abs(dir); err == nil {
+			roots = append(roots, abs)
+		}
+	}
+
+	slices.SortStableFunc(roots, func(a, b string) int { return len(b) - len(a) })
+
+	if wd, err := os.Getwd(); err == nil {
+		roots = append(roots, wd)
+	}
+
+	return roots
+}
+
+// baselinePath returns the path of the source relative to the closest of the
+// roots that it is within, so that baselines are not tied to where the
+// scanned directory is on disk
+func baselinePath(source models.SourceInfo, roots []string) string {
+	// paths are already relative to the directory they were found by scanning
+	if !filepath.IsAbs(source.Path) {
+		return filepath.ToSlash(source.Path)
+	}
+
+	for _, root := range roots {
+		rel, err := filepath.Rel(root, source.Path)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return filepath.ToSlash(rel)
+		}
+	}
+
+	return filepath.ToSlash(source.Path)
+}
+
+// baseline is the set of vulnerability IDs (including aliases) that were
+// already known to affect each package when the baseline was written
+type baseline struct {
+	// roots are what the paths of sources being compared to the baseline are relative to
+	roots []string
+	known map[baselineKey]map[string]struct{}
+}
+
+func newBaseline(results models.VulnerabilityResults, roots []string) *baseline {
+	b := &baseline{roots: roots, known: make(map[baselineKey]map[string]struct{})}
+
+	for _, source := range results.Results {
+		path := baselinePath(source.Source, roots)
+
+		for _, pkg := range source.Packages {
+			key := newBaselineKey(path, pkg.Package)
+
+			if b.known[key] == nil {
+				b.known[key] = make(map[string]struct{})
+			}
+
+			for _, vuln := range pkg.Vulnerabilities {
+				b.known[key][vuln.ID] = struct{}{}
+
+				for _, alias := range vuln.Aliases {
+					b.known[key][alias] = struct{}{}
+				}
+			}
+
+			for _, group := range pkg.Groups {
+				for _, id := range append(slices.Clone(group.IDs), group.Aliases...) {
+					b.known[key][id] = struct{}{}
+				}
+			}
+		}
+	}
+
+	return b
+}
+
+// contains returns true if any of the IDs of the group are in the baseline
+// for the package, as the group may have gained new aliases since
+func (b *baseline) contains(key baselineKey, group models.GroupInfo) bool {
+	ids := b.known[key]
+
+	for _, id := range append(slices.Clone(group.IDs), group.Aliases...) {
+		if _, ok := ids[id]; ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// suppress removes the vulnerability groups that are in the baseline from
+// the results, returning how many vulnerabilities were removed
+func (b *baseline) suppress(results *models.VulnerabilityResults, showAllPackages bool) int {
+	suppressed := 0
+	sources := results.Results[:0]
+
+	for _, source := range results.Results {
+		packages := source.Packages[:0]
+		path := baselinePath(source.Source, b.roots)
+
+		for _, pkg := range source.Packages {
+			key := newBaselineKey(path, pkg.Package)
+			hadVulns := len(pkg.Vulnerabilities) > 0
+
+			var groups []models.GroupInfo
+			var vulns []models.Vulnerability
+
+			for _, group := range pkg.Groups {
+				if !b.contains(key, group) {
+					groups = append(groups, group)
+
+					continue
+				}
+
+				for _, vuln := range pkg.Vulnerabilities {
+					if slices.Contains(group.IDs, vuln.ID) {
+						suppressed++
+					}
+				}
+			}
+
+			for _, vuln := range pkg.Vulnerabilities {
+				if slices.ContainsFunc(groups, func(g models.GroupInfo) bool { return slices.Contains(g.IDs, vuln.ID) }) {
+					vulns = append(vulns, vuln)
+				}
+			}
+
+			pkg.Groups = groups
+			pkg.Vulnerabilities = vulns
+
+			// packages are only dropped if they were only included for their vulnerabilities
+			if hadVulns && len(vulns) == 0 && len(pkg.LicenseViolations) == 0 && !showAllPackages {
+				continue
+			}
+
+			packages = append(packages, pkg)
+		}
+
+		source.Packages = packages
+
+		if len(source.Packages) > 0 {
+			sources = append(sources, source)
+		}
+	}
+
+	results.Results = sources
+
+	return suppressed
+}
+
+// loadBaseline loads the results of a previous scan to use as the baseline
+// for sources whose paths are relative to the given roots
+func loadBaseline(path string, roots []string) (*baseline, error) {
+	results, err := ci.LoadVulnResults(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not load baseline: %w", err)
+	}
+
+	return newBaseline(results, roots), nil
+}
+
+// writeBaseline writes the results in the same format as the JSON output,
+// so that the output of any previous scan can also be used as a baseline,
+// with the paths of sources made relative to the closest of the roots
+func writeBaseline(path string, results models.VulnerabilityResults, roots []string) error {
+	results.Results = slices.Clone(results.Results)
+	for i, source := range results.Results {
+		results.Results[i].Source.Path = baselinePath(source.Source, roots)
+		results.Results[i].Source.ScanPath = ""
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("could not write baseline: %w", err)
+	}
+	defer f.Close()
+
+	if err := output.PrintJSONResults(&results, f); err != nil {
+		return fmt.Errorf("could not write baseline: %w", err)
+	}
+
+	return f.Close()
+}
+
+// scanWithBaseline performs the osv scanner action using the failure
+// thresholds of the config, writing the results as a baseline and
+// suppressing the findings of an existing baseline if requested
+func (s *Scanner) scanWithBaseline(ctx context.Context, actions ScannerActions, fsys fs.FS) (models.VulnerabilityResults, error) {
+	actions, err := s.withFailOnConfig(actions, fsys)
+	if err != nil {
+		return models.VulnerabilityResults{}, err
+	}
+
+	if actions.BaselinePath == "" && actions.WriteBaselinePath == "" {
+		return s.scan(ctx, actions, fsys)
+	}
+
+	roots := baselineRoots(actions)
+
+	var known *baseline
+	if actions.BaselinePath != "" {
+		if known, err = loadBaseline(actions.BaselinePath, roots); err != nil {
+			return models.VulnerabilityResults{}, err
+		}
+	}
+
+	results, err := s.scan(ctx, actions, fsys)
+	if err != nil && !errors.Is(err, VulnerabilitiesFoundErr) {
+		return results, err
+	}
+
+	if actions.WriteBaselinePath != "" {
+		if err := writeBaseline(actions.WriteBaselinePath, results, roots); err != nil {
+			return results, err
+		}
+
+		s.reporter.Infof("Wrote baseline to %s\n", actions.WriteBaselinePath)
+	}
+
+	if known == nil {
+		return results, err
+	}
+
+	s.applyBaseline(known, &results, actions.ShowAllPackages)
+
+	return results, resultsError(results, actions)
+}
+
+// applyBaseline suppresses the findings of the results that are already in
+// the baseline, reporting how many of them there were
+func (s *Scanner) applyBaseline(known *baseline, results *models.VulnerabilityResults, showAllPackages bool) {
+	if suppressed := known.suppress(results, showAllPackages); suppressed > 0 {
+		s.reporter.Infof(
+			"Suppressed %d %s that %s already in the baseline\n",
+			suppressed,
+			output.Form(suppressed, "vulnerability", "vulnerabilities"),
+			output.Form(suppressed, "was", "were"),
+		)
+	}
+}
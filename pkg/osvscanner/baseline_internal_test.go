@@ -0,0 +1,133 @@
+package osvscanner
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/osv-scanner/pkg/models"
+)
+
+func packageVulns(name, version string, groups ...[]string) models.PackageVulns {
+	pkg := models.PackageVulns{
+		Package: models.PackageInfo{Name: name, Version: version, Ecosystem: "npm"},
+	}
+
+	for _, ids := range groups {
+		pkg.Groups = append(pkg.Groups, models.GroupInfo{IDs: ids[:1], Aliases: ids})
+		pkg.Vulnerabilities = append(pkg.Vulnerabilities, models.Vulnerability{ID: ids[0], Aliases: ids[1:]})
+	}
+
+	return pkg
+}
+
+func Test_baseline_suppress(t *testing.T) {
+	t.Parallel()
+
+	source := models.SourceInfo{Path: "/app/package-lock.json", Type: "lockfile"}
+
+	known := newBaseline(models.VulnerabilityResults{
+		Results: []models.PackageSource{{
+			Source: source,
+			Packages: []models.PackageVulns{
+				packageVulns("lodash", "4.17.20", []string{"GHSA-lodash", "CVE-2021-23337"}),
+				packageVulns("minimist", "1.2.5", []string{"GHSA-minimist"}),
+			},
+		}},
+	}, nil)
+
+	results := models.VulnerabilityResults{
+		Results: []models.PackageSource{
+			{
+				Source: source,
+				Packages: []models.PackageVulns{
+					// known through an alias, after a version bump
+					packageVulns("lodash", "4.17.21", []string{"OSV-lodash", "CVE-2021-23337"}, []string{"GHSA-lodash-new"}),
+					packageVulns("minimist", "1.2.5", []string{"GHSA-minimist"}),
+				},
+			},
+			{
+				// the same package in another source is not known
+				Source:   models.SourceInfo{Path: "/other/package-lock.json", Type: "lockfile"},
+				Packages: []models.PackageVulns{packageVulns("minimist", "1.2.5", []string{"GHSA-minimist"})},
+			},
+		},
+	}
+
+	if suppressed := known.suppress(&results, false); suppressed != 2 {
+		t.Errorf("expected 2 vulnerabilities to be suppressed, got %d", suppressed)
+	}
+
+	want := models.VulnerabilityResults{
+		Results: []models.PackageSource{
+			{
+				Source:   source,
+				Packages: []models.PackageVulns{packageVulns("lodash", "4.17.21", []string{"GHSA-lodash-new"})},
+			},
+			{
+				Source:   models.SourceInfo{Path: "/other/package-lock.json", Type: "lockfile"},
+				Packages: []models.PackageVulns{packageVulns("minimist", "1.2.5", []string{"GHSA-minimist"})},
+			},
+		},
+	}
+
+	if diff := cmp.Diff(want, results); diff != "" {
+		t.Errorf("suppress() returned unexpected results (-want +got):\n%s", diff)
+	}
+}
+
+func Test_baseline_suppress_MovedScanRoot(t *testing.T) {
+	t.Parallel()
+
+	// the baseline was written when scanning a checkout in another place
+	known := newBaseline(models.VulnerabilityResults{
+		Results: []models.PackageSource{{
+			Source:   models.SourceInfo{Path: "/ci/build/app/package-lock.json", Type: "lockfile"},
+			Packages: []models.PackageVulns{packageVulns("minimist", "1.2.5", []string{"GHSA-minimist"})},
+		}},
+	}, []string{"/ci/build/app"})
+
+	known.roots = []string{"/home/dev/app"}
+
+	results := models.VulnerabilityResults{
+		Results: []models.PackageSource{
+			{
+				Source:   models.SourceInfo{Path: "/home/dev/app/package-lock.json", Type: "lockfile"},
+				Packages: []models.PackageVulns{packageVulns("minimist", "1.2.5", []string{"GHSA-minimist"})},
+			},
+			{
+				Source:   models.SourceInfo{Path: "/home/dev/app/web/package-lock.json", Type: "lockfile"},
+				Packages: []models.PackageVulns{packageVulns("minimist", "1.2.5", []string{"GHSA-minimist"})},
+			},
+		},
+	}
+
+	if suppressed := known.suppress(&results, false); suppressed != 1 {
+		t.Errorf("expected 1 vulnerability to be suppressed, got %d", suppressed)
+	}
+
+	if len(results.Results) != 1 || results.Results[0].Source.Path != "/home/dev/app/web/package-lock.json" {
+		t.Errorf("expected only the vulnerability in web/package-lock.json to remain, got %+v", results.Results)
+	}
+}
+
+func Test_baselinePath(t *testing.T) {
+	t.Parallel()
+
+	roots := []string{"/app/services", "/app"}
+
+	tests := []struct {
+		source models.SourceInfo
+		want   string
+	}{
+		{source: models.SourceInfo{Path: "/app/services/api/go.mod"}, want: "api/go.mod"},
+		{source: models.SourceInfo{Path: "/app/package-lock.json"}, want: "package-lock.json"},
+		{source: models.SourceInfo{Path: "/application/package-lock.json"}, want: "/application/package-lock.json"},
+		{source: models.SourceInfo{Path: "web/package-lock.json", ScanPath: "/app"}, want: "web/package-lock.json"},
+	}
+
+	for _, tt := range tests {
+		if got := baselinePath(tt.source, roots); got != tt.want {
+			t.Errorf("baselinePath(%+v) = %q, want %q", tt.source, got, tt.want)
+		}
+	}
+}
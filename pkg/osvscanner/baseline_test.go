@@ -0,0 +1,83 @@
+package osvscanner_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/osv-scanner/pkg/osv"
+	"github.com/google/osv-scanner/pkg/osvscanner"
+)
+
+func TestScanner_Scan_Baseline(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	lockfilePath := filepath.Join(dir, "requirements.txt")
+	baselinePath := filepath.Join(dir, "baseline.json")
+
+	server := newFakeOSVServer(t, map[string]string{
+		"django@4.2.0":   "GHSA-django",
+		"django@4.2.1":   "GHSA-django",
+		"requests@2.0.0": "GHSA-requests",
+		"flask@2.0.0":    "GHSA-flask",
+	})
+
+	scanner := osvscanner.NewScanner(osvscanner.WithHTTPClient(server.Client()))
+
+	scan := func(requirements string, actions osvscanner.ScannerActions) ([]string, error) {
+		t.Helper()
+
+		if err := os.WriteFile(lockfilePath, []byte(requirements), 0600); err != nil {
+			t.Fatalf("could not write requirements.txt: %v", err)
+		}
+
+		actions.LockfilePaths = []string{lockfilePath}
+		actions.APIClientConfig = osv.ClientConfig{BaseURL: server.URL}
+
+		results, err := scanner.Scan(context.Background(), actions)
+
+		return vulnerabilityIDs(results), err
+	}
+
+	_, err := scan("django==4.2.0\nrequests==2.0.0\n", osvscanner.ScannerActions{
+		WriteBaselinePath: baselinePath,
+	})
+	if !errors.Is(err, osvscanner.VulnerabilitiesFoundErr) {
+		t.Fatalf("expected vulnerabilities to be found when writing the baseline, got %v", err)
+	}
+
+	// django has been bumped but is still affected by the same vulnerability
+	got, err := scan("django==4.2.1\nrequests==2.0.0\nflask==2.0.0\n", osvscanner.ScannerActions{
+		BaselinePath: baselinePath,
+	})
+	if !errors.Is(err, osvscanner.VulnerabilitiesFoundErr) {
+		t.Fatalf("expected new vulnerabilities to be found, got %v", err)
+	}
+
+	if diff := cmp.Diff([]string{lockfilePath + " flask GHSA-flask"}, got); diff != "" {
+		t.Errorf("Scan() returned unexpected vulnerabilities (-want +got):\n%s", diff)
+	}
+
+	// nothing is found if there are only known vulnerabilities
+	got, err = scan("django==4.2.1\n", osvscanner.ScannerActions{
+		BaselinePath: baselinePath,
+	})
+	if err != nil {
+		t.Errorf("expected no error when all vulnerabilities are in the baseline, got %v", err)
+	}
+
+	if len(got) != 0 {
+		t.Errorf("expected all vulnerabilities to be suppressed, got %v", got)
+	}
+
+	_, err = scan("django==4.2.1\n", osvscanner.ScannerActions{
+		BaselinePath: filepath.Join(dir, "does-not-exist.json"),
+	})
+	if err == nil || errors.Is(err, osvscanner.VulnerabilitiesFoundErr) {
+		t.Errorf("expected a missing baseline to be an error, got %v", err)
+	}
+}
@@ -0,0 +1,27 @@
+package osvscanner
+
+import (
+	"github.com/google/osv-scanner/internal/output"
+	"github.com/google/osv-scanner/pkg/config"
+	"github.com/google/osv-scanner/pkg/reporter"
+)
+
+// reportConfigIssues reports problems with the configs that were used by the
+// scan, such as ignores that expire soon or that did not match anything
+func reportConfigIssues(r reporter.Reporter, configManager *config.ConfigManager) {
+	issues := configManager.Lint(config.DefaultExpiringWithin)
+	if len(issues) == 0 {
+		return
+	}
+
+	r.Warnf("\nFound %d %s with the config:\n", len(issues), output.Form(len(issues), "issue", "issues"))
+
+	for _, issue := range issues {
+		location := issue.Path
+		if issue.Key != "" {
+			location += " " + issue.Key
+		}
+
+		r.Warnf("  %s (%s): %s\n", location, issue.Kind, issue.Message)
+	}
+}
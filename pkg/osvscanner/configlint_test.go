@@ -0,0 +1,50 @@
+package osvscanner_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/osv-scanner/pkg/config"
+	"github.com/google/osv-scanner/pkg/osv"
+	"github.com/google/osv-scanner/pkg/osvscanner"
+)
+
+func TestScanner_Scan_UnusedIgnores(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "osv-scanner.toml")
+
+	files := map[string]string{
+		"requirements.txt": "django==4.2.0\n",
+		"osv-scanner.toml": "[[IgnoredVulns]]\nid = \"GHSA-django\"\n\n[[IgnoredVulns]]\nid = \"GHSA-flask\"\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatalf("could not write %s: %v", name, err)
+		}
+	}
+
+	server := newFakeOSVServer(t, map[string]string{"django@4.2.0": "GHSA-django"})
+
+	configManager := &config.ConfigManager{ConfigMap: make(map[string]config.Config)}
+	scanner := osvscanner.NewScanner(osvscanner.WithHTTPClient(server.Client()), osvscanner.WithConfigManager(configManager))
+
+	_, err := scanner.Scan(context.Background(), osvscanner.ScannerActions{
+		DirectoryPaths:     []string{dir},
+		APIClientConfig:    osv.ClientConfig{BaseURL: server.URL},
+		ReportConfigIssues: true,
+	})
+	if err != nil && !errors.Is(err, osvscanner.VulnerabilitiesFoundErr) {
+		t.Fatalf("Scan() error = %v", err)
+	}
+
+	want := []config.LintIssue{{Path: configPath, Kind: config.LintUnused, Key: "IgnoredVulns[1]", Message: "GHSA-flask did not match any vulnerability"}}
+	if diff := cmp.Diff(want, configManager.UnusedIgnores()); diff != "" {
+		t.Errorf("UnusedIgnores() mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,141 @@
+package osvscanner
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/osv-scanner/internal/output"
+	"github.com/google/osv-scanner/internal/pathfilter"
+	"github.com/google/osv-scanner/internal/utility/severity"
+	"github.com/google/osv-scanner/pkg/config"
+	"github.com/google/osv-scanner/pkg/models"
+	"github.com/google/osv-scanner/pkg/reporter"
+)
+
+// cvssEnvironment is a CVSS environment from a config that is ready to be matched
+type cvssEnvironment struct {
+	config.CVSSEnvironmentEntry
+
+	env   severity.Environment
+	paths []pathfilter.Pattern
+	// config is the config that the environment is from, with index being where it is in it
+	config *config.Config
+	index  int
+}
+
+// compileCVSSEnvironments validates the CVSS environments of the config,
+// returning them in the order they are matched in
+func compileCVSSEnvironments(cfg config.Config) ([]cvssEnvironment, error) {
+	environments := make([]cvssEnvironment, 0, len(cfg.CVSSEnvironments))
+
+	for i, entry := range cfg.CVSSEnvironments {
+		invalid := func(format string, a ...any) error {
+			return fmt.Errorf("invalid CVSS environment %d in %s: %s", i+1, cfg.LoadPath, fmt.Sprintf(format, a...))
+		}
+
+		e := cvssEnvironment{
+			CVSSEnvironmentEntry: entry,
+			env:                  severity.Environment{CVSSV3: entry.CVSSV3, CVSSV4: entry.CVSSV4},
+			config:               &cfg,
+			index:                i,
+		}
+
+		if err := e.env.Validate(); err != nil {
+			return nil, invalid("%v", err)
+		}
+
+		for _, glob := range entry.Paths {
+			pattern, err := pathfilter.Compile(glob)
+			if err != nil {
+				return nil, invalid("%v", err)
+			}
+
+			e.paths = append(e.paths, pattern)
+		}
+
+		environments = append(environments, e)
+	}
+
+	return environments, nil
+}
+
+// matches reports if the package meets all of the conditions of the environment
+func (e cvssEnvironment) matches(source models.SourceInfo, pkg models.PackageVulns) bool {
+	if len(e.Ecosystems) > 0 {
+		ecosystem, _, _ := strings.Cut(pkg.Package.Ecosystem, ":")
+
+		if !slices.ContainsFunc(e.Ecosystems, func(s string) bool { return strings.EqualFold(s, ecosystem) }) {
+			return false
+		}
+	}
+
+	if len(e.paths) > 0 {
+		rel, ok := e.config.CVSSEnvironmentRelativePath(e.index, sourceFilePath(source))
+
+		if !ok || !slices.ContainsFunc(e.paths, func(pattern pathfilter.Pattern) bool { return pattern.Match(rel) }) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// rescoresGroup reports if the environment sets metrics for the CVSS version
+// of any of the severities of the vulnerabilities in the group
+func rescoresGroup(group models.GroupInfo, pkg models.PackageVulns, env severity.Environment) bool {
+	for _, vuln := range pkg.Vulnerabilities {
+		if !slices.Contains(group.IDs, vuln.ID) {
+			continue
+		}
+
+		if slices.ContainsFunc(vuln.Severity, env.Rescores) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// applyCVSSEnvironments scores the groups of vulnerabilities of each package
+// with the metrics of the first CVSS environment of the config for its
+// source that matches it, recording their environmental score if any of
+// their vectors are for a CVSS version that the environment has metrics for
+func applyCVSSEnvironments(r reporter.Reporter, results *models.VulnerabilityResults, configManager *config.ConfigManager) error {
+	compiled := make(map[string][]cvssEnvironment)
+
+	for _, source := range results.Results {
+		cfg := configManager.Get(r, source.Source.Path)
+
+		if len(cfg.CVSSEnvironments) == 0 {
+			continue
+		}
+
+		environments, ok := compiled[cfg.LoadPath]
+		if !ok {
+			var err error
+			if environments, err = compileCVSSEnvironments(cfg); err != nil {
+				return err
+			}
+
+			compiled[cfg.LoadPath] = environments
+		}
+
+		for _, pkg := range source.Packages {
+			i := slices.IndexFunc(environments, func(e cvssEnvironment) bool { return e.matches(source.Source, pkg) })
+			if i == -1 {
+				continue
+			}
+
+			for j, group := range pkg.Groups {
+				if !rescoresGroup(group, pkg, environments[i].env) {
+					continue
+				}
+
+				pkg.Groups[j].EnvironmentalSeverity = output.MaxEnvironmentalSeverity(group, pkg, environments[i].env)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,135 @@
+package osvscanner
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/osv-scanner/pkg/config"
+	"github.com/google/osv-scanner/pkg/models"
+	"github.com/google/osv-scanner/pkg/reporter"
+)
+
+// cvssEnvironmentTestSource returns a source with a critical vulnerability
+// for each of the packages
+func cvssEnvironmentTestSource(path string, pkgs ...models.PackageVulns) models.PackageSource {
+	for i := range pkgs {
+		id := "GHSA-" + pkgs[i].Package.Name
+
+		pkgs[i].Vulnerabilities = []models.Vulnerability{{
+			ID: id,
+			Severity: []models.Severity{
+				{Type: models.SeverityCVSSV3, Score: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"},
+			},
+		}}
+		pkgs[i].Groups = []models.GroupInfo{{IDs: []string{id}, Aliases: []string{id}, MaxSeverity: "9.8"}}
+	}
+
+	return models.PackageSource{
+		Source:   models.SourceInfo{Path: path, Type: "lockfile"},
+		Packages: pkgs,
+	}
+}
+
+func Test_compileCVSSEnvironments_Invalid(t *testing.T) {
+	t.Parallel()
+
+	for _, entry := range []config.CVSSEnvironmentEntry{
+		{CVSSV3: "AV:L"},
+		{CVSSV3: "MAV:Z"},
+		{CVSSV4: "MS:C"},
+		{CVSSV3: "MAV:A", Paths: []string{"services/["}},
+	} {
+		if _, err := compileCVSSEnvironments(config.Config{CVSSEnvironments: []config.CVSSEnvironmentEntry{entry}}); err == nil {
+			t.Errorf("expected %+v to be invalid", entry)
+		}
+	}
+}
+
+func Test_applyCVSSEnvironments(t *testing.T) {
+	t.Parallel()
+
+	configManager := &config.ConfigManager{
+		OverrideConfig: &config.Config{
+			LoadPath: "/app/osv-scanner.toml",
+			CVSSEnvironments: []config.CVSSEnvironmentEntry{
+				{Ecosystems: []string{"PyPI"}, CVSSV3: "MAV:L"},
+				{Paths: []string{"services/**"}, CVSSV3: "MAV:A/CR:L/IR:L"},
+			},
+		},
+	}
+
+	results := models.VulnerabilityResults{
+		Results: []models.PackageSource{
+			cvssEnvironmentTestSource("/app/services/api/package-lock.json", policyTestPackage("lodash", nil)),
+			cvssEnvironmentTestSource("/app/tools/package-lock.json", policyTestPackage("eslint", nil)),
+			cvssEnvironmentTestSource("/app/tools/requirements.txt", models.PackageVulns{
+				Package: models.PackageInfo{Name: "django", Version: "1.0.0", Ecosystem: "PyPI"},
+			}),
+		},
+	}
+
+	if err := applyCVSSEnvironments(&reporter.VoidReporter{}, &results, configManager); err != nil {
+		t.Fatalf("applyCVSSEnvironments() error = %v", err)
+	}
+
+	got := make(map[string]string)
+	for _, source := range results.Results {
+		for _, pkg := range source.Packages {
+			got[pkg.Package.Name] = pkg.Groups[0].EnvironmentalSeverity
+		}
+	}
+
+	// only the first environment that matches a package applies to it
+	want := map[string]string{"lodash": "7.8", "eslint": "", "django": "8.4"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("applyCVSSEnvironments() environmental severities mismatch (-want +got):\n%s", diff)
+	}
+
+	// the FailOn threshold is compared against the environmental score
+	api := models.VulnerabilityResults{Results: results.Results[:1]}
+	if err := resultsError(api, ScannerActions{FailOn: "critical"}); err != nil {
+		t.Errorf("resultsError() = %v, want the environmental score to be below the threshold", err)
+	}
+}
+
+func Test_applyCVSSEnvironments_NotRescored(t *testing.T) {
+	t.Parallel()
+
+	configManager := &config.ConfigManager{
+		OverrideConfig: &config.Config{
+			LoadPath: "/app/osv-scanner.toml",
+			CVSSEnvironments: []config.CVSSEnvironmentEntry{
+				{Ecosystems: []string{"PyPI"}, CVSSV3: "MAV:L"},
+				{CVSSV4: "MAV:L"},
+			},
+		},
+	}
+
+	v2 := cvssEnvironmentTestSource("/app/requirements.txt", models.PackageVulns{
+		Package: models.PackageInfo{Name: "django", Version: "1.0.0", Ecosystem: "PyPI"},
+	})
+	v2.Packages[0].Vulnerabilities[0].Severity = []models.Severity{
+		{Type: models.SeverityCVSSV2, Score: "AV:N/AC:L/Au:N/C:P/I:P/A:P"},
+	}
+
+	results := models.VulnerabilityResults{
+		Results: []models.PackageSource{
+			v2,
+			cvssEnvironmentTestSource("/app/package-lock.json", policyTestPackage("lodash", nil)),
+		},
+	}
+
+	if err := applyCVSSEnvironments(&reporter.VoidReporter{}, &results, configManager); err != nil {
+		t.Fatalf("applyCVSSEnvironments() error = %v", err)
+	}
+
+	// neither environment has metrics for the vectors of the packages, so
+	// there is no environmental score to show alongside their base score
+	for _, source := range results.Results {
+		for _, pkg := range source.Packages {
+			if got := pkg.Groups[0].EnvironmentalSeverity; got != "" {
+				t.Errorf("expected %s to not have an environmental severity, got %q", pkg.Package.Name, got)
+			}
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package osvscanner
+
+import (
+	"fmt"
+	"io/fs"
+	"strconv"
+
+	"github.com/google/osv-scanner/internal/utility/severity"
+	"github.com/google/osv-scanner/pkg/models"
+)
+
+const (
+	// UnknownSeverityFail fails the scan on called vulnerabilities that do
+	// not have a known severity, as they could be above the FailOn threshold
+	UnknownSeverityFail = "fail"
+	// UnknownSeverityIgnore only fails the scan on called vulnerabilities
+	// that are known to be at or above the FailOn threshold
+	UnknownSeverityIgnore = "ignore"
+)
+
+// failurePolicy determines which called vulnerabilities fail the scan
+type failurePolicy struct {
+	// threshold is the lowest score that fails the scan, which every
+	// vulnerability does if it is negative
+	threshold     float64
+	ignoreUnknown bool
+}
+
+func newFailurePolicy(actions ScannerActions) failurePolicy {
+	threshold := -1.0
+
+	// invalid thresholds are rejected before scanning, so cannot get this far
+	if score, err := severity.ParseThreshold(actions.FailOn); actions.FailOn != "" && err == nil {
+		threshold = score
+	}
+
+	return failurePolicy{
+		threshold:     threshold,
+		ignoreUnknown: actions.UnknownSeverity == UnknownSeverityIgnore,
+	}
+}
+
+// fails reports if the group of vulnerabilities should fail the scan, based
+// on the decision of the policy that matched it if any, and otherwise on its
+// highest severity (or environmental score, if it has one) which is empty if
+// none of them have a known one
+func (p failurePolicy) fails(group models.GroupInfo) bool {
+	if group.Policy != nil {
+		return group.Policy.Action == models.PolicyActionFail
+	}
+
+	if p.threshold < 0 {
+		return true
+	}
+
+	score, err := strconv.ParseFloat(group.Severity(), 64)
+	if err != nil {
+		return !p.ignoreUnknown
+	}
+
+	return score >= p.threshold
+}
+
+// withFailOnConfig fills in the failure thresholds that are not set by the
+// actions from the config, returning an error if they are not valid
+func (s *Scanner) withFailOnConfig(actions ScannerActions, fsys fs.FS) (ScannerActions, error) {
+	configManager, err := s.newConfigManager(actions, fsys)
+	if err != nil {
+		s.reporter.Errorf("Failed to read config file: %s\n", err)
+		return actions, err
+	}
+
+	failOn, loadPath := configManager.GetFailOnConfig(".")
+	if actions.FailOn == "" && failOn.Severity != "" {
+		s.reporter.Verbosef("Using fail-on threshold from: %s\n", loadPath)
+		actions.FailOn = failOn.Severity
+	}
+	if actions.UnknownSeverity == "" {
+		actions.UnknownSeverity = failOn.UnknownSeverity
+	}
+
+	if actions.FailOn != "" {
+		if _, err := severity.ParseThreshold(actions.FailOn); err != nil {
+			return actions, err
+		}
+	}
+
+	switch actions.UnknownSeverity {
+	case "", UnknownSeverityFail, UnknownSeverityIgnore:
+	default:
+		return actions, fmt.Errorf("invalid unknown severity handling %q - must be one of %s, %s", actions.UnknownSeverity, UnknownSeverityFail, UnknownSeverityIgnore)
+	}
+
+	return actions, nil
+}
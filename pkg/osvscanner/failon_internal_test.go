@@ -0,0 +1,131 @@
+package osvscanner
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/osv-scanner/pkg/models"
+)
+
+func resultsWithSeverities(severities ...string) models.VulnerabilityResults {
+	pkg := models.PackageVulns{Package: models.PackageInfo{Name: "lodash", Version: "4.17.20", Ecosystem: "npm"}}
+
+	for i, maxSeverity := range severities {
+		id := "GHSA-" + string(rune('a'+i))
+
+		pkg.Vulnerabilities = append(pkg.Vulnerabilities, models.Vulnerability{ID: id})
+		pkg.Groups = append(pkg.Groups, models.GroupInfo{IDs: []string{id}, MaxSeverity: maxSeverity})
+	}
+
+	return models.VulnerabilityResults{
+		Results: []models.PackageSource{{
+			Source:   models.SourceInfo{Path: "/app/package-lock.json", Type: "lockfile"},
+			Packages: []models.PackageVulns{pkg},
+		}},
+	}
+}
+
+func Test_resultsError_FailOn(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		severities []string
+		actions    ScannerActions
+		wantFail   bool
+	}{
+		{
+			name:       "no threshold",
+			severities: []string{"2.0"},
+			wantFail:   true,
+		},
+		{
+			name:       "below threshold",
+			severities: []string{"2.0", "6.9"},
+			actions:    ScannerActions{FailOn: "high"},
+			wantFail:   false,
+		},
+		{
+			name:       "at threshold",
+			severities: []string{"2.0", "7.0"},
+			actions:    ScannerActions{FailOn: "high"},
+			wantFail:   true,
+		},
+		{
+			name:       "score threshold",
+			severities: []string{"7.5"},
+			actions:    ScannerActions{FailOn: "8"},
+			wantFail:   false,
+		},
+		{
+			name:       "unknown severity fails by default",
+			severities: []string{"2.0", ""},
+			actions:    ScannerActions{FailOn: "critical"},
+			wantFail:   true,
+		},
+		{
+			name:       "unknown severity explicitly fails",
+			severities: []string{""},
+			actions:    ScannerActions{FailOn: "critical", UnknownSeverity: UnknownSeverityFail},
+			wantFail:   true,
+		},
+		{
+			name:       "unknown severity ignored",
+			severities: []string{"2.0", ""},
+			actions:    ScannerActions{FailOn: "critical", UnknownSeverity: UnknownSeverityIgnore},
+			wantFail:   false,
+		},
+		{
+			name:       "unknown severity without a threshold",
+			severities: []string{""},
+			actions:    ScannerActions{UnknownSeverity: UnknownSeverityIgnore},
+			wantFail:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := resultsError(resultsWithSeverities(tt.severities...), tt.actions)
+
+			if got := errors.Is(err, VulnerabilitiesFoundErr); got != tt.wantFail {
+				t.Errorf("resultsError() = %v, want failure: %v", err, tt.wantFail)
+			}
+		})
+	}
+}
+
+func Test_withFailOnConfig(t *testing.T) {
+	t.Parallel()
+
+	configPath := filepath.Join(t.TempDir(), "osv-scanner.toml")
+	config := "[FailOn]\nseverity = \"medium\"\nunknownSeverity = \"ignore\"\n"
+
+	if err := os.WriteFile(configPath, []byte(config), 0600); err != nil {
+		t.Fatalf("could not write config: %v", err)
+	}
+
+	s := NewScanner()
+
+	// the config is used for anything that is not set by the actions
+	actions, err := s.withFailOnConfig(ScannerActions{ConfigOverridePath: configPath, FailOn: "9.5"}, nil)
+	if err != nil {
+		t.Fatalf("withFailOnConfig() error = %v", err)
+	}
+
+	if actions.FailOn != "9.5" || actions.UnknownSeverity != UnknownSeverityIgnore {
+		t.Errorf("withFailOnConfig() = %q, %q, want %q, %q", actions.FailOn, actions.UnknownSeverity, "9.5", UnknownSeverityIgnore)
+	}
+
+	for _, actions := range []ScannerActions{
+		{FailOn: "severe"},
+		{FailOn: "11"},
+		{FailOn: "high", UnknownSeverity: "maybe"},
+	} {
+		if _, err := s.withFailOnConfig(actions, nil); err == nil {
+			t.Errorf("expected %q and %q to be invalid", actions.FailOn, actions.UnknownSeverity)
+		}
+	}
+}
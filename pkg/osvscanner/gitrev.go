@@ -0,0 +1,255 @@
+package osvscanner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/osv-scanner/internal/ci"
+	"github.com/google/osv-scanner/internal/gitfs"
+	"github.com/google/osv-scanner/internal/output"
+	"github.com/google/osv-scanner/pkg/models"
+)
+
+// gitRevisionActions opens the git repository containing the directories and
+// lockfiles of the given actions, returning the actions to scan them within
+// the repository with their paths made relative to the root of it
+func gitRevisionActions(actions ScannerActions) (*gitfs.Repository, ScannerActions, error) {
+	if actions.ScanOCIImage != "" || len(actions.DockerContainerNames) > 0 || len(actions.SBOMPaths) > 0 {
+		return nil, actions, errors.New("only lockfiles and directories can be scanned at a git revision")
+	}
+
+	if len(actions.DirectoryPaths) == 0 && len(actions.LockfilePaths) == 0 {
+		actions.DirectoryPaths = []string{"."}
+	}
+
+	repoDir := "."
+	if len(actions.DirectoryPaths) > 0 {
+		repoDir = actions.DirectoryPaths[0]
+	}
+
+	repo, err := gitfs.OpenRepository(repoDir)
+	if err != nil {
+		return nil, actions, err
+	}
+
+	dirs := make([]string, 0, len(actions.DirectoryPaths))
+	for _, dir := range actions.DirectoryPaths {
+		rel, err := repo.Rel(dir)
+		if err != nil {
+			return nil, actions, err
+		}
+
+		dirs = append(dirs, "/"+rel)
+	}
+
+	lockfiles := make([]string, 0, len(actions.LockfilePaths))
+	for _, lockfileElem := range actions.LockfilePaths {
+		parseAs, lockfilePath := parseLockfilePath(lockfileElem)
+
+		if lockfilePath != stdinLockfilePath {
+			rel, err := repo.Rel(lockfilePath)
+			if err != nil {
+				return nil, actions, err
+			}
+
+			lockfilePath = "/" + rel
+		}
+
+		lockfiles = append(lockfiles, parseAs+":"+lockfilePath)
+	}
+
+	actions.DirectoryPaths = dirs
+	actions.LockfilePaths = lockfiles
+	actions.GitRev = ""
+	actions.GitDiff = ""
+
+	return repo, actions, nil
+}
+
+// scanGitRevisions scans the lockfiles and directories of the actions as they
+// were at a git revision, or compares them between two revisions
+func (s *Scanner) scanGitRevisions(ctx context.Context, actions ScannerActions) (models.VulnerabilityResults, error) {
+	if actions.GitRev != "" && actions.GitDiff != "" {
+		return models.VulnerabilityResults{}, errors.New("a git revision and a git diff cannot both be scanned")
+	}
+
+	gitRev, gitDiff := actions.GitRev, actions.GitDiff
+
+	repo, actions, err := gitRevisionActions(actions)
+	if err != nil {
+		return models.VulnerabilityResults{}, err
+	}
+
+	scanRevision := func(rev string) (models.VulnerabilityResults, error) {
+		fsys, err := repo.Revision(rev)
+		if err != nil {
+			return models.VulnerabilityResults{}, err
+		}
+
+		s.reporter.Infof("Scanning %s at git revision %s\n", repo.Root, rev)
+
+		return s.scan(ctx, actions, fsys)
+	}
+
+	if gitDiff == "" {
+		return scanRevision(gitRev)
+	}
+
+	base, head, found := strings.Cut(gitDiff, "..")
+	if !found || base == "" || strings.HasPrefix(head, ".") {
+		return models.VulnerabilityResults{}, fmt.Errorf("git diff must be in the form <base>..<head>, got %q", gitDiff)
+	}
+
+	// like git, an empty head means the current commit
+	if head == "" {
+		head = "HEAD"
+	}
+
+	// a base without any packages is fine, as everything at head is new
+	baseResults, err := scanRevision(base)
+	if err != nil && !errors.Is(err, NoPackagesFoundErr) && !errors.Is(err, VulnerabilitiesFoundErr) {
+		return models.VulnerabilityResults{}, err
+	}
+
+	headResults, err := scanRevision(head)
+	if err != nil && !errors.Is(err, VulnerabilitiesFoundErr) {
+		return models.VulnerabilityResults{}, err
+	}
+
+	// vulnerabilities are compared regardless of the versions of the packages
+	// they affect, so upgrading to a version that is still affected is not new
+	diff := ci.CompareVulnerabilityResults(baseResults, headResults)
+
+	var added []ci.PackageDiff
+	for _, pkg := range diff.Packages {
+		if pkg.Change == ci.PackageAdded {
+			added = append(added, pkg)
+		}
+	}
+
+	results := models.VulnerabilityResults{
+		Results:                    diffedPackages(headResults, diff.NewVulnerabilities, added),
+		Resolved:                   diffedPackages(baseResults, diff.ResolvedVulnerabilities, nil),
+		ExperimentalAnalysisConfig: headResults.ExperimentalAnalysisConfig,
+	}
+
+	resolved := models.VulnerabilityResults{Results: results.Resolved}
+
+	for _, vf := range resolved.Flatten() {
+		if vf.Vulnerability.ID == "" {
+			continue
+		}
+
+		s.reporter.Infof("Resolved %s in %s@%s (%s)\n", vf.Vulnerability.ID, vf.Package.Name, vf.Package.Version, vf.Source.Path)
+	}
+
+	introducedCount := countVulnerabilities(results)
+	resolvedCount := countVulnerabilities(resolved)
+
+	s.reporter.Infof(
+		"Between %s and %s, %d %s introduced and %d %s resolved\n",
+		base,
+		head,
+		introducedCount,
+		output.Form(introducedCount, "vulnerability was", "vulnerabilities were"),
+		resolvedCount,
+		output.Form(resolvedCount, "was", "were"),
+	)
+
+	return results, resultsError(results, actions)
+}
+
+// diffedPackageKey identifies a version of a package within a source, in the
+// same way as the packages of a ci.ResultsDiff
+type diffedPackageKey struct {
+	source    string
+	ecosystem string
+	name      string
+	version   string
+}
+
+func newDiffedPackageKey(source models.SourceInfo, pkg models.PackageInfo) diffedPackageKey {
+	version := pkg.Version
+	if version == "" {
+		version = pkg.Commit
+	}
+
+	return diffedPackageKey{source.Path, pkg.Ecosystem, pkg.Name, version}
+}
+
+// diffedPackages returns the packages of the results with only the given
+// groups of vulnerabilities, along with the license violations of the
+// packages that were added
+func diffedPackages(results models.VulnerabilityResults, vulns []ci.VulnerabilityDiff, added []ci.PackageDiff) []models.PackageSource {
+	groups := make(map[diffedPackageKey][][]string)
+	for _, vd := range vulns {
+		key := diffedPackageKey{vd.Source, vd.Ecosystem, vd.Package, vd.Version}
+		groups[key] = append(groups[key], vd.IDs)
+	}
+
+	addedPackages := make(map[diffedPackageKey]bool)
+	for _, pd := range added {
+		addedPackages[diffedPackageKey{pd.Source, pd.Ecosystem, pd.Package, pd.NewVersion}] = true
+	}
+
+	var sources []models.PackageSource
+
+	for _, source := range results.Results {
+		var packages []models.PackageVulns
+
+		for _, pkg := range source.Packages {
+			key := newDiffedPackageKey(source.Source, pkg.Package)
+
+			diffed := models.PackageVulns{
+				Package:   pkg.Package,
+				DepGroups: pkg.DepGroups,
+				Licenses:  pkg.Licenses,
+			}
+
+			if addedPackages[key] {
+				diffed.LicenseViolations = pkg.LicenseViolations
+			}
+
+			for _, group := range pkg.Groups {
+				if !slices.ContainsFunc(groups[key], func(ids []string) bool { return slices.Equal(ids, group.IDs) }) {
+					continue
+				}
+
+				diffed.Groups = append(diffed.Groups, group)
+
+				for _, vuln := range pkg.Vulnerabilities {
+					if slices.Contains(group.IDs, vuln.ID) {
+						diffed.Vulnerabilities = append(diffed.Vulnerabilities, vuln)
+					}
+				}
+			}
+
+			if len(diffed.Groups) > 0 || len(diffed.LicenseViolations) > 0 {
+				packages = append(packages, diffed)
+			}
+		}
+
+		if len(packages) > 0 {
+			sources = append(sources, models.PackageSource{Source: source.Source, Packages: packages})
+		}
+	}
+
+	return sources
+}
+
+// countVulnerabilities returns how many vulnerabilities affect the packages
+// of the results, counting each vulnerability once per package
+func countVulnerabilities(results models.VulnerabilityResults) int {
+	count := 0
+
+	for _, vf := range results.Flatten() {
+		if vf.Vulnerability.ID != "" {
+			count++
+		}
+	}
+
+	return count
+}
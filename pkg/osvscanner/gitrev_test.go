@@ -0,0 +1,206 @@
+package osvscanner_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/osv-scanner/pkg/models"
+	"github.com/google/osv-scanner/pkg/osv"
+	"github.com/google/osv-scanner/pkg/osvscanner"
+)
+
+// newFakeOSVServer returns a server that responds like the OSV API, with the
+// given vulnerabilities affecting "<name>@<version>" of PyPI packages
+func newFakeOSVServer(t *testing.T, vulns map[string]string) *httptest.Server {
+	t.Helper()
+
+	server, _ := newRecordingOSVServer(t, vulns)
+
+	return server
+}
+
+// newRecordingOSVServer returns a server like newFakeOSVServer, along with
+// a function that returns the "<name>@<version>" of every package that has
+// been queried so far
+func newRecordingOSVServer(t *testing.T, vulns map[string]string) (*httptest.Server, func() []string) {
+	t.Helper()
+
+	var mu sync.Mutex
+	var queried []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if id, ok := strings.CutPrefix(r.URL.Path, "/v1/vulns/"); ok {
+			_ = json.NewEncoder(w).Encode(models.Vulnerability{ID: id})
+
+			return
+		}
+
+		var query osv.BatchedQuery
+		if err := json.NewDecoder(r.Body).Decode(&query); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+
+			return
+		}
+
+		response := osv.BatchedResponse{Results: make([]osv.MinimalResponse, len(query.Queries))}
+		for i, q := range query.Queries {
+			mu.Lock()
+			queried = append(queried, q.Package.Name+"@"+q.Version)
+			mu.Unlock()
+
+			if id, ok := vulns[q.Package.Name+"@"+q.Version]; ok {
+				response.Results[i].Vulns = []osv.MinimalVulnerability{{ID: id}}
+			}
+		}
+
+		_ = json.NewEncoder(w).Encode(response)
+	}))
+	t.Cleanup(server.Close)
+
+	return server, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+
+		return slices.Clone(queried)
+	}
+}
+
+// commitRequirements commits the given requirements.txt to the repository
+func commitRequirements(t *testing.T, repo *git.Repository, dir string, content string) {
+	t.Helper()
+
+	if err := os.WriteFile(filepath.Join(dir, "requirements.txt"), []byte(content), 0600); err != nil {
+		t.Fatalf("could not write requirements.txt: %v", err)
+	}
+
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("could not get worktree: %v", err)
+	}
+
+	if _, err := wt.Add("requirements.txt"); err != nil {
+		t.Fatalf("could not add requirements.txt: %v", err)
+	}
+
+	_, err = wt.Commit("update requirements", &git.CommitOptions{
+		Author: &object.Signature{Name: "osv-scanner", Email: "osv-scanner@example.com", When: time.Now()},
+	})
+	if err != nil {
+		t.Fatalf("could not commit: %v", err)
+	}
+}
+
+func vulnerabilityIDs(results models.VulnerabilityResults) []string {
+	var ids []string
+	for _, vf := range results.Flatten() {
+		ids = append(ids, vf.Source.Path+" "+vf.Package.Name+" "+vf.Vulnerability.ID)
+	}
+	slices.Sort(ids)
+
+	return ids
+}
+
+func TestScanner_Scan_GitRevisions(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatalf("could not create repository: %v", err)
+	}
+
+	commitRequirements(t, repo, dir, "django==4.2.0\nrequests==2.0.0\n")
+	// upgrading django to a version that is still affected does not introduce its vulnerability
+	commitRequirements(t, repo, dir, "django==4.2.1\nflask==2.0.0\n")
+
+	// uncommitted changes are not scanned
+	if err := os.WriteFile(filepath.Join(dir, "requirements.txt"), []byte("jinja2==2.0.0\n"), 0600); err != nil {
+		t.Fatalf("could not write requirements.txt: %v", err)
+	}
+
+	server := newFakeOSVServer(t, map[string]string{
+		"django@4.2.0":   "GHSA-django",
+		"django@4.2.1":   "GHSA-django",
+		"requests@2.0.0": "GHSA-requests",
+		"flask@2.0.0":    "GHSA-flask",
+		"jinja2@2.0.0":   "GHSA-jinja2",
+	})
+
+	scanner := osvscanner.NewScanner(osvscanner.WithHTTPClient(server.Client()))
+
+	results, err := scanner.Scan(context.Background(), osvscanner.ScannerActions{
+		DirectoryPaths:  []string{dir},
+		GitRev:          "HEAD~1",
+		APIClientConfig: osv.ClientConfig{BaseURL: server.URL},
+	})
+	if !errors.Is(err, osvscanner.VulnerabilitiesFoundErr) {
+		t.Fatalf("expected vulnerabilities to be found, got %v", err)
+	}
+
+	want := []string{
+		"/requirements.txt django GHSA-django",
+		"/requirements.txt requests GHSA-requests",
+	}
+
+	if diff := cmp.Diff(want, vulnerabilityIDs(results)); diff != "" {
+		t.Errorf("Scan() returned unexpected vulnerabilities at HEAD~1 (-want +got):\n%s", diff)
+	}
+
+	// only the vulnerabilities introduced by head are returned
+	results, err = scanner.Scan(context.Background(), osvscanner.ScannerActions{
+		DirectoryPaths:  []string{dir},
+		GitDiff:         "HEAD~1..HEAD",
+		APIClientConfig: osv.ClientConfig{BaseURL: server.URL},
+	})
+	if !errors.Is(err, osvscanner.VulnerabilitiesFoundErr) {
+		t.Fatalf("expected vulnerabilities to be found, got %v", err)
+	}
+
+	if diff := cmp.Diff([]string{"/requirements.txt flask GHSA-flask"}, vulnerabilityIDs(results)); diff != "" {
+		t.Errorf("Scan() returned unexpected vulnerabilities between HEAD~1 and HEAD (-want +got):\n%s", diff)
+	}
+
+	// along with those that were resolved
+	resolved := models.VulnerabilityResults{Results: results.Resolved}
+	if diff := cmp.Diff([]string{"/requirements.txt requests GHSA-requests"}, vulnerabilityIDs(resolved)); diff != "" {
+		t.Errorf("Scan() returned unexpected resolved vulnerabilities between HEAD~1 and HEAD (-want +got):\n%s", diff)
+	}
+
+	// resolved vulnerabilities are introduced again when going backwards
+	results, err = scanner.Scan(context.Background(), osvscanner.ScannerActions{
+		DirectoryPaths:  []string{dir},
+		GitDiff:         "HEAD..HEAD~1",
+		APIClientConfig: osv.ClientConfig{BaseURL: server.URL},
+	})
+	if !errors.Is(err, osvscanner.VulnerabilitiesFoundErr) {
+		t.Fatalf("expected vulnerabilities to be found, got %v", err)
+	}
+
+	if diff := cmp.Diff([]string{"/requirements.txt requests GHSA-requests"}, vulnerabilityIDs(results)); diff != "" {
+		t.Errorf("Scan() returned unexpected vulnerabilities between HEAD and HEAD~1 (-want +got):\n%s", diff)
+	}
+
+	for _, gitDiff := range []string{"HEAD~1", "..HEAD", "HEAD~1...HEAD"} {
+		_, err := scanner.Scan(context.Background(), osvscanner.ScannerActions{
+			DirectoryPaths: []string{dir},
+			GitDiff:        gitDiff,
+		})
+		if err == nil || errors.Is(err, osvscanner.VulnerabilitiesFoundErr) {
+			t.Errorf("expected git diff %q to be invalid, got %v", gitDiff, err)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package osvscanner
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"unicode/utf8"
+
+	"github.com/google/osv-scanner/pkg/models"
+)
+
+// noticeFilePattern matches the names of the files (and directories) that
+// packages usually give their license and copyright notices in, such as
+// "LICENSE", "COPYING.txt" and "NOTICE-APACHE"
+var noticeFilePattern = regexp.MustCompile(`(?i)^(licen[cs]es?|copying|copyright|notices?|unlicense)([-._].*)?$`)
+
+// maxNoticeSize is the size of the largest file that is read as a notice,
+// as larger files are unlikely to be one
+const maxNoticeSize = 256 * 1024
+
+// readLicenseNotices reads the license and copyright notices of the package
+// that is in the given directory, including all of the files in directories
+// like "licenses" that packages such as Python wheels put them in
+func readLicenseNotices(dir string) []models.LicenseNotice {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	var notices []models.LicenseNotice
+	for _, entry := range entries {
+		if !noticeFilePattern.MatchString(entry.Name()) {
+			continue
+		}
+
+		if !entry.IsDir() {
+			if notice, ok := readLicenseNotice(dir, entry.Name()); ok {
+				notices = append(notices, notice)
+			}
+
+			continue
+		}
+
+		_ = filepath.WalkDir(filepath.Join(dir, entry.Name()), func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return nil
+			}
+
+			rel, err := filepath.Rel(dir, path)
+			if err != nil {
+				return nil
+			}
+
+			if notice, ok := readLicenseNotice(dir, rel); ok {
+				notices = append(notices, notice)
+			}
+
+			return nil
+		})
+	}
+
+	return notices
+}
+
+// readLicenseNotice reads the notice at the given path within the directory
+// of a package, if it is a regular file of text
+func readLicenseNotice(dir string, rel string) (models.LicenseNotice, bool) {
+	path := filepath.Join(dir, rel)
+
+	info, err := os.Lstat(path)
+	if err != nil || !info.Mode().IsRegular() || info.Size() > maxNoticeSize {
+		return models.LicenseNotice{}, false
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil || !utf8.Valid(content) {
+		return models.LicenseNotice{}, false
+	}
+
+	return models.LicenseNotice{Path: filepath.ToSlash(rel), Text: string(content)}, true
+}
@@ -0,0 +1,33 @@
+package osvscanner
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/osv-scanner/pkg/models"
+)
+
+func Test_readLicenseNotices(t *testing.T) {
+	t.Parallel()
+
+	got := readLicenseNotices("fixtures/notices")
+
+	// binary files and files in other directories are not notices
+	want := []models.LicenseNotice{
+		{Path: "LICENSE", Text: "MIT License\n\nCopyright (c) 2024 Example Authors\n"},
+		{Path: "NOTICE.md", Text: "This product includes software developed by Example.\n"},
+		{Path: "licenses/vendored/LICENSE-BSD", Text: "BSD 3-Clause License\n"},
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("readLicenseNotices() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func Test_readLicenseNotices_MissingDir(t *testing.T) {
+	t.Parallel()
+
+	if got := readLicenseNotices("fixtures/does-not-exist"); got != nil {
+		t.Errorf("readLicenseNotices() = %v, want nil", got)
+	}
+}
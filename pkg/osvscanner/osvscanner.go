@@ -2,29 +2,38 @@ package osvscanner
 
 import (
 	"bufio"
+	"context"
 	"errors"
 	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
 	"os"
 	"os/exec"
 	"path"
 	"path/filepath"
+	"reflect"
+	"slices"
 	"strings"
+	"time"
 
+	"github.com/google/osv-scanner/internal/archive"
 	"github.com/google/osv-scanner/internal/customgitignore"
 	"github.com/google/osv-scanner/internal/image"
 	"github.com/google/osv-scanner/internal/utility/fileposition"
 
 	"github.com/google/osv-scanner/internal/local"
 	"github.com/google/osv-scanner/internal/output"
+	"github.com/google/osv-scanner/internal/pathfilter"
 	"github.com/google/osv-scanner/internal/sbom"
 	"github.com/google/osv-scanner/internal/semantic"
-	"github.com/google/osv-scanner/internal/version"
 	"github.com/google/osv-scanner/pkg/config"
 	"github.com/google/osv-scanner/pkg/depsdev"
 	"github.com/google/osv-scanner/pkg/lockfile"
 	"github.com/google/osv-scanner/pkg/models"
 	"github.com/google/osv-scanner/pkg/osv"
 	"github.com/google/osv-scanner/pkg/reporter"
+	"github.com/google/osv-scanner/pkg/spdx"
 
 	depsdevpb "deps.dev/api/v3"
 	"github.com/go-git/go-git/v5"
@@ -32,13 +41,15 @@ import (
 )
 
 type ScannerActions struct {
-	LockfilePaths          []string
-	SBOMPaths              []string
-	DirectoryPaths         []string
-	GitCommits             []string
-	Recursive              bool
-	SkipGit                bool
-	NoIgnore               bool
+	LockfilePaths  []string
+	SBOMPaths      []string
+	DirectoryPaths []string
+	GitCommits     []string
+	Recursive      bool
+	SkipGit        bool
+	NoIgnore       bool
+	// Deprecated: this is no longer applied as it required modifying the
+	// environment of the process, set the "debug" environment variable instead
 	Debug                  bool
 	DockerContainerNames   []string
 	ConfigOverridePath     string
@@ -46,6 +57,53 @@ type ScannerActions struct {
 	ConsiderScanPathAsRoot bool
 	PathRelativeToScanDir  bool
 	EnableParsers          []string
+	// Parallelism is how many files are scanned at once when scanning
+	// directories, defaulting to the number of CPUs
+	Parallelism int
+	// IncludePaths and ExcludePaths are globs of the paths to scan within
+	// directories, optionally prefixed with the parser they are limited to
+	// (i.e. "requirements.txt:docs/**")
+	IncludePaths []string
+	ExcludePaths []string
+	// APIClientConfig configures how the OSV API is accessed, with any values
+	// that are set taking precedence over the [API] section of the config file
+	APIClientConfig osv.ClientConfig
+	// Stdin is read from for lockfile paths of "-" (which must also specify
+	// the parser to use, i.e. "requirements.txt:-"), defaulting to os.Stdin
+	Stdin io.Reader
+	// StdinSourceDir is the directory that the source files of a lockfile
+	// read from stdin are opened from (such as the package.json of a
+	// package-lock.json), with matching being skipped if it is empty
+	StdinSourceDir string
+	// GitRev scans the directories and lockfiles as they were at the given
+	// git revision, reading them directly from the repository that contains
+	// them rather than from the worktree
+	GitRev string
+	// GitDiff scans the directories and lockfiles at both revisions of a
+	// "<base>..<head>" range like GitRev, returning only the vulnerabilities
+	// that were introduced by head
+	GitDiff string
+	// BaselinePath is the path of the JSON results of a previous scan, with
+	// any vulnerabilities that were already present in it being suppressed,
+	// even if the affected package has since changed version
+	BaselinePath string
+	// WriteBaselinePath is the path to write the JSON results of the scan to,
+	// before any are suppressed, for use as the baseline of future scans
+	WriteBaselinePath string
+	// FailOn is the lowest severity of the called vulnerabilities that cause
+	// VulnerabilitiesFoundErr to be returned, as either a rating (i.e. "high")
+	// or a CVSS score (i.e. "7.0"), with vulnerabilities of a lower severity
+	// still being reported; the [FailOn] section of the config is used if unset
+	FailOn string
+	// UnknownSeverity is whether called vulnerabilities without a known
+	// severity cause VulnerabilitiesFoundErr to be returned when there is a
+	// FailOn threshold, either UnknownSeverityFail (the default) or
+	// UnknownSeverityIgnore
+	UnknownSeverity string
+	// ReportConfigIssues reports problems with the config files that were
+	// used once the scan is complete, such as ignores that did not match
+	// any vulnerability or that expire soon
+	ReportConfigIssues bool
 
 	ExperimentalScannerActions
 }
@@ -57,9 +115,92 @@ type ExperimentalScannerActions struct {
 	ScanLicensesSummary   bool
 	OnlyPackages          bool
 	ScanLicensesAllowlist []string
-	ScanOCIImage          string
+	// ScanLicensesDenylist are licenses that are violations, even if they
+	// are in ScanLicensesAllowlist
+	ScanLicensesDenylist []string
+	// ScanLicenseNotices reads the license and copyright notices of packages
+	// from the directories that they are installed or vendored in, for
+	// attributing them
+	ScanLicenseNotices bool
+	ScanOCIImage       string
 
 	LocalDBPath string
+
+	// UseAPICache enables caching responses from the OSV API on disk,
+	// in APICacheDir if set or osv.DefaultCacheDir otherwise
+	UseAPICache bool
+	APICacheDir string
+	// APICacheTTL is how long query results are cached for, defaulting to osv.DefaultCacheQueryTTL
+	APICacheTTL time.Duration
+
+	// UseExtractionCache enables caching extracted lockfiles on disk so that
+	// unchanged lockfiles are not extracted again, in ExtractionCacheDir if
+	// set or lockfile.DefaultCacheDir otherwise
+	UseExtractionCache bool
+	ExtractionCacheDir string
+
+	// ScanArchives enables scanning the lockfiles within archives (such as
+	// .zip, .tar.gz and .whl files) that are found in directories
+	ScanArchives bool
+}
+
+// hasLicensePolicy reports if the licenses of packages are checked against
+// an allowlist or denylist
+func (actions ExperimentalScannerActions) hasLicensePolicy() bool {
+	return len(actions.ScanLicensesAllowlist) > 0 || len(actions.ScanLicensesDenylist) > 0
+}
+
+// scansLicenses reports if the licenses of packages have to be retrieved
+func (actions ExperimentalScannerActions) scansLicenses() bool {
+	return actions.hasLicensePolicy() || actions.ScanLicensesSummary || actions.ScanLicenseNotices
+}
+
+// licensePolicy returns the policy that the licenses of packages from a
+// source with the given config are checked against, if there is one, which
+// is that of the flags if they set one, and otherwise that of the config
+func (actions ExperimentalScannerActions) licensePolicy(cfg config.Config) (spdx.Policy, bool) {
+	if actions.hasLicensePolicy() {
+		return spdx.Policy{Allow: actions.ScanLicensesAllowlist, Deny: actions.ScanLicensesDenylist}, true
+	}
+
+	if cfg.Licenses.HasPolicy() {
+		return spdx.Policy{Allow: cfg.Licenses.Allow, Deny: cfg.Licenses.Deny}, true
+	}
+
+	return spdx.Policy{}, false
+}
+
+// checksLicenses reports if the licenses of any of the packages have to be
+// retrieved, either because of the flags or the configs of their sources
+func (actions ExperimentalScannerActions) checksLicenses(r reporter.Reporter, configManager *config.ConfigManager, packages []scannedPackage) bool {
+	if actions.scansLicenses() {
+		return true
+	}
+
+	checked := make(map[string]bool)
+	for _, pkg := range packages {
+		if checked[pkg.Source.Path] {
+			continue
+		}
+		checked[pkg.Source.Path] = true
+
+		if configManager.Get(r, pkg.Source.Path).Licenses.HasPolicy() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// addCachedLicenses sets the licenses of the packages that do not declare any
+// in the scanned files to those they declare in the package caches, such as
+// the .nuspec files of NuGet packages and the POMs of Maven packages
+func addCachedLicenses(packages []scannedPackage, caches lockfile.PackageCaches) {
+	for i, pkg := range packages {
+		if len(pkg.Licenses) == 0 {
+			packages[i].Licenses = caches.Licenses(pkg.Ecosystem, pkg.Name, pkg.Version)
+		}
+	}
 }
 
 // NoPackagesFoundErr for when no packages are found during a scan.
@@ -86,7 +227,10 @@ var ErrAPIFailed = errors.New("API query failed")
 //   - Any lockfiles with scanLockfile
 //   - Any SBOM files with scanSBOMFile
 //   - Any git repositories with scanGit
-func scanDir(r reporter.Reporter, dir string, skipGit bool, recursive bool, useGitIgnore bool, compareOffline bool, enabledParsers map[string]bool) ([]scannedPackage, []models.ScannedArtifact, error) {
+//
+// The files that are found are scanned by a pool of workers while the walk
+// continues, with the results being returned in the order they were found.
+func scanDir(ctx context.Context, r reporter.Reporter, dir string, skipGit bool, recursive bool, useGitIgnore bool, compareOffline bool, scanArchives bool, enabledParsers map[string]bool, pathFilter *pathfilter.Filter, parallelism int, extractionCache *lockfile.Cache) ([]scannedPackage, []models.ScannedArtifact, error) {
 	var ignoreMatcher *gitIgnoreMatcher
 	if useGitIgnore {
 		var err error
@@ -99,29 +243,38 @@ func scanDir(r reporter.Reporter, dir string, skipGit bool, recursive bool, useG
 
 	root := true
 
-	var scannedPackages []scannedPackage
-	var scannedArtifacts []models.ScannedArtifact
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		r.Errorf("Failed to walk path %s\n", err)
+		return nil, nil, err
+	}
 
-	return scannedPackages, scannedArtifacts, filepath.WalkDir(dir, func(path string, info os.DirEntry, err error) error {
+	scan := newParallelScan(ctx, r, parallelism)
+
+	err = filepath.WalkDir(dir, func(path string, info os.DirEntry, err error) error {
 		if err != nil {
-			r.Infof("Failed to walk %s: %v\n", path, err)
+			scan.reporter().Infof("Failed to walk %s: %v\n", path, err)
+			return err
+		}
+
+		if err := ctx.Err(); err != nil {
 			return err
 		}
 
 		path, err = filepath.Abs(path)
 		if err != nil {
-			r.Errorf("Failed to walk path %s\n", err)
+			scan.reporter().Errorf("Failed to walk path %s\n", err)
 			return err
 		}
 
 		if useGitIgnore {
 			match, err := ignoreMatcher.match(path, info.IsDir())
 			if err != nil {
-				r.Infof("Failed to resolve gitignore for %s: %v\n", path, err)
+				scan.reporter().Infof("Failed to resolve gitignore for %s: %v\n", path, err)
 				// Don't skip if we can't parse now - potentially noisy for directories with lots of items
 			} else if match {
 				if root { // Don't silently skip if the argument file was ignored.
-					r.Errorf("%s was not scanned because it is excluded by a .gitignore file. Use --no-ignore to scan it.\n", path)
+					scan.reporter().Errorf("%s was not scanned because it is excluded by a .gitignore file. Use --no-ignore to scan it.\n", path)
 				}
 				if info.IsDir() {
 					return filepath.SkipDir
@@ -131,33 +284,78 @@ func scanDir(r reporter.Reporter, dir string, skipGit bool, recursive bool, useG
 			}
 		}
 
-		if !skipGit && info.IsDir() && info.Name() == ".git" {
-			pkgs, err := scanGit(r, filepath.Dir(path)+"/")
-			if err != nil {
-				r.Infof("scan failed for git repository, %s: %v\n", path, err)
-				// Not fatal, so don't return and continue scanning other files
+		// paths are filtered relative to the directory being scanned
+		relPath, _ := filepath.Rel(absDir, path)
+		relPath = filepath.ToSlash(relPath)
+
+		if !root && info.IsDir() {
+			if skip, reason := pathFilter.SkipDir(relPath); skip {
+				scan.reporter().Verbosef("Skipping %s as %s\n", path, reason)
+				return filepath.SkipDir
 			}
-			scannedPackages = append(scannedPackages, pkgs...)
+		}
+
+		if !skipGit && info.IsDir() && info.Name() == ".git" {
+			scan.add(func(r reporter.Reporter) ([]scannedPackage, []models.ScannedArtifact) {
+				pkgs, err := scanGit(r, filepath.Dir(path)+"/")
+				if err != nil {
+					r.Infof("scan failed for git repository, %s: %v\n", path, err)
+					// Not fatal, so don't return and continue scanning other files
+				}
+
+				return pkgs, nil
+			})
 
 			return filepath.SkipDir
 		}
 
 		if !info.IsDir() {
-			if extractor, _ := lockfile.FindExtractor(path, "", enabledParsers); extractor != nil {
-				pkgs, artifact, err := scanLockfile(r, path, "", compareOffline, enabledParsers)
-				if err != nil {
-					r.Warnf("Attempted to scan lockfile but failed: %s (%v)\n", path, err.Error())
-				}
-				scannedPackages = append(scannedPackages, pkgs...)
-				if artifact != nil {
-					scannedArtifacts = append(scannedArtifacts, *artifact)
+			extractor, parser := lockfile.FindExtractor(path, "", enabledParsers)
+			isSBOM := isRecognizedSBOMFile(path)
+			isArchive := scanArchives && archive.IsArchive(path)
+
+			if extractor != nil || isSBOM || isArchive {
+				if skip, reason := pathFilter.SkipFile(relPath, parser); skip && !root {
+					scan.reporter().Verbosef("Skipping %s as %s\n", path, reason)
+
+					return nil
 				}
+
+				scan.add(func(r reporter.Reporter) ([]scannedPackage, []models.ScannedArtifact) {
+					var scannedPackages []scannedPackage
+					var scannedArtifacts []models.ScannedArtifact
+
+					if extractor != nil {
+						pkgs, artifact, err := scanLockfile(r, path, "", compareOffline, enabledParsers, extractionCache)
+						if err != nil {
+							r.Warnf("Attempted to scan lockfile but failed: %s (%v)\n", path, err.Error())
+						}
+						scannedPackages = append(scannedPackages, pkgs...)
+						if artifact != nil {
+							scannedArtifacts = append(scannedArtifacts, *artifact)
+						}
+					}
+
+					if isSBOM {
+						// No need to check for error
+						// If scan fails, it means it isn't a valid SBOM file,
+						// so just move onto the next file
+						pkgs, _ := scanSBOMFile(r, path, true)
+						scannedPackages = append(scannedPackages, pkgs...)
+					}
+
+					if isArchive {
+						pkgs, artifacts, err := scanArchive(r, path, relPath, enabledParsers, pathFilter)
+						if err != nil {
+							r.Warnf("Attempted to scan archive but failed: %s (%v)\n", path, err.Error())
+						}
+						scannedPackages = append(scannedPackages, pkgs...)
+						scannedArtifacts = append(scannedArtifacts, artifacts...)
+					}
+
+					return scannedPackages, scannedArtifacts
+				})
 			}
-			// No need to check for error
-			// If scan fails, it means it isn't a valid SBOM file,
-			// so just move onto the next file
-			pkgs, _ := scanSBOMFile(r, path, true)
-			scannedPackages = append(scannedPackages, pkgs...)
 		}
 
 		if !root && !recursive && info.IsDir() {
@@ -167,6 +365,10 @@ func scanDir(r reporter.Reporter, dir string, skipGit bool, recursive bool, useG
 
 		return nil
 	})
+
+	scannedPackages, scannedArtifacts := scan.wait()
+
+	return scannedPackages, scannedArtifacts, err
 }
 
 type gitIgnoreMatcher struct {
@@ -201,8 +403,8 @@ func (m *gitIgnoreMatcher) match(absPath string, isDir bool) (bool, error) {
 	return m.matcher.Match(pathInGitSep, isDir), nil
 }
 
-func scanImage(r reporter.Reporter, path string) ([]scannedPackage, error) {
-	scanResults, err := image.ScanImage(r, path)
+func scanImage(ctx context.Context, r reporter.Reporter, path string) ([]scannedPackage, error) {
+	scanResults, err := image.ScanImage(ctx, r, path)
 	if err != nil {
 		return []scannedPackage{}, err
 	}
@@ -221,6 +423,7 @@ func scanImage(r reporter.Reporter, path string) ([]scannedPackage, error) {
 					Path: path + ":" + l.FilePath,
 					Type: "docker",
 				},
+				Licenses: pkgDetail.Licenses,
 			})
 		}
 	}
@@ -230,7 +433,7 @@ func scanImage(r reporter.Reporter, path string) ([]scannedPackage, error) {
 
 // scanLockfile will load, identify, and parse the lockfile path passed in, and add the dependencies specified
 // within to `query`
-func scanLockfile(r reporter.Reporter, path string, parseAs string, _ bool, enabledParsers map[string]bool) ([]scannedPackage, *models.ScannedArtifact, error) {
+func scanLockfile(r reporter.Reporter, path string, parseAs string, _ bool, enabledParsers map[string]bool, extractionCache *lockfile.Cache) ([]scannedPackage, *models.ScannedArtifact, error) {
 	var err error
 	var parsedLockfile lockfile.Lockfile
 
@@ -248,7 +451,11 @@ func scanLockfile(r reporter.Reporter, path string, parseAs string, _ bool, enab
 		case "osv-scanner":
 			parsedLockfile, err = lockfile.FromOSVScannerResults(path)
 		default:
-			parsedLockfile, err = lockfile.ExtractDeps(f, parseAs, enabledParsers)
+			if extractionCache != nil {
+				parsedLockfile, err = extractionCache.ExtractDeps(path, parseAs, enabledParsers)
+			} else {
+				parsedLockfile, err = lockfile.ExtractDeps(f, parseAs, enabledParsers)
+			}
 			// We are disabling this as we don't want to go through deps.dev to detect packages
 			// if !compareOffline && (parseAs == "pom.xml" || filepath.Base(path) == "pom.xml") {
 			//	parsedLockfile, err = extractMavenDeps(f)
@@ -262,6 +469,65 @@ func scanLockfile(r reporter.Reporter, path string, parseAs string, _ bool, enab
 		return nil, nil, err
 	}
 
+	return lockfilePackages(r, path, parseAs, parsedLockfile), parsedLockfile.Artifact, nil
+}
+
+// stdinLockfilePath is the lockfile path used to read a lockfile from stdin
+const stdinLockfilePath = "-"
+
+// stdinReportedPath is the synthetic path that lockfiles read from stdin
+// are reported with, as they do not have a path of their own
+const stdinReportedPath = "<stdin>"
+
+// scanStdinLockfile extracts a lockfile that is read from stdin, which must
+// be done with an explicit parser as there is no path to determine one from
+func scanStdinLockfile(r reporter.Reporter, stdin io.Reader, parseAs string, sourceDir string, enabledParsers map[string]bool) ([]scannedPackage, *models.ScannedArtifact, error) {
+	if parseAs == "" {
+		return nil, nil, errors.New("a parser must be specified to read a lockfile from stdin, i.e. requirements.txt:-")
+	}
+
+	if stdin == nil {
+		stdin = os.Stdin
+	}
+
+	if sourceDir != "" {
+		var err error
+		if sourceDir, err = filepath.Abs(sourceDir); err != nil {
+			return nil, nil, fmt.Errorf("failed to resolve source directory: %w", err)
+		}
+	}
+
+	f, err := lockfile.ReadDepFile(stdin, stdinReportedPath, sourceDir)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read lockfile from stdin: %w", err)
+	}
+	defer f.Close()
+
+	parsedLockfile, err := lockfile.ExtractDeps(f, parseAs, enabledParsers)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// without a source directory every source file is missing, which is expected
+	if sourceDir == "" {
+		parsedLockfile.MatchErrors = nil
+	}
+
+	return lockfilePackages(r, stdinReportedPath, parseAs, parsedLockfile), parsedLockfile.Artifact, nil
+}
+
+// lockfilePackages reports on the packages that were extracted from
+// the lockfile at the given path, converting them into scannedPackages
+func lockfilePackages(r reporter.Reporter, path string, parseAs string, parsedLockfile lockfile.Lockfile) []scannedPackage {
+	// missing source files are common, so these are only worth mentioning when asked for
+	for _, matchErr := range parsedLockfile.MatchErrors {
+		r.Verbosef("%s\n", matchErr)
+	}
+
+	for _, warning := range parsedLockfile.Warnings {
+		r.Warnf("%s: %s\n", path, warning)
+	}
+
 	parsedAsComment := ""
 
 	if parseAs != "" {
@@ -278,6 +544,11 @@ func scanLockfile(r reporter.Reporter, path string, parseAs string, _ bool, enab
 
 	packages := make([]scannedPackage, len(parsedLockfile.Packages))
 	for i, pkgDetail := range parsedLockfile.Packages {
+		var dir string
+		if pkgDetail.Dir != "" {
+			dir = filepath.Join(filepath.Dir(path), filepath.FromSlash(pkgDetail.Dir))
+		}
+
 		packages[i] = scannedPackage{
 			Name:           pkgDetail.Name,
 			Version:        pkgDetail.Version,
@@ -293,10 +564,70 @@ func scanLockfile(r reporter.Reporter, path string, parseAs string, _ bool, enab
 			BlockLocation:   pkgDetail.BlockLocation,
 			VersionLocation: pkgDetail.VersionLocation,
 			NameLocation:    pkgDetail.NameLocation,
+			Licenses:        pkgDetail.Licenses,
+			Dir:             dir,
 		}
 	}
 
-	return packages, parsedLockfile.Artifact, nil
+	return packages
+}
+
+// scanArchive extracts the lockfiles within the archive at the given path,
+// which are reported with paths like "release.zip!/app/package-lock.json"
+func scanArchive(r reporter.Reporter, path string, relPath string, enabledParsers map[string]bool, pathFilter *pathfilter.Filter) ([]scannedPackage, []models.ScannedArtifact, error) {
+	var scannedPackages []scannedPackage
+	var scannedArtifacts []models.ScannedArtifact
+
+	opts := archive.Options{
+		Limits: archive.DefaultLimits,
+		// only the files that could be extracted, or opened while extracting
+		// them, are decompressed
+		Wanted: func(name string) bool {
+			extractor, _ := lockfile.FindExtractor(name, "", enabledParsers)
+
+			return extractor != nil || lockfile.IsSourceFile(name)
+		},
+		Skipped: func(entryPath string, err error) {
+			r.Warnf("Skipping %s as %v\n", entryPath, err)
+		},
+	}
+
+	err := archive.Walk(path, opts, func(f archive.File) error {
+		entryPath := f.Path()
+
+		extractor, parser := lockfile.FindExtractor(entryPath, "", enabledParsers)
+		if extractor == nil {
+			return nil
+		}
+
+		entryRelPath := strings.TrimPrefix(entryPath, path)
+		if relPath != "." {
+			entryRelPath = relPath + entryRelPath
+		} else {
+			entryRelPath = filepath.Base(path) + entryRelPath
+		}
+		if skip, reason := pathFilter.SkipFile(entryRelPath, parser); skip {
+			r.Verbosef("Skipping %s as %s\n", entryPath, reason)
+
+			return nil
+		}
+
+		parsedLockfile, err := lockfile.ExtractDeps(f, "", enabledParsers)
+		if err != nil {
+			r.Warnf("Attempted to scan lockfile but failed: %s (%v)\n", entryPath, err.Error())
+
+			return nil
+		}
+
+		scannedPackages = append(scannedPackages, lockfilePackages(r, entryPath, "", parsedLockfile)...)
+		if parsedLockfile.Artifact != nil {
+			scannedArtifacts = append(scannedArtifacts, *parsedLockfile.Artifact)
+		}
+
+		return nil
+	})
+
+	return scannedPackages, scannedArtifacts, err
 }
 
 // func extractMavenDeps(f lockfile.DepFile) (lockfile.Lockfile, error) {
@@ -329,6 +660,19 @@ func scanLockfile(r reporter.Reporter, path string, parseAs string, _ bool, enab
 //	}, err
 //}
 
+// isRecognizedSBOMFile returns true if the file is named like an SBOM file
+// of any format, which are the only files scanSBOMFile considers when scanning
+// a directory
+func isRecognizedSBOMFile(path string) bool {
+	for _, provider := range sbom.Providers {
+		if provider.MatchesRecognizedFileNames(path) {
+			return true
+		}
+	}
+
+	return false
+}
+
 // scanSBOMFile will load, identify, and parse the SBOM path passed in, and add the dependencies specified
 // within to `query`
 func scanSBOMFile(r reporter.Reporter, path string, fromFSScan bool) ([]scannedPackage, error) {
@@ -493,8 +837,8 @@ func createCommitQueryPackage(commit string, source string) scannedPackage {
 	}
 }
 
-func scanDebianDocker(r reporter.Reporter, dockerImageName string) ([]scannedPackage, error) {
-	cmd := exec.Command("docker", "run", "--rm", "--entrypoint", "/usr/bin/dpkg-query", dockerImageName, "-f", "${Package}###${Version}\\n", "-W")
+func scanDebianDocker(ctx context.Context, r reporter.Reporter, dockerImageName string) ([]scannedPackage, error) {
+	cmd := exec.CommandContext(ctx, "docker", "run", "--rm", "--entrypoint", "/usr/bin/dpkg-query", dockerImageName, "-f", "${Package}###${Version}\\n", "-W")
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
 		r.Errorf("Failed to get stdout: %s\n", err)
@@ -550,7 +894,7 @@ func filterResults(r reporter.Reporter, results *models.VulnerabilityResults, co
 		configToUse := configManager.Get(r, pkgSrc.Source.Path)
 		var newPackages []models.PackageVulns
 		for _, pkgVulns := range pkgSrc.Packages {
-			newVulns := filterPackageVulns(r, pkgVulns, configToUse, &unimportantCount)
+			newVulns := filterPackageVulns(r, pkgVulns, configToUse, sourceFilePath(pkgSrc.Source), &unimportantCount)
 			removedCount += len(pkgVulns.Vulnerabilities) - len(newVulns.Vulnerabilities)
 			if allPackages || len(newVulns.Vulnerabilities) > 0 || len(pkgVulns.LicenseViolations) > 0 {
 				newPackages = append(newPackages, newVulns)
@@ -572,7 +916,7 @@ func filterResults(r reporter.Reporter, results *models.VulnerabilityResults, co
 }
 
 // Filters package-grouped vulnerabilities according to config, preserving ordering. Returns filtered package vulnerabilities.
-func filterPackageVulns(r reporter.Reporter, pkgVulns models.PackageVulns, configToUse config.Config, unimportantCount *int) models.PackageVulns {
+func filterPackageVulns(r reporter.Reporter, pkgVulns models.PackageVulns, configToUse config.Config, sourcePath string, unimportantCount *int) models.PackageVulns {
 	if ignore, ignoreLine := configToUse.ShouldIgnorePackageVersion(pkgVulns.Package.Name, pkgVulns.Package.Version, pkgVulns.Package.Ecosystem); ignore {
 		pkgString := fmt.Sprintf("%s/%s/%s", pkgVulns.Package.Ecosystem, pkgVulns.Package.Name, pkgVulns.Package.Version)
 		switch len(pkgVulns.Vulnerabilities) {
@@ -587,6 +931,12 @@ func filterPackageVulns(r reporter.Reporter, pkgVulns models.PackageVulns, confi
 		return pkgVulns
 	}
 	ignoredVulns := map[string]struct{}{}
+	target := config.IgnoreTarget{
+		Name:       pkgVulns.Package.Name,
+		Version:    pkgVulns.Package.Version,
+		Ecosystem:  pkgVulns.Package.Ecosystem,
+		SourcePath: sourcePath,
+	}
 
 	// Ignores all unimportant vulnerabilities.
 	for _, vuln := range pkgVulns.Vulnerabilities {
@@ -603,7 +953,7 @@ func filterPackageVulns(r reporter.Reporter, pkgVulns models.PackageVulns, confi
 		ignore := false
 		for _, id := range group.Aliases {
 			var ignoreLine config.IgnoreEntry
-			if ignore, ignoreLine = configToUse.ShouldIgnore(id); ignore {
+			if ignore, ignoreLine = configToUse.ShouldIgnoreIn(id, target); ignore {
 				for _, id := range group.Aliases {
 					ignoredVulns[id] = struct{}{}
 				}
@@ -663,6 +1013,69 @@ func isUnimportant(ecosystem string, affectedPackages []models.Affected) bool {
 	return false
 }
 
+// newPathFilter creates the filter for the paths within the given directory,
+// from the patterns in the scan actions and the config for the directory
+func newPathFilter(r reporter.Reporter, actions ScannerActions, configManager *config.ConfigManager, dir string) (*pathfilter.Filter, error) {
+	filter := &pathfilter.Filter{}
+
+	addRule := func(include, exclude, parsers []string) error {
+		if len(include) == 0 && len(exclude) == 0 {
+			return nil
+		}
+
+		rule, err := pathfilter.NewRule(include, exclude, parsers)
+		if err != nil {
+			return err
+		}
+		filter.Rules = append(filter.Rules, rule)
+
+		return nil
+	}
+
+	for _, elem := range actions.IncludePaths {
+		parser, glob := parsePathFilter(elem)
+		if err := addRule([]string{glob}, nil, parser); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, elem := range actions.ExcludePaths {
+		parser, glob := parsePathFilter(elem)
+		if err := addRule(nil, []string{glob}, parser); err != nil {
+			return nil, err
+		}
+	}
+
+	entries, loadPath := configManager.GetPathFilters(dir)
+	if len(entries) > 0 {
+		r.Verbosef("Using path filters from: %s\n", loadPath)
+	}
+
+	for _, entry := range entries {
+		if err := addRule(entry.Include, entry.Exclude, entry.Parsers); err != nil {
+			return nil, fmt.Errorf("invalid path filter in %s: %w", loadPath, err)
+		}
+	}
+
+	if len(filter.Rules) == 0 {
+		return nil, nil
+	}
+
+	return filter, nil
+}
+
+// parsePathFilter splits a path filter into the parser it is limited to
+// (if any) and its glob, i.e. "requirements.txt:docs/**"
+func parsePathFilter(elem string) ([]string, string) {
+	parser, glob, found := strings.Cut(elem, ":")
+
+	if !found || !slices.Contains(lockfile.ListExtractors(), parser) {
+		return nil, elem
+	}
+
+	return []string{parser}, glob
+}
+
 func parseLockfilePath(lockfileElem string) (string, string) {
 	if !strings.Contains(lockfileElem, ":") {
 		lockfileElem = ":" + lockfileElem
@@ -686,6 +1099,11 @@ type scannedPackage struct {
 	BlockLocation   models.FilePosition
 	VersionLocation *models.FilePosition
 	NameLocation    *models.FilePosition
+	// Licenses are the licenses that the package declares locally
+	Licenses []string
+	// Dir is the directory on disk that the package is installed or
+	// vendored in, if it is known
+	Dir string
 }
 
 func initializeEnabledParsers(enabledParsers []string) map[string]bool {
@@ -707,48 +1125,64 @@ func initializeEnabledParsers(enabledParsers []string) map[string]bool {
 
 // Perform osv scanner action, with optional reporter to output information
 func DoScan(actions ScannerActions, r reporter.Reporter) (models.VulnerabilityResults, error) {
-	enabledParsers := initializeEnabledParsers(actions.EnableParsers)
+	return DoScanWithContext(context.Background(), actions, r)
+}
 
-	if r == nil {
-		r = &reporter.VoidReporter{}
-	}
+// DoScanWithContext performs the osv scanner action like DoScan, stopping
+// as soon as possible with the context error if the context is cancelled
+func DoScanWithContext(ctx context.Context, actions ScannerActions, r reporter.Reporter) (models.VulnerabilityResults, error) {
+	return NewScanner(WithReporter(r)).Scan(ctx, actions)
+}
 
-	if actions.CompareOffline {
-		actions.SkipGit = true
+// Scan performs the osv scanner action, stopping as soon as possible with
+// the context error if the context is cancelled
+func (s *Scanner) Scan(ctx context.Context, actions ScannerActions) (models.VulnerabilityResults, error) {
+	return s.scanWithBaseline(ctx, actions, nil)
+}
 
-		if len(actions.ScanLicensesAllowlist) > 0 || actions.ScanLicensesSummary {
-			return models.VulnerabilityResults{}, errors.New("cannot retrieve licenses locally")
-		}
+// scan performs the osv scanner action, reading lockfiles and directories
+// from the given filesystem if there is one or the local filesystem otherwise
+func (s *Scanner) scan(ctx context.Context, actions ScannerActions, fsys fs.FS) (models.VulnerabilityResults, error) {
+	r := s.reporter
+
+	if len(actions.EnableParsers) == 0 {
+		actions.EnableParsers = s.enabledParsers
 	}
+	enabledParsers := initializeEnabledParsers(actions.EnableParsers)
 
 	if !actions.CompareOffline && actions.DownloadDatabases {
 		return models.VulnerabilityResults{}, errors.New("databases can only be downloaded when running in offline mode")
 	}
 
-	configManager := config.ConfigManager{
-		DefaultConfig: config.Config{},
-		ConfigMap:     make(map[string]config.Config),
+	if fsys == nil && (actions.GitRev != "" || actions.GitDiff != "") {
+		return s.scanGitRevisions(ctx, actions)
 	}
 
-	//nolint:prealloc // Not sure how many there will be in advance.
-	var scannedPackages []scannedPackage
-	var scannedArtifacts []models.ScannedArtifact
+	if fsys != nil && (actions.ScanOCIImage != "" || len(actions.DockerContainerNames) > 0 || len(actions.SBOMPaths) > 0) {
+		return models.VulnerabilityResults{}, errors.New("only lockfiles and directories can be scanned from a filesystem")
+	}
 
-	if actions.Debug {
-		os.Setenv("debug", "true")
+	configManager, err := s.newConfigManager(actions, fsys)
+	if err != nil {
+		r.Errorf("Failed to read config file: %s\n", err)
+		return models.VulnerabilityResults{}, err
 	}
 
-	if actions.ConfigOverridePath != "" {
-		err := configManager.UseOverride(actions.ConfigOverridePath)
+	var extractionCache *lockfile.Cache
+	if actions.UseExtractionCache && fsys == nil {
+		extractionCache, err = newExtractionCache(actions.ExtractionCacheDir)
 		if err != nil {
-			r.Errorf("Failed to read config file: %s\n", err)
-			return models.VulnerabilityResults{}, err
+			r.Warnf("Failed to set up extraction cache, continuing without it: %v\n", err)
 		}
 	}
 
+	//nolint:prealloc // Not sure how many there will be in advance.
+	var scannedPackages []scannedPackage
+	var scannedArtifacts []models.ScannedArtifact
+
 	if actions.ExperimentalScannerActions.ScanOCIImage != "" {
 		r.Infof("Scanning image %s\n", actions.ExperimentalScannerActions.ScanOCIImage)
-		pkgs, err := scanImage(r, actions.ExperimentalScannerActions.ScanOCIImage)
+		pkgs, err := scanImage(ctx, r, actions.ExperimentalScannerActions.ScanOCIImage)
 		if err != nil {
 			return models.VulnerabilityResults{}, err
 		}
@@ -758,18 +1192,55 @@ func DoScan(actions ScannerActions, r reporter.Reporter) (models.VulnerabilityRe
 
 	// TODO: Deprecated
 	for _, container := range actions.DockerContainerNames {
-		pkgs, _ := scanDebianDocker(r, container)
+		pkgs, _ := scanDebianDocker(ctx, r, container)
 		scannedPackages = append(scannedPackages, pkgs...)
 	}
 
+	readStdin := false
 	for _, lockfileElem := range actions.LockfilePaths {
+		if err := ctx.Err(); err != nil {
+			return models.VulnerabilityResults{}, err
+		}
+
 		parseAs, lockfilePath := parseLockfilePath(lockfileElem)
+
+		if lockfilePath == stdinLockfilePath {
+			if readStdin {
+				return models.VulnerabilityResults{}, errors.New("only one lockfile can be read from stdin")
+			}
+			readStdin = true
+
+			pkgs, artifact, err := scanStdinLockfile(r, actions.Stdin, parseAs, actions.StdinSourceDir, enabledParsers)
+			if err != nil {
+				return models.VulnerabilityResults{}, err
+			}
+			scannedPackages = append(scannedPackages, pkgs...)
+			if artifact != nil {
+				scannedArtifacts = append(scannedArtifacts, *artifact)
+			}
+
+			continue
+		}
+
+		if fsys != nil {
+			pkgs, artifact, err := scanFSLockfile(r, fsys, lockfilePath, parseAs, enabledParsers)
+			if err != nil {
+				return models.VulnerabilityResults{}, err
+			}
+			scannedPackages = append(scannedPackages, pkgs...)
+			if artifact != nil {
+				scannedArtifacts = append(scannedArtifacts, *artifact)
+			}
+
+			continue
+		}
+
 		lockfilePath, err := filepath.Abs(lockfilePath)
 		if err != nil {
 			r.Errorf("Failed to resolved path with error %s\n", err)
 			return models.VulnerabilityResults{}, err
 		}
-		pkgs, artifact, err := scanLockfile(r, lockfilePath, parseAs, actions.CompareOffline, enabledParsers)
+		pkgs, artifact, err := scanLockfile(r, lockfilePath, parseAs, actions.CompareOffline, enabledParsers, extractionCache)
 		if err != nil {
 			return models.VulnerabilityResults{}, err
 		}
@@ -780,6 +1251,10 @@ func DoScan(actions ScannerActions, r reporter.Reporter) (models.VulnerabilityRe
 	}
 
 	for _, sbomElem := range actions.SBOMPaths {
+		if err := ctx.Err(); err != nil {
+			return models.VulnerabilityResults{}, err
+		}
+
 		sbomElem, err := filepath.Abs(sbomElem)
 		if err != nil {
 			return models.VulnerabilityResults{}, fmt.Errorf("failed to resolved path with error %w", err)
@@ -797,36 +1272,83 @@ func DoScan(actions ScannerActions, r reporter.Reporter) (models.VulnerabilityRe
 
 	for _, dir := range actions.DirectoryPaths {
 		r.Infof("Scanning dir %s\n", dir)
-		pkgs, artifacts, err := scanDir(r, dir, actions.SkipGit, actions.Recursive, !actions.NoIgnore, actions.CompareOffline, enabledParsers)
+		pathFilter, err := newPathFilter(r, actions, configManager, dir)
 		if err != nil {
 			return models.VulnerabilityResults{}, err
 		}
 
-		if actions.ConsiderScanPathAsRoot || actions.PathRelativeToScanDir {
-			for index, pkg := range pkgs {
-				pkgs[index].Source.ScanPath = dir
-				pkgs[index].Source.Path = fileposition.ToRelativePath(dir, pkg.Source.Path)
-				pkgs[index].BlockLocation.Filename = fileposition.ToRelativePath(dir, pkg.BlockLocation.Filename)
+		if fsys != nil {
+			pkgs, artifacts, err := scanFSDir(ctx, r, fsys, dir, actions.Recursive, enabledParsers, pathFilter, actions.Parallelism)
+			if err != nil {
+				return models.VulnerabilityResults{}, err
+			}
+			scannedPackages = append(scannedPackages, pkgs...)
+			scannedArtifacts = append(scannedArtifacts, artifacts...)
 
-				if pkgs[index].NameLocation != nil {
-					pkgs[index].NameLocation.Filename = fileposition.ToRelativePath(dir, pkg.NameLocation.Filename)
-				}
+			continue
+		}
 
-				if pkgs[index].VersionLocation != nil {
-					pkgs[index].VersionLocation.Filename = fileposition.ToRelativePath(dir, pkg.VersionLocation.Filename)
-				}
-			}
-			for index, artifact := range artifacts {
-				artifacts[index].Filename = fileposition.ToRelativePath(dir, artifact.Filename)
-				if artifact.DependsOn != nil {
-					artifacts[index].DependsOn.Filename = fileposition.ToRelativePath(dir, artifact.DependsOn.Filename)
-				}
-			}
+		pkgs, artifacts, err := scanDir(ctx, r, dir, actions.SkipGit, actions.Recursive, !actions.NoIgnore, actions.CompareOffline, actions.ScanArchives, enabledParsers, pathFilter, actions.Parallelism, extractionCache)
+		if err != nil {
+			return models.VulnerabilityResults{}, err
+		}
+
+		if actions.ConsiderScanPathAsRoot || actions.PathRelativeToScanDir {
+			relativizePaths(dir, pkgs, artifacts)
 		}
 		scannedPackages = append(scannedPackages, pkgs...)
 		scannedArtifacts = append(scannedArtifacts, artifacts...)
 	}
 
+	if extractionCache != nil {
+		stats := extractionCache.Stats()
+		r.Verbosef(
+			"Extraction cache: %d/%d %s served from %s\n",
+			stats.Hits,
+			stats.Hits+stats.Misses,
+			output.Form(stats.Hits+stats.Misses, "lockfile", "lockfiles"),
+			extractionCache.Dir,
+		)
+	}
+
+	results, err := s.queryResults(ctx, actions, configManager, scannedPackages, scannedArtifacts, nil)
+
+	if actions.ReportConfigIssues {
+		reportConfigIssues(r, configManager)
+	}
+
+	return results, err
+}
+
+// relativizePaths makes the paths of the packages and artifacts that were
+// found by scanning the given directory relative to it
+func relativizePaths(dir string, pkgs []scannedPackage, artifacts []models.ScannedArtifact) {
+	for index, pkg := range pkgs {
+		pkgs[index].Source.ScanPath = dir
+		pkgs[index].Source.Path = fileposition.ToRelativePath(dir, pkg.Source.Path)
+		pkgs[index].BlockLocation.Filename = fileposition.ToRelativePath(dir, pkg.BlockLocation.Filename)
+
+		if pkgs[index].NameLocation != nil {
+			pkgs[index].NameLocation.Filename = fileposition.ToRelativePath(dir, pkg.NameLocation.Filename)
+		}
+
+		if pkgs[index].VersionLocation != nil {
+			pkgs[index].VersionLocation.Filename = fileposition.ToRelativePath(dir, pkg.VersionLocation.Filename)
+		}
+	}
+	for index, artifact := range artifacts {
+		artifacts[index].Filename = fileposition.ToRelativePath(dir, artifact.Filename)
+		if artifact.DependsOn != nil {
+			artifacts[index].DependsOn.Filename = fileposition.ToRelativePath(dir, artifact.DependsOn.Filename)
+		}
+	}
+}
+
+// queryResults checks the scanned packages against the OSV database,
+// reusing the results of previous queries if there is a memo of them
+func (s *Scanner) queryResults(ctx context.Context, actions ScannerActions, configManager *config.ConfigManager, scannedPackages []scannedPackage, scannedArtifacts []models.ScannedArtifact, memo *queryMemo) (models.VulnerabilityResults, error) {
+	r := s.reporter
+
 	if len(scannedPackages) == 0 {
 		return models.VulnerabilityResults{}, NoPackagesFoundErr
 	}
@@ -837,28 +1359,59 @@ func DoScan(actions ScannerActions, r reporter.Reporter) (models.VulnerabilityRe
 		r.Infof("Filtered %d local package/s from the scan.\n", len(scannedPackages)-len(filteredScannedPackages))
 	}
 
-	overrideGoVersion(r, filteredScannedPackages, &configManager)
+	overrideGoVersion(r, filteredScannedPackages, configManager)
 
 	if actions.OnlyPackages {
 		vulnerabilityResults := groupBySource(r, scannedPackages, scannedArtifacts)
 
 		return vulnerabilityResults, nil
 	}
-	vulnsResp, err := makeRequest(r, filteredScannedPackages, actions.CompareOffline, actions.DownloadDatabases, actions.LocalDBPath)
+	apiClient := s.apiClient
+	if apiClient == nil && !actions.CompareOffline {
+		var err error
+		apiClient, err = s.newAPIClient(actions, configManager)
+		if err != nil {
+			return models.VulnerabilityResults{}, err
+		}
+	}
+
+	queryVulns := func(packages []scannedPackage) (*osv.HydratedBatchedResponse, error) {
+		return makeRequest(ctx, r, packages, actions.CompareOffline, local.Options{
+			Offline:     !actions.DownloadDatabases,
+			LocalDBPath: actions.LocalDBPath,
+			HTTPClient:  s.httpClient,
+			UserAgent:   s.userAgent,
+		}, apiClient)
+	}
+	queryLicenses := func(packages []scannedPackage) ([][]models.License, error) {
+		return makeLicensesRequests(ctx, s.userAgent, packages)
+	}
+
+	if memo != nil {
+		queryVulns = memo.memoizeVulnerabilities(queryVulns)
+		queryLicenses = memo.memoizeLicenses(queryLicenses)
+	}
+
+	vulnsResp, err := queryVulns(filteredScannedPackages)
 	if err != nil {
 		return models.VulnerabilityResults{}, err
 	}
 
+	// when offline, only the licenses that packages declare locally are used
 	var licensesResp [][]models.License
-	if len(actions.ScanLicensesAllowlist) > 0 || actions.ScanLicensesSummary {
-		licensesResp, err = makeLicensesRequests(filteredScannedPackages)
-		if err != nil {
-			return models.VulnerabilityResults{}, err
+	if actions.checksLicenses(r, configManager, filteredScannedPackages) {
+		addCachedLicenses(filteredScannedPackages, *s.packageCaches)
+
+		if !actions.CompareOffline {
+			licensesResp, err = queryLicenses(filteredScannedPackages)
+			if err != nil {
+				return models.VulnerabilityResults{}, err
+			}
 		}
 	}
-	results := buildVulnerabilityResults(r, filteredScannedPackages, scannedArtifacts, vulnsResp, licensesResp, actions, &configManager)
+	results := buildVulnerabilityResults(ctx, r, filteredScannedPackages, scannedArtifacts, vulnsResp, licensesResp, actions, configManager)
 
-	filtered := filterResults(r, &results, &configManager, actions.ShowAllPackages)
+	filtered := filterResults(r, &results, configManager, actions.ShowAllPackages)
 	if filtered > 0 {
 		r.Infof(
 			"Filtered %d %s from output\n",
@@ -867,36 +1420,54 @@ func DoScan(actions ScannerActions, r reporter.Reporter) (models.VulnerabilityRe
 		)
 	}
 
-	if len(results.Results) > 0 {
-		// Determine the correct error to return.
-		// TODO: in the next breaking release of osv-scanner, consider
-		// returning a ScanError instead of an error.
-		var vuln bool
-		onlyUncalledVuln := true
-		var licenseViolation bool
-		for _, vf := range results.Flatten() {
-			if vf.Vulnerability.ID != "" {
-				vuln = true
-				if vf.GroupInfo.IsCalled() {
-					onlyUncalledVuln = false
-				}
-			}
-			if len(vf.LicenseViolations) > 0 {
-				licenseViolation = true
-			}
-		}
-		onlyUncalledVuln = onlyUncalledVuln && vuln
-		licenseViolation = licenseViolation && len(actions.ScanLicensesAllowlist) > 0
+	if err := applyCVSSEnvironments(r, &results, configManager); err != nil {
+		return models.VulnerabilityResults{}, err
+	}
 
-		if (!vuln || onlyUncalledVuln) && !licenseViolation {
-			// There is no error.
-			return results, nil
+	ignored, err := applyPolicies(r, &results, configManager, actions.ShowAllPackages)
+	if err != nil {
+		return models.VulnerabilityResults{}, err
+	}
+	if ignored > 0 {
+		r.Infof(
+			"Ignored %d %s by policy\n",
+			ignored,
+			output.Form(ignored, "vulnerability", "vulnerabilities"),
+		)
+	}
+
+	return results, resultsError(results, actions)
+}
+
+// resultsError determines the correct error to return for the given results,
+// which is VulnerabilitiesFoundErr if there are any called vulnerabilities at
+// or above the FailOn threshold or license violations
+func resultsError(results models.VulnerabilityResults, actions ScannerActions) error {
+	if len(results.Results) == 0 {
+		return nil
+	}
+
+	policy := newFailurePolicy(actions)
+
+	// TODO: in the next breaking release of osv-scanner, consider
+	// returning a ScanError instead of an error.
+	var failingVuln bool
+	var licenseViolation bool
+	for _, vf := range results.Flatten() {
+		if vf.Vulnerability.ID != "" && vf.GroupInfo.IsCalled() && policy.fails(vf.GroupInfo) {
+			failingVuln = true
+		}
+		if len(vf.LicenseViolations) > 0 {
+			licenseViolation = true
 		}
+	}
 
-		return results, VulnerabilitiesFoundErr
+	if !failingVuln && !licenseViolation {
+		// There is no error.
+		return nil
 	}
 
-	return results, nil
+	return VulnerabilitiesFoundErr
 }
 
 // filterUnscannablePackages removes packages that don't have enough information to be scanned
@@ -945,11 +1516,12 @@ func patchPackageForRequest(pkg scannedPackage) scannedPackage {
 }
 
 func makeRequest(
+	ctx context.Context,
 	r reporter.Reporter,
 	packages []scannedPackage,
 	compareOffline bool,
-	downloadDBs bool,
-	localDBPath string,
+	localDBOptions local.Options,
+	apiClient *osv.Client,
 ) (*osv.HydratedBatchedResponse, error) {
 	// Make OSV queries from the packages.
 	var query osv.BatchedQuery
@@ -965,7 +1537,10 @@ func makeRequest(
 				PackageManager: models.Unknown,
 			}))
 		case p.Commit != "":
-			query.Queries = append(query.Queries, osv.MakeCommitRequest(p.Commit))
+			q := osv.MakeCommitRequest(p.Commit)
+			// The source is needed to find the repository when matching commits locally
+			q.Source = p.Source
+			query.Queries = append(query.Queries, q)
 		case p.PURL != "":
 			query.Queries = append(query.Queries, osv.MakePURLRequest(p.PURL))
 		default:
@@ -974,8 +1549,7 @@ func makeRequest(
 	}
 
 	if compareOffline {
-		// Downloading databases requires network access.
-		hydratedResp, err := local.MakeRequest(r, query, !downloadDBs, localDBPath)
+		hydratedResp, err := local.MakeRequest(ctx, r, query, localDBOptions)
 		if err != nil {
 			return &osv.HydratedBatchedResponse{}, fmt.Errorf("local comparison failed %w", err)
 		}
@@ -983,24 +1557,134 @@ func makeRequest(
 		return hydratedResp, nil
 	}
 
-	if osv.RequestUserAgent == "" {
-		osv.RequestUserAgent = "osv-scanner-api_v" + version.OSVVersion
-	}
-
-	resp, err := osv.MakeRequest(query)
+	resp, err := apiClient.MakeRequest(ctx, query)
 	if err != nil {
 		return &osv.HydratedBatchedResponse{}, fmt.Errorf("%w: osv.dev query failed: %w", ErrAPIFailed, err)
 	}
 
-	hydratedResp, err := osv.Hydrate(resp)
+	hydratedResp, err := apiClient.Hydrate(ctx, resp)
 	if err != nil {
 		return &osv.HydratedBatchedResponse{}, fmt.Errorf("%w: failed to hydrate OSV response: %w", ErrAPIFailed, err)
 	}
 
+	if apiCache := apiClient.Cache; apiCache != nil {
+		stats := apiCache.Stats()
+		r.Verbosef(
+			"API cache: %d/%d %s and %d/%d %s served from %s\n",
+			stats.QueryHits,
+			stats.QueryHits+stats.QueryMisses,
+			output.Form(stats.QueryHits+stats.QueryMisses, "query", "queries"),
+			stats.VulnHits,
+			stats.VulnHits+stats.VulnMisses,
+			output.Form(stats.VulnHits+stats.VulnMisses, "vulnerability", "vulnerabilities"),
+			apiCache.Dir,
+		)
+	}
+
 	return hydratedResp, nil
 }
 
-func makeLicensesRequests(packages []scannedPackage) ([][]models.License, error) {
+// newAPIClient creates the client for querying the OSV API, combining the
+// settings from the scan actions with those from the config file
+func (s *Scanner) newAPIClient(actions ScannerActions, configManager *config.ConfigManager) (*osv.Client, error) {
+	r := s.reporter
+	clientConfig := actions.APIClientConfig
+	if clientConfig.UserAgent == "" {
+		clientConfig.UserAgent = s.userAgent
+	}
+
+	apiConfig, loadPath := configManager.GetAPIConfig()
+	if loadPath != "" && !reflect.DeepEqual(apiConfig, config.APIConfig{}) {
+		r.Verbosef("Using API settings from: %s\n", loadPath)
+	}
+
+	if clientConfig.BaseURL == "" {
+		clientConfig.BaseURL = apiConfig.BaseURL
+	}
+	if clientConfig.CACertFile == "" && apiConfig.CACertFile != "" {
+		clientConfig.CACertFile = apiConfig.CACertFile
+		// relative paths are relative to the config file
+		if !filepath.IsAbs(clientConfig.CACertFile) {
+			clientConfig.CACertFile = filepath.Join(filepath.Dir(loadPath), clientConfig.CACertFile)
+		}
+	}
+	if clientConfig.ProxyURL == "" {
+		clientConfig.ProxyURL = apiConfig.Proxy
+	}
+	if clientConfig.Timeout == 0 {
+		clientConfig.Timeout = apiConfig.Timeout
+	}
+	if clientConfig.MaxRetryAttempts == 0 {
+		clientConfig.MaxRetryAttempts = apiConfig.MaxRetryAttempts
+	}
+	if clientConfig.MaxConcurrentRequests == 0 {
+		clientConfig.MaxConcurrentRequests = apiConfig.MaxConcurrentRequests
+	}
+
+	if len(apiConfig.Headers) > 0 {
+		headers := make(map[string]string, len(apiConfig.Headers)+len(clientConfig.Headers))
+		for name, value := range apiConfig.Headers {
+			headers[name] = value
+		}
+		for name, value := range clientConfig.Headers {
+			headers[name] = value
+		}
+		clientConfig.Headers = headers
+	}
+
+	client, err := osv.NewClient(clientConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure OSV API client: %w", err)
+	}
+
+	// only use our own client if the settings did not need a custom one
+	if s.httpClient != nil && client.HTTPClient == http.DefaultClient {
+		client.HTTPClient = s.httpClient
+	}
+
+	if actions.UseAPICache {
+		client.Cache, err = newAPICache(actions.APICacheDir, actions.APICacheTTL)
+		if err != nil {
+			r.Warnf("Failed to set up API cache, continuing without it: %v\n", err)
+		}
+	}
+
+	return client, nil
+}
+
+// newAPICache sets up the on-disk cache for OSV API responses,
+// using the default directory and TTL when they are not provided
+func newAPICache(dir string, ttl time.Duration) (*osv.Cache, error) {
+	if dir == "" {
+		var err error
+		dir, err = osv.DefaultCacheDir()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if ttl <= 0 {
+		ttl = osv.DefaultCacheQueryTTL
+	}
+
+	return osv.NewCache(dir, ttl)
+}
+
+// newExtractionCache sets up the on-disk cache for extracted lockfiles,
+// using the default directory when one is not provided
+func newExtractionCache(dir string) (*lockfile.Cache, error) {
+	if dir == "" {
+		var err error
+		dir, err = lockfile.DefaultCacheDir()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return lockfile.NewCache(dir)
+}
+
+func makeLicensesRequests(ctx context.Context, userAgent string, packages []scannedPackage) ([][]models.License, error) {
 	queries := make([]*depsdevpb.GetVersionRequest, len(packages))
 	for i, pkg := range packages {
 		system, ok := depsdev.System[pkg.Ecosystem]
@@ -1009,7 +1693,7 @@ func makeLicensesRequests(packages []scannedPackage) ([][]models.License, error)
 		}
 		queries[i] = depsdev.VersionQuery(system, pkg.Name, pkg.Version)
 	}
-	licenses, err := depsdev.MakeVersionRequests(queries)
+	licenses, err := depsdev.MakeVersionRequestsWithUserAgent(ctx, userAgent, queries)
 	if err != nil {
 		return nil, fmt.Errorf("%w: deps.dev query failed: %w", ErrAPIFailed, err)
 	}
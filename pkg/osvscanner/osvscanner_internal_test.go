@@ -1,11 +1,16 @@
 package osvscanner
 
 import (
+	"archive/zip"
+	"context"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/osv-scanner/internal/archive"
 	"github.com/google/osv-scanner/internal/testutility"
 	"github.com/google/osv-scanner/pkg/config"
 	"github.com/google/osv-scanner/pkg/models"
@@ -59,6 +64,46 @@ func Test_filterResults(t *testing.T) {
 	}
 }
 
+func Test_filterResults_ScopedIgnores(t *testing.T) {
+	t.Parallel()
+
+	vulnerable := func(path, name string) models.PackageSource {
+		return models.PackageSource{
+			Source: models.SourceInfo{Path: path, Type: "lockfile"},
+			Packages: []models.PackageVulns{{
+				Package:         models.PackageInfo{Name: name, Version: "1.0.0", Ecosystem: "npm"},
+				Vulnerabilities: []models.Vulnerability{{ID: "GHSA-1", Aliases: []string{"CVE-2024-1"}}},
+				Groups:          []models.GroupInfo{{IDs: []string{"GHSA-1"}, Aliases: []string{"CVE-2024-1", "GHSA-1"}}},
+			}},
+		}
+	}
+
+	configManager := config.ConfigManager{
+		OverrideConfig: &config.Config{
+			LoadPath: "/app/osv-scanner.toml",
+			IgnoredVulns: []config.IgnoreEntry{
+				// ignoring the CVE also ignores the advisories that it is an alias of
+				{ID: "CVE-2024-1", Paths: []string{"services/api"}},
+				{ID: "GHSA-1", Package: "react"},
+			},
+		},
+	}
+
+	results := models.VulnerabilityResults{Results: []models.PackageSource{
+		vulnerable("/app/services/api/package-lock.json", "lodash"),
+		vulnerable("/app/services/web/package-lock.json", "lodash"),
+		vulnerable("/app/services/web/yarn.lock", "react"),
+	}}
+
+	if filtered := filterResults(&reporter.VoidReporter{}, &results, &configManager, false); filtered != 2 {
+		t.Errorf("filterResults() = %v, want %v", filtered, 2)
+	}
+
+	if len(results.Results) != 1 || results.Results[0].Source.Path != "/app/services/web/package-lock.json" {
+		t.Errorf("filterResults() left unexpected sources: %+v", results.Results)
+	}
+}
+
 func Test_scanGit(t *testing.T) {
 	t.Parallel()
 
@@ -111,3 +156,123 @@ func Test_scanGit(t *testing.T) {
 		t.Errorf("can't find .git folder")
 	}
 }
+
+func Test_scanDir_PathFilter(t *testing.T) {
+	t.Parallel()
+
+	dir := makeSyntheticTree(t, 200)
+	configManager := &config.ConfigManager{ConfigMap: make(map[string]config.Config)}
+
+	tests := []struct {
+		name    string
+		actions ScannerActions
+		want    int
+	}{
+		{
+			name:    "no_filters",
+			actions: ScannerActions{},
+			want:    40,
+		},
+		{
+			name:    "exclude_directory",
+			actions: ScannerActions{ExcludePaths: []string{"group-00", "group-05/**"}},
+			want:    20,
+		},
+		{
+			name:    "include_directory",
+			actions: ScannerActions{IncludePaths: []string{"group-00/**"}},
+			want:    10,
+		},
+		{
+			name:    "exclude_for_other_parser",
+			actions: ScannerActions{ExcludePaths: []string{"requirements.txt:group-00"}},
+			want:    40,
+		},
+		{
+			name:    "exclude_for_parser",
+			actions: ScannerActions{ExcludePaths: []string{"package-lock.json:group-00"}},
+			want:    30,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			pathFilter, err := newPathFilter(&reporter.VoidReporter{}, tt.actions, configManager, dir)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			pkgs, _, err := scanDir(context.Background(), &reporter.VoidReporter{}, dir, true, true, false, false, false, initializeEnabledParsers(nil), pathFilter, 4, nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(pkgs) != tt.want {
+				t.Errorf("expected %d packages, got %d", tt.want, len(pkgs))
+			}
+		})
+	}
+}
+
+func Test_scanDir_Archives(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	archivePath := filepath.Join(dir, "release.zip")
+
+	f, err := os.Create(archivePath)
+	if err != nil {
+		t.Fatalf("could not create archive: %v", err)
+	}
+
+	w := zip.NewWriter(f)
+	for name, content := range map[string]string{
+		"app/package-lock.json": fmt.Sprintf(syntheticLockfile, 1),
+		"app/package.json":      `{ "dependencies": { "wrappy": "^1.0.0" } }`,
+		// files that cannot be extracted are not decompressed, so do not
+		// count towards the size limits
+		"bin/server": strings.Repeat("\x00", int(archive.DefaultLimits.MaxFileSize)+1),
+	} {
+		entry, err := w.Create(name)
+		if err != nil {
+			t.Fatalf("could not create archive entry: %v", err)
+		}
+
+		if _, err := entry.Write([]byte(content)); err != nil {
+			t.Fatalf("could not write archive entry: %v", err)
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatalf("could not write archive: %v", err)
+	}
+	f.Close()
+
+	pkgs, _, err := scanDir(context.Background(), &reporter.VoidReporter{}, dir, true, true, false, false, false, initializeEnabledParsers(nil), nil, 4, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(pkgs) != 0 {
+		t.Errorf("expected archives to not be scanned unless enabled, got %d packages", len(pkgs))
+	}
+
+	pkgs, _, err = scanDir(context.Background(), &reporter.VoidReporter{}, dir, true, true, false, false, true, initializeEnabledParsers(nil), nil, 4, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(pkgs) != 1 {
+		t.Fatalf("expected 1 package, got %d", len(pkgs))
+	}
+
+	if want := archivePath + "!/app/package-lock.json"; pkgs[0].Source.Path != want {
+		t.Errorf("expected package to be from %s, got %s", want, pkgs[0].Source.Path)
+	}
+
+	if pkgs[0].NameLocation == nil {
+		t.Errorf("expected package to be matched against the package.json in the archive")
+	}
+}
@@ -0,0 +1,181 @@
+package osvscanner
+
+import (
+	"context"
+	"runtime"
+	"sync"
+
+	"github.com/google/osv-scanner/pkg/models"
+	"github.com/google/osv-scanner/pkg/reporter"
+)
+
+// bufferedReporter records what is reported so that it can be replayed
+// later, allowing work that is done in parallel to be reported in order
+type bufferedReporter struct {
+	calls      []func(r reporter.Reporter)
+	hasErrored bool
+}
+
+var _ reporter.Reporter = &bufferedReporter{}
+
+func (r *bufferedReporter) Errorf(format string, a ...any) {
+	r.hasErrored = true
+	r.calls = append(r.calls, func(r reporter.Reporter) { r.Errorf(format, a...) })
+}
+
+func (r *bufferedReporter) HasErrored() bool {
+	return r.hasErrored
+}
+
+func (r *bufferedReporter) Warnf(format string, a ...any) {
+	r.calls = append(r.calls, func(r reporter.Reporter) { r.Warnf(format, a...) })
+}
+
+func (r *bufferedReporter) Infof(format string, a ...any) {
+	r.calls = append(r.calls, func(r reporter.Reporter) { r.Infof(format, a...) })
+}
+
+func (r *bufferedReporter) Verbosef(format string, a ...any) {
+	r.calls = append(r.calls, func(r reporter.Reporter) { r.Verbosef(format, a...) })
+}
+
+func (r *bufferedReporter) PrintResult(vulnResult *models.VulnerabilityResults) error {
+	r.calls = append(r.calls, func(r reporter.Reporter) { _ = r.PrintResult(vulnResult) })
+
+	return nil
+}
+
+// replay reports everything that has been recorded to the given reporter
+func (r *bufferedReporter) replay(to reporter.Reporter) {
+	for _, call := range r.calls {
+		call(to)
+	}
+	r.calls = nil
+}
+
+// scanTask is a piece of work found while walking a directory, such as
+// extracting a lockfile, that can be done in parallel with other tasks
+type scanTask struct {
+	run func(r reporter.Reporter) ([]scannedPackage, []models.ScannedArtifact)
+
+	reporter  bufferedReporter
+	packages  []scannedPackage
+	artifacts []models.ScannedArtifact
+	done      bool
+}
+
+// parallelScan runs the tasks that are added to it with a bounded number of
+// workers, collecting their results (and what they report) in the order that
+// the tasks were added regardless of the order in which they finish, so that
+// the output is the same as if the tasks had been run one after another
+type parallelScan struct {
+	r     reporter.Reporter
+	tasks chan *scanTask
+	wg    sync.WaitGroup
+
+	mu        sync.Mutex
+	queue     []*scanTask
+	current   *scanTask
+	packages  []scannedPackage
+	artifacts []models.ScannedArtifact
+}
+
+// defaultParallelism is the number of workers used when none is specified
+func defaultParallelism() int {
+	return runtime.NumCPU()
+}
+
+func newParallelScan(ctx context.Context, r reporter.Reporter, parallelism int) *parallelScan {
+	if parallelism <= 0 {
+		parallelism = defaultParallelism()
+	}
+
+	s := &parallelScan{r: r, tasks: make(chan *scanTask, parallelism)}
+
+	for range parallelism {
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+
+			for task := range s.tasks {
+				// skip any remaining work once the scan is cancelled
+				if ctx.Err() == nil {
+					task.packages, task.artifacts = task.run(&task.reporter)
+				}
+				s.complete(task)
+			}
+		}()
+	}
+
+	return s
+}
+
+// add queues the given work to be run by the next available worker
+func (s *parallelScan) add(run func(r reporter.Reporter) ([]scannedPackage, []models.ScannedArtifact)) {
+	task := &scanTask{run: run}
+
+	s.mu.Lock()
+	s.finishCurrent()
+	s.queue = append(s.queue, task)
+	s.mu.Unlock()
+
+	s.tasks <- task
+}
+
+// reporter returns a reporter for reporting things in order with the tasks,
+// which must not be used concurrently with adding tasks
+func (s *parallelScan) reporter() reporter.Reporter {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.current == nil {
+		s.current = &scanTask{}
+		s.queue = append(s.queue, s.current)
+	}
+
+	return &s.current.reporter
+}
+
+// finishCurrent marks what has been reported outside of tasks since the
+// last task was added as done, so that it can be flushed
+func (s *parallelScan) finishCurrent() {
+	if s.current != nil {
+		s.current.done = true
+		s.current = nil
+	}
+}
+
+func (s *parallelScan) complete(task *scanTask) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	task.done = true
+	s.flush()
+}
+
+// flush collects the results of the tasks at the front of the queue that
+// are done, stopping at the first one that is still in progress
+func (s *parallelScan) flush() {
+	for len(s.queue) > 0 && s.queue[0].done {
+		task := s.queue[0]
+		s.queue = s.queue[1:]
+
+		task.reporter.replay(s.r)
+		s.packages = append(s.packages, task.packages...)
+		s.artifacts = append(s.artifacts, task.artifacts...)
+	}
+}
+
+// wait waits for all the tasks to be done, returning their results
+func (s *parallelScan) wait() ([]scannedPackage, []models.ScannedArtifact) {
+	close(s.tasks)
+	s.wg.Wait()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.finishCurrent()
+	s.flush()
+
+	return s.packages, s.artifacts
+}
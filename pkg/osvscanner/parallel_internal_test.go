@@ -0,0 +1,130 @@
+package osvscanner
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/osv-scanner/pkg/reporter"
+)
+
+const syntheticLockfile = `{
+  "name": "my-library",
+  "lockfileVersion": 2,
+  "requires": true,
+  "packages": {
+    "": {
+      "dependencies": { "wrappy": "^1.0.0" }
+    },
+    "node_modules/wrappy": {
+      "version": "1.0.%d"
+    }
+  }
+}
+`
+
+// makeSyntheticTree creates a directory tree with the given number of
+// directories, some of which have lockfiles and all of which have other files
+func makeSyntheticTree(tb testing.TB, dirs int) string {
+	tb.Helper()
+
+	root := tb.TempDir()
+
+	for i := range dirs {
+		dir := filepath.Join(root, fmt.Sprintf("group-%02d", i%20), fmt.Sprintf("project-%04d", i))
+
+		if err := os.MkdirAll(filepath.Join(dir, "src"), 0750); err != nil {
+			tb.Fatalf("could not create directory: %v", err)
+		}
+
+		files := map[string]string{
+			"README.md":   "# project\n",
+			"src/main.js": "console.log('hello world')\n",
+		}
+
+		if i%5 == 0 {
+			files["package-lock.json"] = fmt.Sprintf(syntheticLockfile, i)
+		}
+
+		for name, content := range files {
+			if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+				tb.Fatalf("could not write file: %v", err)
+			}
+		}
+	}
+
+	return root
+}
+
+func scanSyntheticTree(tb testing.TB, dir string, parallelism int) ([]scannedPackage, string) {
+	tb.Helper()
+
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	r := reporter.NewJSONReporter(stdout, stderr, reporter.VerboseLevel)
+
+	pkgs, _, err := scanDir(context.Background(), r, dir, true, true, false, false, false, initializeEnabledParsers(nil), nil, parallelism, nil)
+	if err != nil {
+		tb.Fatalf("unexpected error: %v", err)
+	}
+
+	return pkgs, stderr.String()
+}
+
+func Test_scanDir_Parallelism(t *testing.T) {
+	t.Parallel()
+
+	dir := makeSyntheticTree(t, 200)
+
+	wantPkgs, wantOutput := scanSyntheticTree(t, dir, 1)
+
+	if len(wantPkgs) != 40 {
+		t.Fatalf("expected 40 packages, got %d", len(wantPkgs))
+	}
+
+	for _, parallelism := range []int{2, 8, 32} {
+		gotPkgs, gotOutput := scanSyntheticTree(t, dir, parallelism)
+
+		if diff := cmp.Diff(wantPkgs, gotPkgs); diff != "" {
+			t.Errorf("scanDir() with parallelism %d returned different packages (-want +got):\n%s", parallelism, diff)
+		}
+
+		if diff := cmp.Diff(wantOutput, gotOutput); diff != "" {
+			t.Errorf("scanDir() with parallelism %d reported differently (-want +got):\n%s", parallelism, diff)
+		}
+	}
+}
+
+func Test_scanDir_Cancelled(t *testing.T) {
+	t.Parallel()
+
+	dir := makeSyntheticTree(t, 20)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, _, err := scanDir(ctx, &reporter.VoidReporter{}, dir, true, true, false, false, false, initializeEnabledParsers(nil), nil, 4, nil)
+	if err == nil {
+		t.Errorf("expected an error")
+	}
+}
+
+func BenchmarkScanDir(b *testing.B) {
+	dir := makeSyntheticTree(b, 2000)
+	enabledParsers := initializeEnabledParsers(nil)
+
+	for _, parallelism := range []int{1, 4, 16} {
+		b.Run(fmt.Sprintf("parallelism=%d", parallelism), func(b *testing.B) {
+			for range b.N {
+				_, _, err := scanDir(context.Background(), &reporter.VoidReporter{}, dir, true, true, false, false, false, enabledParsers, nil, parallelism, nil)
+				if err != nil {
+					b.Fatalf("unexpected error: %v", err)
+				}
+			}
+		})
+	}
+}
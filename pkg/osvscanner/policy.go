@@ -0,0 +1,305 @@
+package osvscanner
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/osv-scanner/internal/pathfilter"
+	"github.com/google/osv-scanner/internal/utility/severity"
+	"github.com/google/osv-scanner/pkg/config"
+	"github.com/google/osv-scanner/pkg/models"
+	"github.com/google/osv-scanner/pkg/reporter"
+)
+
+// policySeverities are the ratings that policies can match on
+var policySeverities = []string{"NONE", "LOW", "MEDIUM", "HIGH", "CRITICAL", "UNKNOWN"}
+
+// policy is a policy from a config that is ready to be evaluated
+type policy struct {
+	config.PolicyEntry
+
+	paths []pathfilter.Pattern
+	// config is the config that the policy is from, with index being where it is in it
+	config *config.Config
+	index  int
+}
+
+// compilePolicies validates the policies of the config, returning them in
+// the order they are evaluated in
+func compilePolicies(cfg config.Config) ([]policy, error) {
+	policies := make([]policy, 0, len(cfg.Policies))
+
+	for i, entry := range cfg.Policies {
+		invalid := func(format string, a ...any) error {
+			return fmt.Errorf("invalid policy %d in %s: %s", i+1, cfg.LoadPath, fmt.Sprintf(format, a...))
+		}
+
+		switch models.PolicyAction(entry.Action) {
+		case models.PolicyActionFail, models.PolicyActionWarn, models.PolicyActionIgnore:
+		default:
+			return nil, invalid("action %q must be one of fail, warn, ignore", entry.Action)
+		}
+
+		for _, rating := range entry.Severities {
+			if !slices.Contains(policySeverities, strings.ToUpper(rating)) {
+				return nil, invalid("severity %q must be one of %s", rating, strings.ToLower(strings.Join(policySeverities, ", ")))
+			}
+		}
+
+		for _, name := range entry.Packages {
+			if _, err := path.Match(name, ""); err != nil {
+				return nil, invalid("package %q is not a valid pattern", name)
+			}
+		}
+
+		p := policy{PolicyEntry: entry, config: &cfg, index: i}
+
+		for _, glob := range entry.Paths {
+			pattern, err := pathfilter.Compile(glob)
+			if err != nil {
+				return nil, invalid("%v", err)
+			}
+
+			p.paths = append(p.paths, pattern)
+		}
+
+		policies = append(policies, p)
+	}
+
+	return policies, nil
+}
+
+// groupRating returns the rating of the highest severity of the group,
+// which is its environmental score if it has one
+func groupRating(group models.GroupInfo) string {
+	score, err := strconv.ParseFloat(group.Severity(), 64)
+	if err != nil {
+		return severity.Rating(-1)
+	}
+
+	return severity.Rating(score)
+}
+
+// groupVulnerabilities returns the vulnerabilities of the package that are in the group
+func groupVulnerabilities(pkg models.PackageVulns, group models.GroupInfo) []models.Vulnerability {
+	var vulns []models.Vulnerability
+
+	for _, vuln := range pkg.Vulnerabilities {
+		if slices.Contains(group.IDs, vuln.ID) {
+			vulns = append(vulns, vuln)
+		}
+	}
+
+	return vulns
+}
+
+// isFixable reports if any of the vulnerabilities of the group have been
+// fixed in a version of the package
+func isFixable(pkg models.PackageVulns, group models.GroupInfo) bool {
+	affected := models.Package{Ecosystem: models.Ecosystem(pkg.Package.Ecosystem), Name: pkg.Package.Name}
+
+	for _, vuln := range groupVulnerabilities(pkg, group) {
+		if len(vuln.FixedVersions()[affected]) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// publishedAt returns when the first of the vulnerabilities of the group was
+// published, which is zero if none of them say
+func publishedAt(pkg models.PackageVulns, group models.GroupInfo) time.Time {
+	var published time.Time
+
+	for _, vuln := range groupVulnerabilities(pkg, group) {
+		if !vuln.Published.IsZero() && (published.IsZero() || vuln.Published.Before(published)) {
+			published = vuln.Published
+		}
+	}
+
+	return published
+}
+
+// sourceFilePath returns the path of the source file, which is relative to
+// the directory that was scanned if the results have paths relative to it
+func sourceFilePath(source models.SourceInfo) string {
+	if !filepath.IsAbs(source.Path) && source.ScanPath != "" {
+		return filepath.Join(source.ScanPath, source.Path)
+	}
+
+	return source.Path
+}
+
+// matches reports if the group of vulnerabilities meets all of the
+// conditions of the policy
+func (p policy) matches(source models.SourceInfo, pkg models.PackageVulns, group models.GroupInfo, now time.Time) bool {
+	if len(p.Ecosystems) > 0 {
+		ecosystem, _, _ := strings.Cut(pkg.Package.Ecosystem, ":")
+
+		if !slices.ContainsFunc(p.Ecosystems, func(e string) bool { return strings.EqualFold(e, ecosystem) }) {
+			return false
+		}
+	}
+
+	if len(p.Packages) > 0 && !slices.ContainsFunc(p.Packages, func(name string) bool {
+		matched, _ := path.Match(name, pkg.Package.Name)
+		return matched
+	}) {
+		return false
+	}
+
+	if len(p.Severities) > 0 {
+		rating := groupRating(group)
+
+		if !slices.ContainsFunc(p.Severities, func(s string) bool { return strings.EqualFold(s, rating) }) {
+			return false
+		}
+	}
+
+	if p.Dev != nil && *p.Dev != (pkg.Metadata[models.IsDevDependencyMetadata] == "true") {
+		return false
+	}
+
+	if p.Direct != nil && *p.Direct != (pkg.Metadata[models.IsDirectDependencyMetadata] == "true") {
+		return false
+	}
+
+	if p.Fixable != nil && *p.Fixable != isFixable(pkg, group) {
+		return false
+	}
+
+	if p.OlderThan > 0 {
+		published := publishedAt(pkg, group)
+
+		if published.IsZero() || now.Sub(published) < time.Duration(p.OlderThan) {
+			return false
+		}
+	}
+
+	if len(p.paths) > 0 {
+		rel, ok := p.config.PolicyRelativePath(p.index, sourceFilePath(source))
+
+		if !ok || !slices.ContainsFunc(p.paths, func(pattern pathfilter.Pattern) bool { return pattern.Match(rel) }) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// applyPolicies evaluates the policies of the config for each source against
+// its groups of vulnerabilities, recording the decision of the first policy
+// that matches each group and removing those that are ignored.
+// Returns the number of vulnerabilities that were ignored.
+func applyPolicies(r reporter.Reporter, results *models.VulnerabilityResults, configManager *config.ConfigManager, showAllPackages bool) (int, error) {
+	compiled := make(map[string][]policy)
+	now := time.Now()
+	ignored := 0
+
+	sources := make([]models.PackageSource, 0, len(results.Results))
+
+	for _, source := range results.Results {
+		cfg := configManager.Get(r, source.Source.Path)
+
+		if len(cfg.Policies) == 0 {
+			sources = append(sources, source)
+
+			continue
+		}
+
+		policies, ok := compiled[cfg.LoadPath]
+		if !ok {
+			var err error
+			if policies, err = compilePolicies(cfg); err != nil {
+				return ignored, err
+			}
+
+			compiled[cfg.LoadPath] = policies
+		}
+
+		packages := make([]models.PackageVulns, 0, len(source.Packages))
+
+		for _, pkg := range source.Packages {
+			hadVulns := len(pkg.Vulnerabilities) > 0
+			ignoredIDs := make(map[string]bool)
+			groups := make([]models.GroupInfo, 0, len(pkg.Groups))
+
+			for _, group := range pkg.Groups {
+				i := slices.IndexFunc(policies, func(p policy) bool { return p.matches(source.Source, pkg, group, now) })
+				if i == -1 {
+					groups = append(groups, group)
+
+					continue
+				}
+
+				decision := &models.PolicyDecision{Action: models.PolicyAction(policies[i].Action), Reason: policies[i].Reason}
+				description := fmt.Sprintf("%s affecting %s@%s in %s", strings.Join(group.IDs, ", "), pkg.Package.Name, pkg.Package.Version, source.Source.Path)
+
+				switch decision.Action {
+				case models.PolicyActionIgnore:
+					r.Verbosef("%s has been ignored by policy%s\n", description, policyReason(decision))
+					for _, id := range group.Aliases {
+						ignoredIDs[id] = true
+					}
+					for _, id := range group.IDs {
+						ignoredIDs[id] = true
+					}
+
+					continue
+				case models.PolicyActionWarn:
+					r.Warnf("Warning: %s is only a warning by policy%s\n", description, policyReason(decision))
+				case models.PolicyActionFail:
+				}
+
+				group.Policy = decision
+				groups = append(groups, group)
+			}
+
+			vulns := make([]models.Vulnerability, 0, len(pkg.Vulnerabilities))
+			for _, vuln := range pkg.Vulnerabilities {
+				if !ignoredIDs[vuln.ID] {
+					vulns = append(vulns, vuln)
+				}
+			}
+
+			ignored += len(pkg.Vulnerabilities) - len(vulns)
+
+			if len(vulns) == 0 {
+				vulns, groups = nil, nil
+			}
+
+			pkg.Vulnerabilities = vulns
+			pkg.Groups = groups
+
+			// packages are only dropped if they were only included for their vulnerabilities
+			if hadVulns && len(vulns) == 0 && len(pkg.LicenseViolations) == 0 && !showAllPackages {
+				continue
+			}
+
+			packages = append(packages, pkg)
+		}
+
+		if len(packages) > 0 {
+			source.Packages = packages
+			sources = append(sources, source)
+		}
+	}
+
+	results.Results = sources
+
+	return ignored, nil
+}
+
+func policyReason(decision *models.PolicyDecision) string {
+	if decision.Reason == "" {
+		return ""
+	}
+
+	return ": " + decision.Reason
+}
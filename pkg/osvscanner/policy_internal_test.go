@@ -0,0 +1,176 @@
+package osvscanner
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/osv-scanner/pkg/config"
+	"github.com/google/osv-scanner/pkg/models"
+	"github.com/google/osv-scanner/pkg/reporter"
+)
+
+// policyTestResults returns results with a vulnerability for each of the packages
+func policyTestResults(pkgs ...models.PackageVulns) models.VulnerabilityResults {
+	for i := range pkgs {
+		id := "GHSA-" + pkgs[i].Package.Name
+
+		pkgs[i].Vulnerabilities = []models.Vulnerability{{
+			ID:        id,
+			Published: time.Now().AddDate(-3, 0, 0),
+			Affected: []models.Affected{{
+				Package: models.Package{Ecosystem: models.Ecosystem(pkgs[i].Package.Ecosystem), Name: pkgs[i].Package.Name},
+				Ranges:  []models.Range{{Type: models.RangeEcosystem, Events: []models.Event{{Introduced: "0"}, {Fixed: "9.9.9"}}}},
+			}},
+		}}
+		pkgs[i].Groups = []models.GroupInfo{{IDs: []string{id}, Aliases: []string{id}, MaxSeverity: "7.5"}}
+	}
+
+	return models.VulnerabilityResults{
+		Results: []models.PackageSource{{
+			Source:   models.SourceInfo{Path: "/app/services/api/package-lock.json", Type: "lockfile"},
+			Packages: pkgs,
+		}},
+	}
+}
+
+func policyTestPackage(name string, metadata models.PackageMetadata) models.PackageVulns {
+	return models.PackageVulns{
+		Package:  models.PackageInfo{Name: name, Version: "1.0.0", Ecosystem: "npm"},
+		Metadata: metadata,
+	}
+}
+
+func policyDecisions(results models.VulnerabilityResults) map[string]string {
+	decisions := make(map[string]string)
+
+	for _, source := range results.Results {
+		for _, pkg := range source.Packages {
+			for _, group := range pkg.Groups {
+				decision := "none"
+				if group.Policy != nil {
+					decision = string(group.Policy.Action)
+				}
+				decisions[pkg.Package.Name] = decision
+			}
+		}
+	}
+
+	return decisions
+}
+
+func Test_policy_matches(t *testing.T) {
+	t.Parallel()
+
+	yes, no := true, false
+	source := models.SourceInfo{Path: "/app/services/api/package-lock.json"}
+	pkg := policyTestResults(policyTestPackage("lodash", models.PackageMetadata{models.IsDevDependencyMetadata: "true"})).Results[0].Packages[0]
+	group := pkg.Groups[0]
+
+	tests := []struct {
+		name  string
+		entry config.PolicyEntry
+		want  bool
+	}{
+		{name: "no conditions", want: true},
+		{name: "ecosystem", entry: config.PolicyEntry{Ecosystems: []string{"NPM"}}, want: true},
+		{name: "other ecosystem", entry: config.PolicyEntry{Ecosystems: []string{"PyPI"}}, want: false},
+		{name: "package pattern", entry: config.PolicyEntry{Packages: []string{"lo*"}}, want: true},
+		{name: "other package", entry: config.PolicyEntry{Packages: []string{"react"}}, want: false},
+		{name: "severity", entry: config.PolicyEntry{Severities: []string{"high", "critical"}}, want: true},
+		{name: "other severity", entry: config.PolicyEntry{Severities: []string{"low", "unknown"}}, want: false},
+		{name: "dev", entry: config.PolicyEntry{Dev: &yes}, want: true},
+		{name: "not dev", entry: config.PolicyEntry{Dev: &no}, want: false},
+		{name: "not direct", entry: config.PolicyEntry{Direct: &no}, want: true},
+		{name: "fixable", entry: config.PolicyEntry{Fixable: &yes}, want: true},
+		{name: "not fixable", entry: config.PolicyEntry{Fixable: &no}, want: false},
+		{name: "older than", entry: config.PolicyEntry{OlderThan: config.Age(2 * 365 * 24 * time.Hour)}, want: true},
+		{name: "not older than", entry: config.PolicyEntry{OlderThan: config.Age(4 * 365 * 24 * time.Hour)}, want: false},
+		{name: "path", entry: config.PolicyEntry{Paths: []string{"services/**"}}, want: true},
+		{name: "other path", entry: config.PolicyEntry{Paths: []string{"tools/**"}}, want: false},
+		{name: "all conditions", entry: config.PolicyEntry{Ecosystems: []string{"npm"}, Dev: &yes, Fixable: &yes, Paths: []string{"services"}}, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			tt.entry.Action = string(models.PolicyActionFail)
+
+			policies, err := compilePolicies(config.Config{LoadPath: "/app/osv-scanner.toml", Policies: []config.PolicyEntry{tt.entry}})
+			if err != nil {
+				t.Fatalf("compilePolicies() error = %v", err)
+			}
+
+			if got := policies[0].matches(source, pkg, group, time.Now()); got != tt.want {
+				t.Errorf("matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_compilePolicies_Invalid(t *testing.T) {
+	t.Parallel()
+
+	for _, entry := range []config.PolicyEntry{
+		{},
+		{Action: "block"},
+		{Action: "fail", Severities: []string{"severe"}},
+		{Action: "fail", Packages: []string{"[a-"}},
+	} {
+		if _, err := compilePolicies(config.Config{Policies: []config.PolicyEntry{entry}}); err == nil {
+			t.Errorf("expected %+v to be invalid", entry)
+		}
+	}
+}
+
+func Test_applyPolicies(t *testing.T) {
+	t.Parallel()
+
+	yes := true
+	configManager := &config.ConfigManager{
+		OverrideConfig: &config.Config{
+			LoadPath: "/app/osv-scanner.toml",
+			Policies: []config.PolicyEntry{
+				{Action: "ignore", Reason: "not shipped", Packages: []string{"eslint*"}},
+				{Action: "warn", Dev: &yes},
+				{Action: "fail", Packages: []string{"lodash"}},
+			},
+		},
+	}
+
+	results := policyTestResults(
+		policyTestPackage("eslint", nil),
+		policyTestPackage("jest", models.PackageMetadata{models.IsDevDependencyMetadata: "true"}),
+		policyTestPackage("lodash", nil),
+		policyTestPackage("react", nil),
+	)
+
+	ignored, err := applyPolicies(&reporter.VoidReporter{}, &results, configManager, false)
+	if err != nil {
+		t.Fatalf("applyPolicies() error = %v", err)
+	}
+
+	if ignored != 1 {
+		t.Errorf("applyPolicies() ignored %d vulnerabilities, want 1", ignored)
+	}
+
+	want := map[string]string{"jest": "warn", "lodash": "fail", "react": "none"}
+	if diff := cmp.Diff(want, policyDecisions(results)); diff != "" {
+		t.Errorf("applyPolicies() decisions mismatch (-want +got):\n%s", diff)
+	}
+
+	// decisions of policies take precedence over the FailOn threshold
+	if err := resultsError(results, ScannerActions{FailOn: "critical"}); !errors.Is(err, VulnerabilitiesFoundErr) {
+		t.Errorf("resultsError() = %v, want the failing policy to fail the scan", err)
+	}
+
+	warnOnly := policyTestResults(policyTestPackage("jest", models.PackageMetadata{models.IsDevDependencyMetadata: "true"}))
+	if _, err := applyPolicies(&reporter.VoidReporter{}, &warnOnly, configManager, false); err != nil {
+		t.Fatalf("applyPolicies() error = %v", err)
+	}
+
+	if err := resultsError(warnOnly, ScannerActions{}); err != nil {
+		t.Errorf("resultsError() = %v, want warnings to not fail the scan", err)
+	}
+}
@@ -0,0 +1,133 @@
+package osvscanner
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"path"
+	"strings"
+
+	"github.com/google/osv-scanner/internal/pathfilter"
+	"github.com/google/osv-scanner/pkg/lockfile"
+	"github.com/google/osv-scanner/pkg/models"
+	"github.com/google/osv-scanner/pkg/reporter"
+)
+
+// ScanFS performs the osv scanner action like Scan, except that the lockfiles
+// and directories of the actions are read from the given filesystem, such as
+// an embed.FS, a fstest.MapFS or a zip.Reader, rather than the local one.
+//
+// Config files are also loaded from the filesystem, and paths within it are
+// reported as absolute paths, with "/" being the root of the filesystem.
+//
+// Only lockfiles that are supported by the registered parsers can be scanned,
+// so git repositories, SBOMs and the apk-installed, dpkg-status and
+// osv-scanner parsers are not supported.
+func (s *Scanner) ScanFS(ctx context.Context, fsys fs.FS, actions ScannerActions) (models.VulnerabilityResults, error) {
+	if fsys == nil {
+		return models.VulnerabilityResults{}, errors.New("no filesystem to scan")
+	}
+
+	return s.scanWithBaseline(ctx, actions, fsys)
+}
+
+// scanFSLockfile extracts the lockfile at the given path within the filesystem
+func scanFSLockfile(r reporter.Reporter, fsys fs.FS, p string, parseAs string, enabledParsers map[string]bool) ([]scannedPackage, *models.ScannedArtifact, error) {
+	f, err := lockfile.OpenFSDepFile(fsys, p)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+
+	parsedLockfile, err := lockfile.ExtractDeps(f, parseAs, enabledParsers)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	packages := lockfilePackages(r, f.Path(), parseAs, parsedLockfile)
+
+	// the directories of packages are within the filesystem rather than on
+	// disk, so their license notices are not read
+	for i := range packages {
+		packages[i].Dir = ""
+	}
+
+	return packages, parsedLockfile.Artifact, nil
+}
+
+// scanFSDir walks through the given directory within the filesystem, like
+// scanDir, to find and extract any lockfiles
+func scanFSDir(ctx context.Context, r reporter.Reporter, fsys fs.FS, dir string, recursive bool, enabledParsers map[string]bool, pathFilter *pathfilter.Filter, parallelism int) ([]scannedPackage, []models.ScannedArtifact, error) {
+	root := strings.TrimPrefix(path.Clean("/"+dir), "/")
+	if root == "" {
+		root = "."
+	}
+
+	scan := newParallelScan(ctx, r, parallelism)
+
+	err := fs.WalkDir(fsys, root, func(name string, info fs.DirEntry, err error) error {
+		if err != nil {
+			scan.reporter().Infof("Failed to walk %s: %v\n", name, err)
+			return err
+		}
+
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		isRoot := name == root
+
+		// paths are filtered relative to the directory being scanned
+		relPath := name
+		if root != "." {
+			relPath = strings.TrimPrefix(strings.TrimPrefix(name, root), "/")
+		}
+
+		if info.IsDir() {
+			if isRoot {
+				return nil
+			}
+
+			if skip, reason := pathFilter.SkipDir(relPath); skip {
+				scan.reporter().Verbosef("Skipping /%s as %s\n", name, reason)
+				return fs.SkipDir
+			}
+
+			if !recursive || info.Name() == ".git" {
+				return fs.SkipDir
+			}
+
+			return nil
+		}
+
+		extractor, parser := lockfile.FindExtractor("/"+name, "", enabledParsers)
+		if extractor == nil {
+			return nil
+		}
+
+		if skip, reason := pathFilter.SkipFile(relPath, parser); skip && !isRoot {
+			scan.reporter().Verbosef("Skipping /%s as %s\n", name, reason)
+
+			return nil
+		}
+
+		scan.add(func(r reporter.Reporter) ([]scannedPackage, []models.ScannedArtifact) {
+			pkgs, artifact, err := scanFSLockfile(r, fsys, name, "", enabledParsers)
+			if err != nil {
+				r.Warnf("Attempted to scan lockfile but failed: /%s (%v)\n", name, err.Error())
+			}
+
+			if artifact == nil {
+				return pkgs, nil
+			}
+
+			return pkgs, []models.ScannedArtifact{*artifact}
+		})
+
+		return nil
+	})
+
+	scannedPackages, scannedArtifacts := scan.wait()
+
+	return scannedPackages, scannedArtifacts, err
+}
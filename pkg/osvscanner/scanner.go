@@ -0,0 +1,148 @@
+package osvscanner
+
+import (
+	"io/fs"
+	"log/slog"
+	"net/http"
+
+	"github.com/google/osv-scanner/internal/version"
+	"github.com/google/osv-scanner/pkg/config"
+	"github.com/google/osv-scanner/pkg/lockfile"
+	"github.com/google/osv-scanner/pkg/osv"
+	"github.com/google/osv-scanner/pkg/reporter"
+)
+
+// Scanner scans for packages and checks them against the OSV database.
+//
+// A Scanner does not modify any process-wide state, so it is safe to run
+// several scans at once, both with the same Scanner and with different ones.
+// If a reporter is given, it must also be safe for concurrent use to do so.
+type Scanner struct {
+	reporter       reporter.Reporter
+	httpClient     *http.Client
+	apiClient      *osv.Client
+	userAgent      string
+	enabledParsers []string
+	configManager  *config.ConfigManager
+	packageCaches  *lockfile.PackageCaches
+}
+
+// ScannerOption configures a Scanner
+type ScannerOption func(*Scanner)
+
+// WithReporter sets the reporter that the scanner outputs information to,
+// which otherwise is discarded
+func WithReporter(r reporter.Reporter) ScannerOption {
+	return func(s *Scanner) {
+		s.reporter = r
+	}
+}
+
+// WithLogger sets the reporter that the scanner outputs information to as
+// one that sends it to the given structured logger
+func WithLogger(logger *slog.Logger) ScannerOption {
+	return WithReporter(reporter.NewSlogReporter(logger))
+}
+
+// WithHTTPClient sets the client used for the requests made when scanning,
+// unless the API settings require their own transport (i.e. a proxy)
+func WithHTTPClient(client *http.Client) ScannerOption {
+	return func(s *Scanner) {
+		s.httpClient = client
+	}
+}
+
+// WithAPIClient sets the client used to query the OSV API, in which case
+// the API settings of the scan actions and config files are not used
+func WithAPIClient(client *osv.Client) ScannerOption {
+	return func(s *Scanner) {
+		s.apiClient = client
+	}
+}
+
+// WithUserAgent sets the user agent that the scanner identifies itself with
+func WithUserAgent(userAgent string) ScannerOption {
+	return func(s *Scanner) {
+		s.userAgent = userAgent
+	}
+}
+
+// WithEnabledParsers sets the lockfile parsers that are used when the scan
+// actions do not specify any, which otherwise defaults to all of them
+func WithEnabledParsers(parsers []string) ScannerOption {
+	return func(s *Scanner) {
+		s.enabledParsers = parsers
+	}
+}
+
+// WithConfigManager sets the config manager that the configs for each scan
+// are loaded with, so they can be shared between scans
+func WithConfigManager(configManager *config.ConfigManager) ScannerOption {
+	return func(s *Scanner) {
+		s.configManager = configManager
+	}
+}
+
+// WithPackageCaches sets where the NuGet and Maven packages that have been
+// downloaded are looked for when reading the licenses they declare, which
+// otherwise are the default locations of the NuGet and Maven caches
+func WithPackageCaches(caches lockfile.PackageCaches) ScannerOption {
+	return func(s *Scanner) {
+		s.packageCaches = &caches
+	}
+}
+
+// NewScanner creates a Scanner with the given options
+func NewScanner(opts ...ScannerOption) *Scanner {
+	s := &Scanner{}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	if s.reporter == nil {
+		s.reporter = &reporter.VoidReporter{}
+	}
+
+	if s.userAgent == "" {
+		s.userAgent = osv.RequestUserAgent
+	}
+
+	if s.packageCaches == nil {
+		caches := lockfile.DefaultPackageCaches()
+		s.packageCaches = &caches
+	}
+
+	if s.userAgent == "" {
+		s.userAgent = "osv-scanner-api_v" + version.OSVVersion
+	}
+
+	return s
+}
+
+// newConfigManager returns the config manager to use for a scan, which is
+// only shared with other scans if there is no config override to apply and
+// configs are not being loaded from the given filesystem
+func (s *Scanner) newConfigManager(actions ScannerActions, fsys fs.FS) (*config.ConfigManager, error) {
+	if s.configManager != nil && actions.ConfigOverridePath == "" && fsys == nil {
+		return s.configManager, nil
+	}
+
+	configManager := &config.ConfigManager{
+		DefaultConfig: config.Config{},
+		ConfigMap:     make(map[string]config.Config),
+		FS:            fsys,
+	}
+
+	if s.configManager != nil {
+		configManager.DefaultConfig = s.configManager.DefaultConfig
+	}
+
+	if actions.ConfigOverridePath != "" {
+		if err := configManager.UseOverride(actions.ConfigOverridePath); err != nil {
+			return nil, err
+		}
+	}
+
+	return configManager, nil
+}
@@ -0,0 +1,320 @@
+package osvscanner_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/osv-scanner/pkg/lockfile"
+	"github.com/google/osv-scanner/pkg/models"
+	"github.com/google/osv-scanner/pkg/osv"
+	"github.com/google/osv-scanner/pkg/osvscanner"
+)
+
+func TestScanner_ConcurrentScans(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	userAgents := make(map[string]int)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		userAgents[r.Header.Get("User-Agent")]++
+		mu.Unlock()
+
+		var query osv.BatchedQuery
+		if err := json.NewDecoder(r.Body).Decode(&query); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(osv.BatchedResponse{
+			Results: make([]osv.MinimalResponse, len(query.Queries)),
+		})
+	}))
+	defer server.Close()
+
+	scanner := osvscanner.NewScanner(
+		osvscanner.WithHTTPClient(server.Client()),
+		osvscanner.WithUserAgent("my-service/1.0"),
+	)
+
+	lockfiles := []string{
+		"package-lock.json:../lockfile/fixtures/npm/one-package.v1.json",
+		"package-lock.json:../lockfile/fixtures/npm/one-package.v2.json",
+		"package-lock.json:../lockfile/fixtures/npm/one-package-dev.v1.json",
+		"package-lock.json:../lockfile/fixtures/npm/one-package-dev.v2.json",
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(lockfiles))
+
+	for i, lockfile := range lockfiles {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			_, errs[i] = scanner.Scan(context.Background(), osvscanner.ScannerActions{
+				LockfilePaths:   []string{lockfile},
+				APIClientConfig: osv.ClientConfig{BaseURL: server.URL},
+			})
+		}()
+	}
+
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			t.Errorf("unexpected error scanning %s: %v", lockfiles[i], err)
+		}
+	}
+
+	if userAgents["my-service/1.0"] != len(lockfiles) || len(userAgents) != 1 {
+		t.Errorf("expected every request to use the scanner user agent, got %v", userAgents)
+	}
+}
+
+func TestScanner_ScanFS(t *testing.T) {
+	t.Parallel()
+
+	readFixture := func(path string) []byte {
+		t.Helper()
+
+		b, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("could not read fixture: %v", err)
+		}
+
+		return b
+	}
+
+	fsys := fstest.MapFS{
+		"services/api/package-lock.json":      {Data: readFixture("../lockfile/fixtures/package-json/one-package/npm-v2.json")},
+		"services/api/package.json":           {Data: readFixture("../lockfile/fixtures/package-json/one-package/package.json")},
+		"services/api/testdata/Pipfile.lock":  {Data: []byte(`{ "default": { "requests": { "version": "==2.31.0" } } }`)},
+		"services/worker/go.mod":              {Data: []byte("module example.com/worker\n\ngo 1.22\n")},
+		"services/worker/requirements.txt":    {Data: []byte("django==4.2.0\n")},
+		"services/worker/docs/README.md":      {Data: []byte("# worker\n")},
+		"services/worker/vendor/modules.txt":  {Data: []byte("")},
+		"services/worker/osv-scanner.toml":    {Data: []byte("")},
+		"services/worker/some-other-file.txt": {Data: []byte("")},
+	}
+
+	results, err := osvscanner.NewScanner().ScanFS(context.Background(), fsys, osvscanner.ScannerActions{
+		DirectoryPaths: []string{"services"},
+		Recursive:      true,
+		ExcludePaths:   []string{"**/testdata/**"},
+		ExperimentalScannerActions: osvscanner.ExperimentalScannerActions{
+			OnlyPackages: true,
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// files are scanned in parallel, so results can be in any order
+	var sources []string
+	for _, source := range results.Results {
+		sources = append(sources, source.Source.Path)
+	}
+	slices.Sort(sources)
+
+	want := []string{
+		"/services/api/package-lock.json",
+		"/services/worker/go.mod",
+		"/services/worker/requirements.txt",
+	}
+
+	if diff := cmp.Diff(want, sources); diff != "" {
+		t.Errorf("ScanFS() scanned unexpected sources (-want +got):\n%s", diff)
+	}
+
+	matched := false
+	for _, source := range results.Results {
+		if source.Source.Path != "/services/api/package-lock.json" {
+			continue
+		}
+
+		for _, pkg := range source.Packages {
+			for _, location := range pkg.Locations {
+				if location.Name != nil && location.Name.Filename == "/services/api/package.json" {
+					matched = true
+				}
+			}
+		}
+	}
+
+	if !matched {
+		t.Errorf("expected lodash to be matched against the package.json in the filesystem")
+	}
+}
+
+func TestScanner_Scan_Stdin(t *testing.T) {
+	t.Parallel()
+
+	results, err := osvscanner.NewScanner().Scan(context.Background(), osvscanner.ScannerActions{
+		LockfilePaths: []string{"requirements.txt:-"},
+		Stdin:         strings.NewReader("django==4.2.0\nflask==2.3.0\n"),
+		ExperimentalScannerActions: osvscanner.ExperimentalScannerActions{
+			OnlyPackages: true,
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(results.Results) != 1 || results.Results[0].Source.Path != "<stdin>" {
+		t.Fatalf("expected a single result for <stdin>, got %v", results.Results)
+	}
+
+	if len(results.Results[0].Packages) != 2 {
+		t.Errorf("expected two packages, got %d", len(results.Results[0].Packages))
+	}
+
+	for _, lockfilePaths := range [][]string{
+		// there is no filename to infer the parser from
+		{"-"},
+		// stdin can only be read once
+		{"requirements.txt:-", "requirements.txt:-"},
+	} {
+		_, err := osvscanner.NewScanner().Scan(context.Background(), osvscanner.ScannerActions{
+			LockfilePaths: lockfilePaths,
+			Stdin:         strings.NewReader("django==4.2.0\n"),
+			ExperimentalScannerActions: osvscanner.ExperimentalScannerActions{
+				OnlyPackages: true,
+			},
+		})
+		if err == nil {
+			t.Errorf("expected scanning %v to fail", lockfilePaths)
+		}
+	}
+}
+
+func TestScanner_Scan_WithLogger(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelWarn}))
+
+	_, err := osvscanner.NewScanner(osvscanner.WithLogger(logger)).Scan(context.Background(), osvscanner.ScannerActions{
+		LockfilePaths: []string{"go.mod:-"},
+		Stdin:         strings.NewReader("module example.com/app\n\nrequire github.com/elastic/go-elasticsearch master\n"),
+		ExperimentalScannerActions: osvscanner.ExperimentalScannerActions{
+			OnlyPackages: true,
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !strings.Contains(buf.String(), "level=WARN") || !strings.Contains(buf.String(), "github.com/elastic/go-elasticsearch@master is not a canonical version") {
+		t.Errorf("expected a warning about the non-canonical version to be logged, got %q", buf.String())
+	}
+
+	if strings.Contains(buf.String(), "level=INFO") {
+		t.Errorf("expected messages below the logger level to be dropped, got %q", buf.String())
+	}
+}
+
+func TestScanner_Scan_LicenseNotices(t *testing.T) {
+	t.Parallel()
+
+	results, err := osvscanner.NewScanner().Scan(context.Background(), osvscanner.ScannerActions{
+		LockfilePaths: []string{"../lockfile/fixtures/npm/licenses/package-lock.json"},
+		ExperimentalScannerActions: osvscanner.ExperimentalScannerActions{
+			CompareOffline:     true,
+			LocalDBPath:        t.TempDir(),
+			ShowAllPackages:    true,
+			ScanLicenseNotices: true,
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	notices := make(map[string][]models.LicenseNotice)
+	for _, source := range results.Results {
+		for _, pkg := range source.Packages {
+			if len(pkg.Licenses) == 0 {
+				t.Errorf("expected %s to have its licenses", pkg.Package.Name)
+			}
+
+			notices[pkg.Package.Name] = pkg.LicenseNotices
+		}
+	}
+
+	// only packages that are installed have their notices read
+	want := map[string][]models.LicenseNotice{
+		"@scope/manifest-license": nil,
+		"expression":              nil,
+		"legacy-object":           nil,
+		"manifest-licenses": {
+			{Path: "LICENSE", Text: "MIT License\n\nCopyright (c) 2024 Manifest Licenses Authors\n"},
+		},
+		"no-license": nil,
+	}
+
+	if diff := cmp.Diff(want, notices); diff != "" {
+		t.Errorf("unexpected license notices (-want +got):\n%s", diff)
+	}
+}
+
+func TestScanner_Scan_PackageCacheLicenses(t *testing.T) {
+	t.Parallel()
+
+	packagesLock := `{
+  "version": 1,
+  "dependencies": {
+    "net8.0": {
+      "Newtonsoft.Json": { "type": "Direct", "requested": "[13.0.3, )", "resolved": "13.0.3" },
+      "Legacy.Package": { "type": "Direct", "requested": "[1.0.0, )", "resolved": "1.0.0" }
+    }
+  }
+}`
+
+	scanner := osvscanner.NewScanner(osvscanner.WithPackageCaches(lockfile.PackageCaches{
+		NuGet: "../lockfile/fixtures/package-caches/nuget",
+	}))
+
+	results, err := scanner.Scan(context.Background(), osvscanner.ScannerActions{
+		LockfilePaths: []string{"packages.lock.json:-"},
+		Stdin:         strings.NewReader(packagesLock),
+		ExperimentalScannerActions: osvscanner.ExperimentalScannerActions{
+			CompareOffline:      true,
+			LocalDBPath:         t.TempDir(),
+			ShowAllPackages:     true,
+			ScanLicensesSummary: true,
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	licenses := make(map[string][]models.License)
+	for _, source := range results.Results {
+		for _, pkg := range source.Packages {
+			licenses[pkg.Package.Name] = pkg.Licenses
+		}
+	}
+
+	// packages with a license file rather than an expression are still unknown
+	want := map[string][]models.License{
+		"Newtonsoft.Json": {"MIT"},
+		"Legacy.Package":  {"UNKNOWN"},
+	}
+
+	if diff := cmp.Diff(want, licenses); diff != "" {
+		t.Errorf("unexpected licenses (-want +got):\n%s", diff)
+	}
+}
@@ -1,6 +1,7 @@
 package osvscanner
 
 import (
+	"context"
 	"slices"
 	"sort"
 	"strconv"
@@ -43,6 +44,7 @@ func packageHasRangedVersion(scannedPackage scannedPackage) bool {
 // vulnerability information by source location.
 // TODO: This function is getting long, we should refactor it
 func buildVulnerabilityResults(
+	ctx context.Context,
 	r reporter.Reporter,
 	packages []scannedPackage,
 	artifacts []models.ScannedArtifact,
@@ -56,6 +58,7 @@ func buildVulnerabilityResults(
 		Artifacts: artifacts,
 	}
 	groupedBySource := map[models.SourceInfo][]models.PackageVulns{}
+	usedConfigPolicy := false
 	for i, rawPkg := range packages {
 		includePackage := actions.ShowAllPackages
 		var pkg models.PackageVulns
@@ -97,24 +100,42 @@ func buildVulnerabilityResults(
 				pkg.Groups[i].MaxSeverity = output.MaxSeverity(group, pkg)
 			}
 		}
-		if actions.ScanLicensesSummary || len(actions.ScanLicensesAllowlist) > 0 {
-			configToUse := configManager.Get(r, rawPkg.Source.Path)
+
+		configToUse := configManager.Get(r, rawPkg.Source.Path)
+		policy, hasPolicy := actions.licensePolicy(configToUse)
+		if hasPolicy && !actions.hasLicensePolicy() {
+			usedConfigPolicy = true
+		}
+
+		if actions.scansLicenses() || hasPolicy {
+			licenses, licenseSource := packageLicenses(rawPkg, licensesResp, i)
+
 			if override, entry := configToUse.ShouldOverridePackageVersionLicense(pkg.Package.Name, pkg.Package.Version, pkg.Package.Ecosystem); override {
 				overrideLicenses := make([]models.License, len(entry.License.Override))
 				for j, license := range entry.License.Override {
 					overrideLicenses[j] = models.License(license)
 				}
 				r.Infof("overriding license for package %s/%s/%s with %s\n", pkg.Package.Ecosystem, pkg.Package.Name, pkg.Package.Version, strings.Join(entry.License.Override, ","))
-				licensesResp[i] = overrideLicenses
+				licenses, licenseSource = overrideLicenses, models.LicenseSourceOverride
 			}
-			if len(actions.ScanLicensesAllowlist) > 0 {
-				pkg.Licenses = licensesResp[i]
-				for _, license := range pkg.Licenses {
-					lowerLicense := strings.ToLower(string(license))
-					if !slices.ContainsFunc(actions.ScanLicensesAllowlist, func(l string) bool {
-						return strings.ToLower(l) == lowerLicense
-					}) {
-						pkg.LicenseViolations = append(pkg.LicenseViolations, license)
+			if hasPolicy {
+				pkg.Licenses = licenses
+				pkg.LicenseSource = licenseSource
+
+				checked := pkg.Licenses
+				if exempt, exception := configToUse.ShouldExemptPackageLicense(pkg.Package.Name, pkg.Package.Version, pkg.Package.Ecosystem); exempt {
+					r.Infof("exempting licenses of package %s/%s/%s from the license policy%s\n", pkg.Package.Ecosystem, pkg.Package.Name, pkg.Package.Version, exceptionReason(exception))
+					policy.Exempt = exception.Licenses
+					// exceptions without licenses exempt all of the licenses of the package
+					if len(exception.Licenses) == 0 {
+						checked = nil
+					}
+				}
+
+				for _, license := range checked {
+					// only the branches of the license expression that violate the policy are reported
+					for _, violation := range policy.Violations(string(license)) {
+						pkg.LicenseViolations = append(pkg.LicenseViolations, models.License(violation))
 					}
 				}
 				if len(pkg.LicenseViolations) > 0 {
@@ -129,8 +150,13 @@ func buildVulnerabilityResults(
 				pkg.Locations = make([]models.PackageLocations, 0)
 			}
 
-			if actions.ScanLicensesSummary {
-				pkg.Licenses = licensesResp[i]
+			if actions.ScanLicensesSummary || actions.ScanLicenseNotices {
+				pkg.Licenses = licenses
+				pkg.LicenseSource = licenseSource
+			}
+
+			if actions.ScanLicenseNotices && rawPkg.Dir != "" {
+				pkg.LicenseNotices = readLicenseNotices(rawPkg.Dir)
 			}
 		}
 		if includePackage {
@@ -139,7 +165,7 @@ func buildVulnerabilityResults(
 	}
 
 	for source, packages := range groupedBySource {
-		sourceanalysis.Run(r, source, packages, actions.CallAnalysisStates)
+		sourceanalysis.Run(ctx, r, source, packages, actions.CallAnalysisStates)
 		results.Results = append(results.Results, models.PackageSource{
 			Source:   source,
 			Packages: packages,
@@ -154,18 +180,48 @@ func buildVulnerabilityResults(
 		return results.Results[i].Source.Path < results.Results[j].Source.Path
 	})
 
-	if len(actions.ScanLicensesAllowlist) > 0 || actions.ScanLicensesSummary {
+	results.ExperimentalAnalysisConfig.Licenses.FromConfig = usedConfigPolicy
+	if actions.scansLicenses() {
 		results.ExperimentalAnalysisConfig.Licenses.Summary = actions.ScanLicensesSummary
 		allowlist := make([]models.License, len(actions.ScanLicensesAllowlist))
 		for i, l := range actions.ScanLicensesAllowlist {
 			allowlist[i] = models.License(l)
 		}
 		results.ExperimentalAnalysisConfig.Licenses.Allowlist = allowlist
+		for _, l := range actions.ScanLicensesDenylist {
+			results.ExperimentalAnalysisConfig.Licenses.Denylist = append(results.ExperimentalAnalysisConfig.Licenses.Denylist, models.License(l))
+		}
 	}
 
 	return results
 }
 
+// packageLicenses returns the licenses of the package along with where they
+// are from, which is deps.dev if they were retrieved from it (and it knows
+// them), and otherwise the licenses that the package declares locally
+func packageLicenses(pkg scannedPackage, licensesResp [][]models.License, i int) ([]models.License, models.LicenseSource) {
+	var retrieved []models.License
+	if i < len(licensesResp) {
+		retrieved = licensesResp[i]
+	}
+
+	// deps.dev returns UNKNOWN for the packages that it does not know the license of
+	if slices.ContainsFunc(retrieved, func(l models.License) bool { return !strings.EqualFold(string(l), "unknown") }) {
+		return retrieved, models.LicenseSourceDepsDev
+	}
+
+	if len(pkg.Licenses) > 0 {
+		licenses := make([]models.License, len(pkg.Licenses))
+		for j, license := range pkg.Licenses {
+			licenses[j] = models.License(license)
+		}
+
+		return licenses, models.LicenseSourceLocal
+	}
+
+	return []models.License{"UNKNOWN"}, ""
+}
+
 // grouped by source location.
 func groupBySource(r reporter.Reporter, packages []scannedPackage, artifacts []models.ScannedArtifact) models.VulnerabilityResults {
 	output := models.VulnerabilityResults{
@@ -224,3 +280,11 @@ func groupBySource(r reporter.Reporter, packages []scannedPackage, artifacts []m
 
 	return output
 }
+
+func exceptionReason(exception config.LicenseException) string {
+	if exception.Reason == "" {
+		return ""
+	}
+
+	return " because: " + exception.Reason
+}
@@ -1,8 +1,10 @@
 package osvscanner
 
 import (
+	"context"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
 	"github.com/google/osv-scanner/internal/testutility"
 	"github.com/google/osv-scanner/pkg/config"
 	"github.com/google/osv-scanner/pkg/lockfile"
@@ -68,6 +70,72 @@ func Test_packageHasRangedVersion(t *testing.T) {
 	}
 }
 
+func Test_packageLicenses(t *testing.T) {
+	t.Parallel()
+
+	local := scannedPackage{Name: "pkg", Licenses: []string{"MIT OR Apache-2.0"}}
+
+	tests := []struct {
+		name         string
+		pkg          scannedPackage
+		licensesResp [][]models.License
+		wantLicenses []models.License
+		wantSource   models.LicenseSource
+	}{
+		{
+			name:         "retrieved from deps.dev",
+			pkg:          local,
+			licensesResp: [][]models.License{{"MIT"}},
+			wantLicenses: []models.License{"MIT"},
+			wantSource:   models.LicenseSourceDepsDev,
+		},
+		{
+			name:         "unknown to deps.dev",
+			pkg:          local,
+			licensesResp: [][]models.License{{"UNKNOWN"}},
+			wantLicenses: []models.License{"MIT OR Apache-2.0"},
+			wantSource:   models.LicenseSourceLocal,
+		},
+		{
+			name:         "offline",
+			pkg:          local,
+			licensesResp: nil,
+			wantLicenses: []models.License{"MIT OR Apache-2.0"},
+			wantSource:   models.LicenseSourceLocal,
+		},
+		{
+			name:         "unknown to deps.dev without local licenses",
+			pkg:          scannedPackage{Name: "pkg"},
+			licensesResp: [][]models.License{{"UNKNOWN"}},
+			wantLicenses: []models.License{"UNKNOWN"},
+			wantSource:   "",
+		},
+		{
+			name:         "offline without local licenses",
+			pkg:          scannedPackage{Name: "pkg"},
+			licensesResp: nil,
+			wantLicenses: []models.License{"UNKNOWN"},
+			wantSource:   "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			licenses, source := packageLicenses(tt.pkg, tt.licensesResp, 0)
+
+			if diff := cmp.Diff(tt.wantLicenses, licenses); diff != "" {
+				t.Errorf("packageLicenses() licenses (-want +got):\n%s", diff)
+			}
+
+			if source != tt.wantSource {
+				t.Errorf("packageLicenses() source = %q, want %q", source, tt.wantSource)
+			}
+		})
+	}
+}
+
 func Test_assembleResult(t *testing.T) {
 	t.Parallel()
 	type args struct {
@@ -297,6 +365,84 @@ func Test_assembleResult(t *testing.T) {
 			},
 			config: &config.ConfigManager{},
 		},
+	}, {
+		name: "group_vulnerabilities_with_license_expressions_and_denylist",
+		args: args{
+			r:         &reporter.VoidReporter{},
+			packages:  packages,
+			vulnsResp: vulnsResp,
+			licensesResp: [][]models.License{
+				{models.License("MIT OR GPL-3.0-only")},
+				{models.License("Apache-2.0 WITH LLVM-exception AND GPL-3.0-only")},
+				{models.License("(MIT AND BSD-3-Clause)")},
+			},
+			actions: ScannerActions{
+				ExperimentalScannerActions: ExperimentalScannerActions{
+					ShowAllPackages:       false,
+					ScanLicensesAllowlist: []string{"MIT", "Apache-2.0", "BSD-3-Clause"},
+					ScanLicensesDenylist:  []string{"BSD-3-Clause"},
+				},
+				CallAnalysisStates: callAnalysisStates,
+			},
+			config: &config.ConfigManager{},
+		},
+	}, {
+		name: "group_vulnerabilities_with_config_license_policy",
+		args: args{
+			r:            &reporter.VoidReporter{},
+			packages:     packages,
+			vulnsResp:    vulnsResp,
+			licensesResp: makeLicensesResp(),
+			actions: ScannerActions{
+				ExperimentalScannerActions: ExperimentalScannerActions{
+					ShowAllPackages: false,
+				},
+				CallAnalysisStates: callAnalysisStates,
+			},
+			config: &config.ConfigManager{
+				OverrideConfig: &config.Config{
+					Licenses: config.LicenseConfig{
+						Allow: []string{"MIT", "0BSD"},
+						Deny:  []string{"0BSD"},
+						Exceptions: []config.LicenseException{
+							{
+								Package:   "pkg-3",
+								Ecosystem: "npm",
+								Reason:    "license is not known",
+							},
+						},
+					},
+				},
+			},
+		},
+	}, {
+		name: "group_vulnerabilities_with_license_allowlist_and_config_license_exception",
+		args: args{
+			r:            &reporter.VoidReporter{},
+			packages:     packages,
+			vulnsResp:    vulnsResp,
+			licensesResp: makeLicensesResp(),
+			actions: ScannerActions{
+				ExperimentalScannerActions: ExperimentalScannerActions{
+					ShowAllPackages:       false,
+					ScanLicensesAllowlist: []string{"MIT"},
+				},
+				CallAnalysisStates: callAnalysisStates,
+			},
+			config: &config.ConfigManager{
+				OverrideConfig: &config.Config{
+					Licenses: config.LicenseConfig{
+						Deny: []string{"MIT"},
+						Exceptions: []config.LicenseException{
+							{
+								Package:  "pkg-1",
+								Licenses: []string{"0BSD"},
+							},
+						},
+					},
+				},
+			},
+		},
 	}, {
 		name: "group_vulnerabilities_ignore_ranged_versions",
 		args: args{
@@ -316,7 +462,7 @@ func Test_assembleResult(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
-			got := buildVulnerabilityResults(tt.args.r, tt.args.packages, []models.ScannedArtifact{}, tt.args.vulnsResp, tt.args.licensesResp, tt.args.actions, tt.args.config)
+			got := buildVulnerabilityResults(context.Background(), tt.args.r, tt.args.packages, []models.ScannedArtifact{}, tt.args.vulnsResp, tt.args.licensesResp, tt.args.actions, tt.args.config)
 			testutility.NewSnapshot().MatchJSON(t, got)
 		})
 	}
@@ -0,0 +1,635 @@
+package osvscanner
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/google/osv-scanner/internal/output"
+	"github.com/google/osv-scanner/internal/pathfilter"
+	"github.com/google/osv-scanner/internal/watch"
+	"github.com/google/osv-scanner/pkg/config"
+	"github.com/google/osv-scanner/pkg/lockfile"
+	"github.com/google/osv-scanner/pkg/models"
+	"github.com/google/osv-scanner/pkg/osv"
+	"github.com/google/osv-scanner/pkg/reporter"
+)
+
+// watchedLockfile is a lockfile that is scanned again whenever it or one of
+// the source files its packages are matched against changes
+type watchedLockfile struct {
+	path    string
+	parseAs string
+	// dir is the directory the lockfile was found in, if any, which its
+	// paths are made relative to if requested
+	dir string
+
+	sourceFiles []string
+	packages    []scannedPackage
+	artifacts   []models.ScannedArtifact
+}
+
+// files returns the files that the packages of the lockfile come from
+func (l *watchedLockfile) files() []string {
+	return append([]string{l.path}, l.sourceFiles...)
+}
+
+// findSourceFiles determines the source files of the lockfile, which are
+// those that its matchers use along with any other files that its packages
+// were found in, even if they do not exist (yet)
+func (l *watchedLockfile) findSourceFiles(enabledParsers map[string]bool) {
+	l.sourceFiles = nil
+
+	addSourceFile := func(path string) {
+		if path != "" && path != l.path && !slices.Contains(l.sourceFiles, path) {
+			l.sourceFiles = append(l.sourceFiles, path)
+		}
+	}
+
+	if _, extractedAs := lockfile.FindExtractor(l.path, l.parseAs, enabledParsers); extractedAs != "" {
+		if f, err := lockfile.OpenLocalDepFile(l.path); err == nil {
+			for _, path := range lockfile.MatcherSourceFiles(f, extractedAs) {
+				addSourceFile(path)
+			}
+			f.Close()
+		}
+	}
+
+	for _, pkg := range l.packages {
+		addSourceFile(pkg.BlockLocation.Filename)
+
+		if pkg.NameLocation != nil {
+			addSourceFile(pkg.NameLocation.Filename)
+		}
+
+		if pkg.VersionLocation != nil {
+			addSourceFile(pkg.VersionLocation.Filename)
+		}
+	}
+}
+
+// watchedDir is a directory that was scanned, which new lockfiles are
+// looked for in when they are created
+type watchedDir struct {
+	path      string
+	recursive bool
+	filter    *pathfilter.Filter
+}
+
+// contains returns the path of the file relative to the directory, if it
+// is one that would be found by scanning the directory
+func (d watchedDir) contains(path string) (string, bool) {
+	dir, err := filepath.Abs(d.path)
+	if err != nil {
+		return "", false
+	}
+
+	rel, err := filepath.Rel(dir, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+
+	if !d.recursive && filepath.Dir(rel) != "." {
+		return "", false
+	}
+
+	return filepath.ToSlash(rel), true
+}
+
+// watchState is everything that is scanned when watching
+type watchState struct {
+	dirs      []watchedDir
+	lockfiles []*watchedLockfile
+	// packages and artifacts that are not from lockfiles that can be watched,
+	// such as those of git repositories, which are only scanned once
+	packages  []scannedPackage
+	artifacts []models.ScannedArtifact
+}
+
+// files returns every file that is being watched
+func (ws *watchState) files() []string {
+	var files []string
+
+	for _, l := range ws.lockfiles {
+		files = append(files, l.files()...)
+	}
+
+	return files
+}
+
+// createdDirs returns the directories that new lockfiles can be created in,
+// which are the scanned directories along with those that lockfiles were
+// found in within them
+func (ws *watchState) createdDirs() []string {
+	dirs := make([]string, 0, len(ws.dirs))
+
+	for _, d := range ws.dirs {
+		dirs = append(dirs, d.path)
+	}
+
+	for _, l := range ws.lockfiles {
+		if l.dir != "" && !slices.Contains(dirs, filepath.Dir(l.path)) {
+			dirs = append(dirs, filepath.Dir(l.path))
+		}
+	}
+
+	return dirs
+}
+
+// isWatched reports if the file at the given path is one of the lockfiles
+// (or their source files) that is being watched
+func (ws *watchState) isWatched(path string) bool {
+	return slices.ContainsFunc(ws.lockfiles, func(l *watchedLockfile) bool {
+		return slices.Contains(l.files(), path)
+	})
+}
+
+// scannedPackages returns the current packages and artifacts of everything
+// being watched, with their paths being made relative if requested
+func (ws *watchState) scannedPackages(relativize bool) ([]scannedPackage, []models.ScannedArtifact) {
+	packages := slices.Clone(ws.packages)
+	artifacts := slices.Clone(ws.artifacts)
+
+	for _, l := range ws.lockfiles {
+		pkgs := clonePackages(l.packages)
+		arts := cloneArtifacts(l.artifacts)
+
+		if relativize && l.dir != "" {
+			relativizePaths(l.dir, pkgs, arts)
+		}
+
+		packages = append(packages, pkgs...)
+		artifacts = append(artifacts, arts...)
+	}
+
+	return packages, artifacts
+}
+
+// clonePackages copies the packages so that their locations can be changed
+// without affecting the originals
+func clonePackages(packages []scannedPackage) []scannedPackage {
+	cloned := slices.Clone(packages)
+
+	for i, pkg := range cloned {
+		if pkg.NameLocation != nil {
+			location := *pkg.NameLocation
+			cloned[i].NameLocation = &location
+		}
+
+		if pkg.VersionLocation != nil {
+			location := *pkg.VersionLocation
+			cloned[i].VersionLocation = &location
+		}
+	}
+
+	return cloned
+}
+
+// cloneArtifacts copies the artifacts so that their filenames can be changed
+// without affecting the originals
+func cloneArtifacts(artifacts []models.ScannedArtifact) []models.ScannedArtifact {
+	cloned := slices.Clone(artifacts)
+
+	for i, artifact := range cloned {
+		if artifact.DependsOn != nil {
+			dependsOn := *artifact.DependsOn
+			cloned[i].DependsOn = &dependsOn
+		}
+	}
+
+	return cloned
+}
+
+// isWatchable reports if the path is a lockfile on the local filesystem,
+// as opposed to i.e. a git repository or a lockfile within an archive
+func isWatchable(pkg scannedPackage) bool {
+	if pkg.Source.Type != "lockfile" {
+		return false
+	}
+
+	info, err := os.Stat(pkg.Source.Path)
+
+	return err == nil && info.Mode().IsRegular()
+}
+
+// addDirectory adds the packages and artifacts found by scanning the directory,
+// with those of lockfiles being watched and everything else only being kept
+func (ws *watchState) addDirectory(dir string, pkgs []scannedPackage, artifacts []models.ScannedArtifact, relativize bool, enabledParsers map[string]bool) {
+	byPath := make(map[string]*watchedLockfile)
+	var static []scannedPackage
+
+	for _, pkg := range pkgs {
+		if !isWatchable(pkg) {
+			static = append(static, pkg)
+
+			continue
+		}
+
+		l, ok := byPath[pkg.Source.Path]
+		if !ok {
+			l = &watchedLockfile{path: pkg.Source.Path, dir: dir}
+			byPath[pkg.Source.Path] = l
+			ws.lockfiles = append(ws.lockfiles, l)
+		}
+
+		l.packages = append(l.packages, pkg)
+	}
+
+	var staticArtifacts []models.ScannedArtifact
+	for _, artifact := range artifacts {
+		if l, ok := byPath[artifact.Filename]; ok {
+			l.artifacts = append(l.artifacts, artifact)
+		} else {
+			staticArtifacts = append(staticArtifacts, artifact)
+		}
+	}
+
+	for _, l := range byPath {
+		l.findSourceFiles(enabledParsers)
+	}
+
+	if relativize {
+		relativizePaths(dir, static, staticArtifacts)
+	}
+
+	ws.packages = append(ws.packages, static...)
+	ws.artifacts = append(ws.artifacts, staticArtifacts...)
+}
+
+// checkWatchable returns an error if the actions include anything that
+// cannot be watched for changes
+func checkWatchable(actions ScannerActions) error {
+	if actions.GitRev != "" || actions.GitDiff != "" {
+		return errors.New("git revisions cannot be watched")
+	}
+
+	if actions.WriteBaselinePath != "" {
+		return errors.New("a baseline cannot be written when watching")
+	}
+
+	if actions.ScanOCIImage != "" || len(actions.DockerContainerNames) > 0 || len(actions.SBOMPaths) > 0 {
+		return errors.New("only lockfiles and directories can be watched")
+	}
+
+	for _, lockfileElem := range actions.LockfilePaths {
+		if _, lockfilePath := parseLockfilePath(lockfileElem); lockfilePath == stdinLockfilePath {
+			return errors.New("lockfiles read from stdin cannot be watched")
+		}
+	}
+
+	return nil
+}
+
+// queryMemo remembers the results of querying for each package version,
+// so that only new package versions are queried when scanning again
+type queryMemo struct {
+	r        reporter.Reporter
+	vulns    map[string][]models.Vulnerability
+	licenses map[string][]models.License
+}
+
+func newQueryMemo(r reporter.Reporter) *queryMemo {
+	return &queryMemo{
+		r:        r,
+		vulns:    make(map[string][]models.Vulnerability),
+		licenses: make(map[string][]models.License),
+	}
+}
+
+// queryKey identifies what a package is queried for, in the same way as
+// the query that is made for it
+func queryKey(pkg scannedPackage) string {
+	switch {
+	case pkg.Ecosystem != "" && pkg.Name != "" && pkg.Version != "":
+		return "pkg\x00" + string(pkg.Ecosystem) + "\x00" + pkg.Name + "\x00" + pkg.Version
+	case pkg.Commit != "":
+		// the source is used to find the repository when matching commits locally
+		return "commit\x00" + pkg.Commit + "\x00" + pkg.Source.Path
+	default:
+		return "purl\x00" + pkg.PURL
+	}
+}
+
+// unqueried returns the packages that are not yet in the memo, along with
+// their keys, with each package version only being included once
+func unqueried[T any](memo map[string]T, packages []scannedPackage) ([]scannedPackage, []string) {
+	var missing []scannedPackage
+	var keys []string
+
+	for _, pkg := range packages {
+		key := queryKey(pkg)
+
+		if _, ok := memo[key]; ok || slices.Contains(keys, key) {
+			continue
+		}
+
+		missing = append(missing, pkg)
+		keys = append(keys, key)
+	}
+
+	return missing, keys
+}
+
+// memoizeVulnerabilities wraps the query so that it is only made for the package
+// versions that have not been queried before
+func (m *queryMemo) memoizeVulnerabilities(query func([]scannedPackage) (*osv.HydratedBatchedResponse, error)) func([]scannedPackage) (*osv.HydratedBatchedResponse, error) {
+	return func(packages []scannedPackage) (*osv.HydratedBatchedResponse, error) {
+		missing, keys := unqueried(m.vulns, packages)
+
+		m.r.Verbosef(
+			"Querying %d new package %s, %d already queried\n",
+			len(missing),
+			output.Form(len(missing), "version", "versions"),
+			len(packages)-len(missing),
+		)
+
+		if len(missing) > 0 {
+			resp, err := query(missing)
+			if err != nil {
+				return nil, err
+			}
+
+			for i, key := range keys {
+				m.vulns[key] = resp.Results[i].Vulns
+			}
+		}
+
+		resp := &osv.HydratedBatchedResponse{Results: make([]osv.Response, len(packages))}
+		for i, pkg := range packages {
+			resp.Results[i] = osv.Response{Vulns: m.vulns[queryKey(pkg)]}
+		}
+
+		return resp, nil
+	}
+}
+
+// memoizeLicenses wraps the query so that it is only made for the package
+// versions that have not been queried before
+func (m *queryMemo) memoizeLicenses(query func([]scannedPackage) ([][]models.License, error)) func([]scannedPackage) ([][]models.License, error) {
+	return func(packages []scannedPackage) ([][]models.License, error) {
+		missing, keys := unqueried(m.licenses, packages)
+
+		if len(missing) > 0 {
+			resp, err := query(missing)
+			if err != nil {
+				return nil, err
+			}
+
+			for i, key := range keys {
+				m.licenses[key] = resp[i]
+			}
+		}
+
+		licenses := make([][]models.License, len(packages))
+		for i, pkg := range packages {
+			licenses[i] = m.licenses[queryKey(pkg)]
+		}
+
+		return licenses, nil
+	}
+}
+
+// Watch performs the osv scanner action like Scan and then watches the
+// lockfiles that were scanned, along with the source files that their
+// packages are matched against, until the context is cancelled.
+//
+// Whenever any of the files change, only the lockfiles they affect are
+// extracted again and only package versions that have not been seen before
+// are queried, with onResults being called with the results of every scan.
+func (s *Scanner) Watch(ctx context.Context, actions ScannerActions, onResults func(models.VulnerabilityResults, error)) error {
+	r := s.reporter
+
+	if err := checkWatchable(actions); err != nil {
+		return err
+	}
+
+	actions, err := s.withFailOnConfig(actions, nil)
+	if err != nil {
+		return err
+	}
+
+	if len(actions.EnableParsers) == 0 {
+		actions.EnableParsers = s.enabledParsers
+	}
+	enabledParsers := initializeEnabledParsers(actions.EnableParsers)
+
+	configManager, err := s.newConfigManager(actions, nil)
+	if err != nil {
+		r.Errorf("Failed to read config file: %s\n", err)
+		return err
+	}
+
+	var known *baseline
+	if actions.BaselinePath != "" {
+		if known, err = loadBaseline(actions.BaselinePath, baselineRoots(actions)); err != nil {
+			return err
+		}
+	}
+
+	var extractionCache *lockfile.Cache
+	if actions.UseExtractionCache {
+		extractionCache, err = newExtractionCache(actions.ExtractionCacheDir)
+		if err != nil {
+			r.Warnf("Failed to set up extraction cache, continuing without it: %v\n", err)
+		}
+	}
+
+	state, err := s.collectWatched(ctx, actions, configManager, enabledParsers, extractionCache)
+	if err != nil {
+		return err
+	}
+
+	watcher, err := watch.New(watch.DefaultDebounce)
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+
+	memo := newQueryMemo(r)
+
+	isLockfile := func(path string) bool {
+		_, extractedAs := lockfile.FindExtractor(path, "", enabledParsers)
+
+		return extractedAs != ""
+	}
+
+	for {
+		if err := watcher.WatchCreated(state.createdDirs(), isLockfile); err != nil {
+			return err
+		}
+
+		files := state.files()
+		if err := watcher.Watch(files); err != nil {
+			return err
+		}
+
+		packages, artifacts := state.scannedPackages(actions.ConsiderScanPathAsRoot || actions.PathRelativeToScanDir)
+		results, err := s.queryResults(ctx, actions, configManager, packages, artifacts, memo)
+
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		if known != nil && (err == nil || errors.Is(err, VulnerabilitiesFoundErr)) {
+			s.applyBaseline(known, &results, actions.ShowAllPackages)
+			err = resultsError(results, actions)
+		}
+
+		onResults(results, err)
+
+		r.Infof("Watching %d %s for changes\n", len(files), output.Form(len(files), "file", "files"))
+
+		changed, err := watcher.Next(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			return err
+		}
+
+		state.rescan(r, changed, actions, enabledParsers, extractionCache)
+	}
+}
+
+// collectWatched scans the lockfiles and directories of the actions,
+// keeping track of which files each package came from
+func (s *Scanner) collectWatched(ctx context.Context, actions ScannerActions, configManager *config.ConfigManager, enabledParsers map[string]bool, extractionCache *lockfile.Cache) (*watchState, error) {
+	r := s.reporter
+	state := &watchState{}
+
+	for _, lockfileElem := range actions.LockfilePaths {
+		parseAs, lockfilePath := parseLockfilePath(lockfileElem)
+
+		lockfilePath, err := filepath.Abs(lockfilePath)
+		if err != nil {
+			r.Errorf("Failed to resolved path with error %s\n", err)
+			return nil, err
+		}
+
+		pkgs, artifact, err := scanLockfile(r, lockfilePath, parseAs, actions.CompareOffline, enabledParsers, extractionCache)
+		if err != nil {
+			return nil, err
+		}
+
+		l := &watchedLockfile{path: lockfilePath, parseAs: parseAs, packages: pkgs}
+		if artifact != nil {
+			l.artifacts = append(l.artifacts, *artifact)
+		}
+		l.findSourceFiles(enabledParsers)
+
+		state.lockfiles = append(state.lockfiles, l)
+	}
+
+	for _, commit := range actions.GitCommits {
+		state.packages = append(state.packages, createCommitQueryPackage(commit, "HASH"))
+	}
+
+	for _, dir := range actions.DirectoryPaths {
+		r.Infof("Scanning dir %s\n", dir)
+		pathFilter, err := newPathFilter(r, actions, configManager, dir)
+		if err != nil {
+			return nil, err
+		}
+
+		pkgs, artifacts, err := scanDir(ctx, r, dir, actions.SkipGit, actions.Recursive, !actions.NoIgnore, actions.CompareOffline, actions.ScanArchives, enabledParsers, pathFilter, actions.Parallelism, extractionCache)
+		if err != nil {
+			return nil, err
+		}
+
+		state.addDirectory(dir, pkgs, artifacts, actions.ConsiderScanPathAsRoot || actions.PathRelativeToScanDir, enabledParsers)
+		state.dirs = append(state.dirs, watchedDir{path: dir, recursive: actions.Recursive, filter: pathFilter})
+	}
+
+	return state, nil
+}
+
+// rescan extracts the lockfiles affected by the changed files again,
+// keeping the previous packages of any that can no longer be extracted
+// (such as while they are being edited) until they can be, and extracts any
+// lockfiles that have been created within the scanned directories
+func (ws *watchState) rescan(r reporter.Reporter, changed []string, actions ScannerActions, enabledParsers map[string]bool, extractionCache *lockfile.Cache) {
+	var created []string
+	for _, path := range changed {
+		if !ws.isWatched(path) {
+			created = append(created, path)
+		}
+	}
+
+	for _, l := range ws.lockfiles {
+		if !slices.ContainsFunc(l.files(), func(path string) bool { return slices.Contains(changed, path) }) {
+			continue
+		}
+
+		if _, err := os.Stat(l.path); errors.Is(err, fs.ErrNotExist) {
+			r.Infof("%s was removed\n", l.path)
+			l.packages, l.artifacts = nil, nil
+
+			continue
+		}
+
+		pkgs, artifact, err := scanLockfile(r, l.path, l.parseAs, actions.CompareOffline, enabledParsers, extractionCache)
+		if err != nil {
+			r.Warnf("Failed to scan %s again, keeping its previous packages: %v\n", l.path, err)
+
+			continue
+		}
+
+		l.packages, l.artifacts = pkgs, nil
+		if artifact != nil {
+			l.artifacts = append(l.artifacts, *artifact)
+		}
+		l.findSourceFiles(enabledParsers)
+	}
+	for _, path := range created {
+		ws.addCreated(r, path, actions, enabledParsers, extractionCache)
+	}
+}
+
+// addCreated starts watching the lockfile that was created at the given path,
+// if it is in one of the scanned directories and is not excluded from them
+func (ws *watchState) addCreated(r reporter.Reporter, path string, actions ScannerActions, enabledParsers map[string]bool, extractionCache *lockfile.Cache) {
+	_, parser := lockfile.FindExtractor(path, "", enabledParsers)
+	if parser == "" {
+		return
+	}
+
+	for _, d := range ws.dirs {
+		rel, ok := d.contains(path)
+		if !ok {
+			continue
+		}
+
+		if skip, reason := d.filter.SkipFile(rel, parser); skip {
+			r.Verbosef("Skipping %s as %s\n", path, reason)
+
+			return
+		}
+
+		if _, err := os.Stat(path); err != nil {
+			return
+		}
+
+		r.Infof("%s was created\n", path)
+
+		pkgs, artifact, err := scanLockfile(r, path, "", actions.CompareOffline, enabledParsers, extractionCache)
+		if err != nil {
+			r.Warnf("Failed to scan %s: %v\n", path, err)
+		}
+
+		// lockfiles that cannot be extracted yet are still watched for when they can be
+		l := &watchedLockfile{path: path, dir: d.path, packages: pkgs}
+		if artifact != nil {
+			l.artifacts = append(l.artifacts, *artifact)
+		}
+		l.findSourceFiles(enabledParsers)
+
+		ws.lockfiles = append(ws.lockfiles, l)
+
+		return
+	}
+}
@@ -0,0 +1,166 @@
+package osvscanner_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/google/osv-scanner/pkg/models"
+	"github.com/google/osv-scanner/pkg/osv"
+	"github.com/google/osv-scanner/pkg/osvscanner"
+)
+
+func TestScanner_Watch(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	requirements := filepath.Join(dir, "requirements.txt")
+	lockfile := filepath.Join(dir, "poetry.lock")
+
+	writeRequirements := func(content string) {
+		t.Helper()
+
+		if err := os.WriteFile(requirements, []byte(content), 0600); err != nil {
+			t.Fatalf("could not write requirements.txt: %v", err)
+		}
+	}
+
+	writeRequirements("django==4.2.0\n")
+
+	poetryLock := "[[package]]\nname = \"jinja2\"\nversion = \"2.0.0\"\n\n[metadata]\ncontent-hash = \"abc\"\n"
+	if err := os.WriteFile(lockfile, []byte(poetryLock), 0600); err != nil {
+		t.Fatalf("could not write poetry.lock: %v", err)
+	}
+
+	server, queried := newRecordingOSVServer(t, map[string]string{
+		"django@4.2.0":   "GHSA-django",
+		"flask@2.0.0":    "GHSA-flask",
+		"jinja2@2.0.0":   "GHSA-jinja2",
+		"werkzeug@1.0.0": "GHSA-werkzeug",
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	type scan struct {
+		results models.VulnerabilityResults
+		err     error
+	}
+
+	scans := make(chan scan)
+	done := make(chan error)
+
+	go func() {
+		done <- osvscanner.NewScanner(osvscanner.WithHTTPClient(server.Client())).Watch(ctx, osvscanner.ScannerActions{
+			DirectoryPaths:  []string{dir},
+			APIClientConfig: osv.ClientConfig{BaseURL: server.URL},
+		}, func(results models.VulnerabilityResults, err error) {
+			scans <- scan{results, err}
+		})
+	}()
+
+	next := func() models.VulnerabilityResults {
+		t.Helper()
+
+		select {
+		case s := <-scans:
+			if !errors.Is(s.err, osvscanner.VulnerabilitiesFoundErr) {
+				t.Fatalf("expected vulnerabilities to be found, got %v", s.err)
+			}
+
+			return s.results
+		case err := <-done:
+			t.Fatalf("Watch() stopped unexpectedly: %v", err)
+		case <-ctx.Done():
+			t.Fatalf("timed out waiting for results")
+		}
+
+		return models.VulnerabilityResults{}
+	}
+
+	want := []string{
+		lockfile + " jinja2 GHSA-jinja2",
+		requirements + " django GHSA-django",
+	}
+
+	if diff := cmp.Diff(want, vulnerabilityIDs(next())); diff != "" {
+		t.Errorf("Watch() returned unexpected vulnerabilities (-want +got):\n%s", diff)
+	}
+
+	writeRequirements("django==4.2.0\nflask==2.0.0\n")
+
+	want = []string{
+		lockfile + " jinja2 GHSA-jinja2",
+		requirements + " django GHSA-django",
+		requirements + " flask GHSA-flask",
+	}
+
+	if diff := cmp.Diff(want, vulnerabilityIDs(next())); diff != "" {
+		t.Errorf("Watch() returned unexpected vulnerabilities after a change (-want +got):\n%s", diff)
+	}
+
+	// only package versions that have not been seen before are queried again
+	if diff := cmp.Diff([]string{"jinja2@2.0.0", "django@4.2.0", "flask@2.0.0"}, queried(), cmpopts.SortSlices(func(a, b string) bool { return a < b })); diff != "" {
+		t.Errorf("Watch() made unexpected queries (-want +got):\n%s", diff)
+	}
+
+	// lockfiles that are removed no longer have any packages
+	if err := os.Remove(lockfile); err != nil {
+		t.Fatalf("could not remove poetry.lock: %v", err)
+	}
+
+	want = []string{
+		requirements + " django GHSA-django",
+		requirements + " flask GHSA-flask",
+	}
+
+	if diff := cmp.Diff(want, vulnerabilityIDs(next())); diff != "" {
+		t.Errorf("Watch() returned unexpected vulnerabilities after a removal (-want +got):\n%s", diff)
+	}
+
+	// lockfiles that are created in the scanned directory are scanned too
+	pipfileLock := filepath.Join(dir, "Pipfile.lock")
+	if err := os.WriteFile(pipfileLock, []byte(`{"default": {"werkzeug": {"version": "==1.0.0"}}, "develop": {}}`), 0600); err != nil {
+		t.Fatalf("could not write Pipfile.lock: %v", err)
+	}
+
+	want = []string{
+		pipfileLock + " werkzeug GHSA-werkzeug",
+		requirements + " django GHSA-django",
+		requirements + " flask GHSA-flask",
+	}
+
+	if diff := cmp.Diff(want, vulnerabilityIDs(next())); diff != "" {
+		t.Errorf("Watch() returned unexpected vulnerabilities after a lockfile was created (-want +got):\n%s", diff)
+	}
+
+	cancel()
+
+	if err := <-done; err != nil {
+		t.Errorf("Watch() error = %v, want nil once cancelled", err)
+	}
+}
+
+func TestScanner_Watch_Unwatchable(t *testing.T) {
+	t.Parallel()
+
+	for _, actions := range []osvscanner.ScannerActions{
+		{SBOMPaths: []string{"bom.json"}},
+		{DirectoryPaths: []string{"."}, GitRev: "HEAD"},
+		{LockfilePaths: []string{"requirements.txt:-"}},
+		{DirectoryPaths: []string{"."}, WriteBaselinePath: "baseline.json"},
+	} {
+		err := osvscanner.NewScanner().Watch(context.Background(), actions, func(models.VulnerabilityResults, error) {
+			t.Errorf("expected no results")
+		})
+
+		if err == nil {
+			t.Errorf("expected %+v to not be watchable", actions)
+		}
+	}
+}
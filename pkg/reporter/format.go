@@ -7,7 +7,7 @@ import (
 	"github.com/google/osv-scanner/pkg/models"
 )
 
-var format = []string{"table", "vertical", "json", "markdown", "sarif", "gh-annotations", "cyclonedx-1-4", "cyclonedx-1-5"}
+var format = []string{"table", "vertical", "json", "markdown", "sarif", "gh-annotations", "cyclonedx-1-4", "cyclonedx-1-5", "notice", "notice-markdown"}
 
 func Format() []string {
 	return format
@@ -33,6 +33,10 @@ func New(format string, stdout, stderr io.Writer, level VerbosityLevel, terminal
 		return NewCycloneDXReporter(stdout, stderr, models.CycloneDXVersion14, level), nil
 	case "cyclonedx-1-5":
 		return NewCycloneDXReporter(stdout, stderr, models.CycloneDXVersion15, level), nil
+	case "notice":
+		return NewNoticeReporter(stdout, stderr, level, false), nil
+	case "notice-markdown":
+		return NewNoticeReporter(stdout, stderr, level, true), nil
 	default:
 		return nil, fmt.Errorf("%v is not a valid format", format)
 	}
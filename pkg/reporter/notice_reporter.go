@@ -0,0 +1,66 @@
+package reporter
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/google/osv-scanner/internal/output"
+	"github.com/google/osv-scanner/pkg/models"
+)
+
+// NoticeReporter prints the packages that were scanned as a NOTICE file that
+// attributes them, so runtime information is printed to stderr
+type NoticeReporter struct {
+	hasErrored bool
+	stdout     io.Writer
+	stderr     io.Writer
+	level      VerbosityLevel
+	markdown   bool
+}
+
+func NewNoticeReporter(stdout, stderr io.Writer, level VerbosityLevel, markdown bool) *NoticeReporter {
+	return &NoticeReporter{
+		stdout:     stdout,
+		stderr:     stderr,
+		hasErrored: false,
+		level:      level,
+		markdown:   markdown,
+	}
+}
+
+func (r *NoticeReporter) Errorf(format string, a ...any) {
+	fmt.Fprintf(r.stderr, format, a...)
+	r.hasErrored = true
+}
+
+func (r *NoticeReporter) HasErrored() bool {
+	return r.hasErrored
+}
+
+func (r *NoticeReporter) Warnf(format string, a ...any) {
+	if WarnLevel <= r.level {
+		fmt.Fprintf(r.stderr, format, a...)
+	}
+}
+
+func (r *NoticeReporter) Infof(format string, a ...any) {
+	if InfoLevel <= r.level {
+		fmt.Fprintf(r.stderr, format, a...)
+	}
+}
+
+func (r *NoticeReporter) Verbosef(format string, a ...any) {
+	if VerboseLevel <= r.level {
+		fmt.Fprintf(r.stderr, format, a...)
+	}
+}
+
+func (r *NoticeReporter) PrintResult(vulnResult *models.VulnerabilityResults) error {
+	if r.markdown {
+		output.PrintMarkdownNoticeResults(vulnResult, r.stdout)
+	} else {
+		output.PrintNoticeResults(vulnResult, r.stdout)
+	}
+
+	return nil
+}
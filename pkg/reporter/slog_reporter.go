@@ -0,0 +1,58 @@
+package reporter
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync/atomic"
+
+	"github.com/google/osv-scanner/pkg/models"
+)
+
+// SlogReporter sends runtime information to a structured logger, using the
+// level of the logger to decide what is printed. Vulnerability results are
+// not printed, as they are expected to be handled by the caller.
+//
+// A SlogReporter is safe for concurrent use.
+type SlogReporter struct {
+	hasErrored atomic.Bool
+	logger     *slog.Logger
+}
+
+func NewSlogReporter(logger *slog.Logger) *SlogReporter {
+	return &SlogReporter{logger: logger}
+}
+
+func (r *SlogReporter) log(level slog.Level, format string, a ...any) {
+	if !r.logger.Enabled(context.Background(), level) {
+		return
+	}
+
+	r.logger.Log(context.Background(), level, strings.TrimRight(fmt.Sprintf(format, a...), "\n"))
+}
+
+func (r *SlogReporter) Errorf(format string, a ...any) {
+	r.log(slog.LevelError, format, a...)
+	r.hasErrored.Store(true)
+}
+
+func (r *SlogReporter) HasErrored() bool {
+	return r.hasErrored.Load()
+}
+
+func (r *SlogReporter) Warnf(format string, a ...any) {
+	r.log(slog.LevelWarn, format, a...)
+}
+
+func (r *SlogReporter) Infof(format string, a ...any) {
+	r.log(slog.LevelInfo, format, a...)
+}
+
+func (r *SlogReporter) Verbosef(format string, a ...any) {
+	r.log(slog.LevelDebug, format, a...)
+}
+
+func (r *SlogReporter) PrintResult(vulnResult *models.VulnerabilityResults) error {
+	return nil
+}
@@ -0,0 +1,81 @@
+package reporter_test
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+
+	"github.com/google/osv-scanner/pkg/reporter"
+)
+
+func newTestSlogReporter(level slog.Level) (*reporter.SlogReporter, *bytes.Buffer) {
+	writer := &bytes.Buffer{}
+	logger := slog.New(slog.NewTextHandler(writer, &slog.HandlerOptions{
+		Level: level,
+		ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+
+			return a
+		},
+	}))
+
+	return reporter.NewSlogReporter(logger), writer
+}
+
+func TestSlogReporter_Errorf(t *testing.T) {
+	t.Parallel()
+
+	r, writer := newTestSlogReporter(slog.LevelError)
+
+	r.Errorf("hello %s!\n", "world")
+
+	if writer.String() != "level=ERROR msg=\"hello world!\"\n" {
+		t.Errorf("unexpected output \"%s\"", writer.String())
+	}
+	if !r.HasErrored() {
+		t.Error("HasErrored() should have returned true")
+	}
+}
+
+func TestSlogReporter_Levels(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		lvl              slog.Level
+		expectedPrintout string
+	}{
+		{
+			lvl:              slog.LevelDebug,
+			expectedPrintout: "level=WARN msg=warn\nlevel=INFO msg=info\nlevel=DEBUG msg=verbose\n",
+		},
+		{
+			lvl:              slog.LevelInfo,
+			expectedPrintout: "level=WARN msg=warn\nlevel=INFO msg=info\n",
+		},
+		{
+			lvl:              slog.LevelWarn,
+			expectedPrintout: "level=WARN msg=warn\n",
+		},
+		{
+			lvl:              slog.LevelError,
+			expectedPrintout: "",
+		},
+	}
+
+	for _, test := range tests {
+		r, writer := newTestSlogReporter(test.lvl)
+
+		r.Warnf("warn\n")
+		r.Infof("info\n")
+		r.Verbosef("verbose\n")
+
+		if writer.String() != test.expectedPrintout {
+			t.Errorf("expected \"%s\", got \"%s\"", test.expectedPrintout, writer.String())
+		}
+		if r.HasErrored() {
+			t.Error("HasErrored() should have returned false")
+		}
+	}
+}
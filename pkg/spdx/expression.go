@@ -0,0 +1,222 @@
+package spdx
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Operator is how the operands of a compound license expression are combined
+type Operator string
+
+const (
+	// And requires all of the operands to be complied with
+	And Operator = "AND"
+	// Or requires any one of the operands to be complied with
+	Or Operator = "OR"
+)
+
+// Expression is a parsed SPDX license expression, which is either a single
+// license (optionally with an exception) or a combination of expressions
+type Expression struct {
+	// Operator is empty for a single license
+	Operator Operator
+	Operands []Expression
+
+	// License is the identifier of a single license, including any "+" suffix
+	License string
+	// Exception is the identifier of the exception the license is used WITH, if any
+	Exception string
+}
+
+var idPattern = regexp.MustCompile(`^(DocumentRef-[A-Za-z0-9.\-]+:)?[A-Za-z0-9.\-]+\+?$`)
+
+// IsLicense reports if the expression is a single license
+func (e Expression) IsLicense() bool {
+	return e.Operator == ""
+}
+
+// Licenses returns the single licenses that are in the expression
+func (e Expression) Licenses() []Expression {
+	if e.IsLicense() {
+		return []Expression{e}
+	}
+
+	var licenses []Expression
+	for _, operand := range e.Operands {
+		licenses = append(licenses, operand.Licenses()...)
+	}
+
+	return licenses
+}
+
+// String returns the expression in its canonical form, using parentheses
+// only where they are needed
+func (e Expression) String() string {
+	if e.IsLicense() {
+		if e.Exception != "" {
+			return e.License + " WITH " + e.Exception
+		}
+
+		return e.License
+	}
+
+	operands := make([]string, len(e.Operands))
+	for i, operand := range e.Operands {
+		operands[i] = operand.String()
+
+		if !operand.IsLicense() && operand.Operator != e.Operator {
+			operands[i] = "(" + operands[i] + ")"
+		}
+	}
+
+	return strings.Join(operands, " "+string(e.Operator)+" ")
+}
+
+// Parse parses an SPDX license expression such as "MIT OR Apache-2.0" or
+// "(GPL-2.0-only WITH Classpath-exception-2.0 AND BSD-3-Clause)". Operators
+// are matched case-insensitively, and identifiers are not checked to be
+// known licenses.
+func Parse(expression string) (Expression, error) {
+	p := parser{tokens: tokenize(expression)}
+	if len(p.tokens) == 0 {
+		return Expression{}, errors.New("license expression is empty")
+	}
+
+	expr, err := p.parseOr()
+	if err != nil {
+		return Expression{}, fmt.Errorf("invalid license expression %q: %w", expression, err)
+	}
+
+	if tok, ok := p.peek(); ok {
+		return Expression{}, fmt.Errorf("invalid license expression %q: unexpected %q", expression, tok)
+	}
+
+	return expr, nil
+}
+
+func tokenize(expression string) []string {
+	expression = strings.NewReplacer("(", " ( ", ")", " ) ").Replace(expression)
+
+	return strings.Fields(expression)
+}
+
+type parser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *parser) peek() (string, bool) {
+	if p.pos >= len(p.tokens) {
+		return "", false
+	}
+
+	return p.tokens[p.pos], true
+}
+
+// accept consumes the next token if it is the given keyword
+func (p *parser) accept(keyword string) bool {
+	if tok, ok := p.peek(); ok && strings.EqualFold(tok, keyword) {
+		p.pos++
+
+		return true
+	}
+
+	return false
+}
+
+// parseOr parses operands joined by OR, which binds less tightly than AND
+func (p *parser) parseOr() (Expression, error) {
+	return p.parseCompound(Or, p.parseAnd)
+}
+
+// parseAnd parses operands joined by AND
+func (p *parser) parseAnd() (Expression, error) {
+	return p.parseCompound(And, p.parseWith)
+}
+
+func (p *parser) parseCompound(op Operator, operand func() (Expression, error)) (Expression, error) {
+	first, err := operand()
+	if err != nil {
+		return Expression{}, err
+	}
+
+	operands := []Expression{first}
+	for p.accept(string(op)) {
+		next, err := operand()
+		if err != nil {
+			return Expression{}, err
+		}
+
+		operands = append(operands, next)
+	}
+
+	if len(operands) == 1 {
+		return first, nil
+	}
+
+	// chains of the same operator are flattened, i.e. "a AND (b AND c)"
+	// has the same operands as "a AND b AND c"
+	flattened := make([]Expression, 0, len(operands))
+	for _, operand := range operands {
+		if operand.Operator == op {
+			flattened = append(flattened, operand.Operands...)
+		} else {
+			flattened = append(flattened, operand)
+		}
+	}
+
+	return Expression{Operator: op, Operands: flattened}, nil
+}
+
+// parseWith parses a single license with an optional exception, or an
+// expression in parentheses
+func (p *parser) parseWith() (Expression, error) {
+	if p.accept("(") {
+		expr, err := p.parseOr()
+		if err != nil {
+			return Expression{}, err
+		}
+
+		if !p.accept(")") {
+			return Expression{}, errors.New("missing closing parenthesis")
+		}
+
+		return expr, nil
+	}
+
+	license, err := p.parseID("license")
+	if err != nil {
+		return Expression{}, err
+	}
+
+	expr := Expression{License: license}
+	if p.accept("WITH") {
+		if expr.Exception, err = p.parseID("exception"); err != nil {
+			return Expression{}, err
+		}
+	}
+
+	return expr, nil
+}
+
+func (p *parser) parseID(kind string) (string, error) {
+	tok, ok := p.peek()
+	if !ok {
+		return "", fmt.Errorf("expected a %s but the expression ended", kind)
+	}
+
+	switch strings.ToUpper(tok) {
+	case "(", ")", string(And), string(Or), "WITH":
+		return "", fmt.Errorf("expected a %s but got %q", kind, tok)
+	}
+
+	if !idPattern.MatchString(tok) {
+		return "", fmt.Errorf("%q is not a valid %s identifier", tok, kind)
+	}
+
+	p.pos++
+
+	return tok, nil
+}
@@ -0,0 +1,124 @@
+package spdx
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		expression string
+		want       Expression
+		wantString string
+	}{
+		{
+			expression: "MIT",
+			want:       Expression{License: "MIT"},
+			wantString: "MIT",
+		},
+		{
+			expression: "GPL-2.0+",
+			want:       Expression{License: "GPL-2.0+"},
+			wantString: "GPL-2.0+",
+		},
+		{
+			expression: "MIT OR GPL-3.0-only",
+			want: Expression{Operator: Or, Operands: []Expression{
+				{License: "MIT"},
+				{License: "GPL-3.0-only"},
+			}},
+			wantString: "MIT OR GPL-3.0-only",
+		},
+		{
+			expression: "Apache-2.0 WITH LLVM-exception",
+			want:       Expression{License: "Apache-2.0", Exception: "LLVM-exception"},
+			wantString: "Apache-2.0 WITH LLVM-exception",
+		},
+		{
+			expression: "(MIT AND BSD-3-Clause)",
+			want: Expression{Operator: And, Operands: []Expression{
+				{License: "MIT"},
+				{License: "BSD-3-Clause"},
+			}},
+			wantString: "MIT AND BSD-3-Clause",
+		},
+		{
+			// AND binds more tightly than OR
+			expression: "MIT and BSD-3-Clause or Apache-2.0 WITH LLVM-exception",
+			want: Expression{Operator: Or, Operands: []Expression{
+				{Operator: And, Operands: []Expression{
+					{License: "MIT"},
+					{License: "BSD-3-Clause"},
+				}},
+				{License: "Apache-2.0", Exception: "LLVM-exception"},
+			}},
+			wantString: "(MIT AND BSD-3-Clause) OR Apache-2.0 WITH LLVM-exception",
+		},
+		{
+			expression: "MIT AND (ISC AND (GPL-2.0-only OR LGPL-2.1-only))",
+			want: Expression{Operator: And, Operands: []Expression{
+				{License: "MIT"},
+				{License: "ISC"},
+				{Operator: Or, Operands: []Expression{
+					{License: "GPL-2.0-only"},
+					{License: "LGPL-2.1-only"},
+				}},
+			}},
+			wantString: "MIT AND ISC AND (GPL-2.0-only OR LGPL-2.1-only)",
+		},
+		{
+			expression: "DocumentRef-spdx-tool-1.2:LicenseRef-MIT-Style-2",
+			want:       Expression{License: "DocumentRef-spdx-tool-1.2:LicenseRef-MIT-Style-2"},
+			wantString: "DocumentRef-spdx-tool-1.2:LicenseRef-MIT-Style-2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := Parse(tt.expression)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Parse() (-want +got):\n%s", diff)
+			}
+
+			if got.String() != tt.wantString {
+				t.Errorf("String() = %q, want %q", got.String(), tt.wantString)
+			}
+		})
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	t.Parallel()
+
+	tests := []string{
+		"",
+		"MIT OR",
+		"AND MIT",
+		"(MIT OR ISC",
+		"MIT OR ISC)",
+		"MIT ISC",
+		"Apache-2.0 WITH",
+		"MIT WITH (ISC)",
+		"unrecognized license",
+		"MIT/X11",
+	}
+
+	for _, expression := range tests {
+		t.Run(expression, func(t *testing.T) {
+			t.Parallel()
+
+			if got, err := Parse(expression); err == nil {
+				t.Errorf("Parse() = %v, want an error", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package spdx
+
+import "strings"
+
+// Policy decides which licenses are allowed to be used. Entries are single
+// licenses, optionally with an exception, where a license without an
+// exception also covers its use with any exception.
+type Policy struct {
+	// Allow is the licenses that can be used, which allows every license that
+	// is not denied if it is empty
+	Allow []string
+	// Deny is the licenses that cannot be used, even if they are allowed
+	Deny []string
+	// Exempt is the licenses that can always be used, even if they are denied
+	Exempt []string
+}
+
+// Violations evaluates the license expression against the policy, returning
+// the branches of it that violate the policy, or nothing if it complies.
+//
+// An AND expression complies if all of its operands do, and reports each of
+// those that do not, while an OR expression complies if any of its operands
+// do, and reports all of them if none do. Expressions that cannot be parsed
+// are compared as a whole against the policy.
+func (p Policy) Violations(license string) []string {
+	expr, err := Parse(license)
+	if err != nil {
+		expr = Expression{License: strings.TrimSpace(license)}
+	}
+
+	var violations []string
+	for _, violation := range p.violations(expr) {
+		violations = append(violations, violation.String())
+	}
+
+	return violations
+}
+
+func (p Policy) violations(expr Expression) []Expression {
+	switch expr.Operator {
+	case And:
+		var violations []Expression
+		for _, operand := range expr.Operands {
+			violations = append(violations, p.violations(operand)...)
+		}
+
+		return violations
+	case Or:
+		var violations []Expression
+		for _, operand := range expr.Operands {
+			found := p.violations(operand)
+			if len(found) == 0 {
+				return nil
+			}
+
+			violations = append(violations, found...)
+		}
+
+		return violations
+	}
+
+	if listed(p.Exempt, expr) {
+		return nil
+	}
+
+	if (len(p.Allow) > 0 && !listed(p.Allow, expr)) || listed(p.Deny, expr) {
+		return []Expression{expr}
+	}
+
+	return nil
+}
+
+// listed reports if the single license is covered by any of the entries
+func listed(entries []string, license Expression) bool {
+	for _, entry := range entries {
+		parsed, err := Parse(entry)
+		if err != nil || !parsed.IsLicense() {
+			if strings.EqualFold(strings.TrimSpace(entry), license.String()) {
+				return true
+			}
+
+			continue
+		}
+
+		if strings.EqualFold(parsed.License, license.License) &&
+			(parsed.Exception == "" || strings.EqualFold(parsed.Exception, license.Exception)) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,128 @@
+package spdx
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestPolicy_Violations(t *testing.T) {
+	t.Parallel()
+
+	allowed := Policy{Allow: []string{"MIT", "BSD-3-Clause", "Apache-2.0", "GPL-2.0-only WITH Classpath-exception-2.0"}}
+	denied := Policy{Deny: []string{"GPL-3.0-only", "AGPL-3.0-only", "Apache-2.0 WITH LLVM-exception"}}
+
+	tests := []struct {
+		name    string
+		policy  Policy
+		license string
+		want    []string
+	}{
+		{
+			name:    "exempt license that is denied",
+			policy:  Policy{Allow: allowed.Allow, Deny: denied.Deny, Exempt: []string{"AGPL-3.0-only"}},
+			license: "AGPL-3.0-only AND ISC",
+			want:    []string{"ISC"},
+		},
+		{
+			name:    "allowed license",
+			policy:  allowed,
+			license: "mit",
+			want:    nil,
+		},
+		{
+			name:    "license that is not allowed",
+			policy:  allowed,
+			license: "ISC",
+			want:    []string{"ISC"},
+		},
+		{
+			name:    "choice with an allowed branch",
+			policy:  allowed,
+			license: "MIT OR GPL-3.0-only",
+			want:    nil,
+		},
+		{
+			name:    "choice without an allowed branch",
+			policy:  allowed,
+			license: "GPL-3.0-only OR (ISC AND MIT)",
+			want:    []string{"GPL-3.0-only", "ISC"},
+		},
+		{
+			name:    "conjunction with a branch that is not allowed",
+			policy:  allowed,
+			license: "(MIT AND BSD-3-Clause AND GPL-3.0-only)",
+			want:    []string{"GPL-3.0-only"},
+		},
+		{
+			name:    "exception of an allowed license",
+			policy:  allowed,
+			license: "Apache-2.0 WITH LLVM-exception",
+			want:    nil,
+		},
+		{
+			name:    "allowed exception",
+			policy:  allowed,
+			license: "GPL-2.0-only WITH Classpath-exception-2.0",
+			want:    nil,
+		},
+		{
+			name:    "exception that is not allowed",
+			policy:  allowed,
+			license: "GPL-2.0-only WITH GCC-exception-2.0 OR ISC",
+			want:    []string{"GPL-2.0-only WITH GCC-exception-2.0", "ISC"},
+		},
+		{
+			name:    "license that is not denied",
+			policy:  denied,
+			license: "MIT AND Apache-2.0",
+			want:    nil,
+		},
+		{
+			name:    "denied license",
+			policy:  denied,
+			license: "MIT AND (GPL-3.0-only OR AGPL-3.0-only)",
+			want:    []string{"GPL-3.0-only", "AGPL-3.0-only"},
+		},
+		{
+			name:    "choice with a branch that is not denied",
+			policy:  denied,
+			license: "GPL-3.0-only OR MIT",
+			want:    nil,
+		},
+		{
+			name:    "denied exception",
+			policy:  denied,
+			license: "Apache-2.0 WITH LLVM-exception",
+			want:    []string{"Apache-2.0 WITH LLVM-exception"},
+		},
+		{
+			name:    "allowed license that is denied",
+			policy:  Policy{Allow: []string{"MIT", "GPL-3.0-only"}, Deny: []string{"GPL-3.0-only"}},
+			license: "MIT AND GPL-3.0-only",
+			want:    []string{"GPL-3.0-only"},
+		},
+		{
+			name:    "unparsable license",
+			policy:  Policy{Allow: []string{"MIT", "non standard"}},
+			license: "non standard",
+			want:    nil,
+		},
+		{
+			name:    "unparsable license that is not allowed",
+			policy:  allowed,
+			license: "MIT/X11",
+			want:    []string{"MIT/X11"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if diff := cmp.Diff(tt.want, tt.policy.Violations(tt.license)); diff != "" {
+				t.Errorf("Violations() (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
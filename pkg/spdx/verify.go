@@ -3,14 +3,25 @@ package spdx
 import "strings"
 
 // Unrecognized filters licenses for non-spdx identifiers. The "unknown" string is
-// also treated as a valid identifier.
+// also treated as a valid identifier, as are custom "LicenseRef-" identifiers,
+// and licenses can be used WITH an exception.
 func Unrecognized(licenses []string) (unrecognized []string) {
 	for _, license := range licenses {
-		l := strings.ToLower(license)
-		if !IDs[l] && l != "unknown" {
+		if !recognized(license) {
 			unrecognized = append(unrecognized, license)
 		}
 	}
 
 	return unrecognized
 }
+
+func recognized(license string) bool {
+	expr, err := Parse(license)
+	if err != nil || !expr.IsLicense() {
+		return false
+	}
+
+	l := strings.ToLower(expr.License)
+
+	return IDs[l] || l == "unknown" || strings.HasPrefix(l, "licenseref-")
+}
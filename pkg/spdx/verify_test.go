@@ -24,6 +24,14 @@ func Test_unrecognized(t *testing.T) {
 			name:     "some recognized, some unrecognized licenses",
 			licenses: []string{"agpl-1.0", "unrecognized license", "apache-1.0"},
 			want:     []string{"unrecognized license"},
+		}, {
+			name:     "licenses with exceptions and custom licenses",
+			licenses: []string{"Apache-2.0 WITH LLVM-exception", "LicenseRef-Proprietary", "agpl1.0 WITH LLVM-exception"},
+			want:     []string{"agpl1.0 WITH LLVM-exception"},
+		}, {
+			name:     "license expressions",
+			licenses: []string{"MIT OR Apache-2.0", "(MIT)"},
+			want:     []string{"MIT OR Apache-2.0"},
 		},
 	}
 	for _, tt := range tests {